@@ -1,21 +1,39 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	tcp_client_adapter "connectorapi-go/internal/adapter/client"
+	elkLog "connectorapi-go/internal/adapter/client/elk"
 	handler_adapter "connectorapi-go/internal/adapter/handler/api"
 	repo_adapter "connectorapi-go/internal/adapter/utils"
 	service_core "connectorapi-go/internal/core/service"
 	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/failures"
+	"connectorapi-go/pkg/health"
+	"connectorapi-go/pkg/idempotency"
+	"connectorapi-go/pkg/journal"
 	"connectorapi-go/pkg/logger"
+	"connectorapi-go/pkg/mask"
 	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/ratelimit"
+	"connectorapi-go/pkg/replay"
+	"connectorapi-go/pkg/reqid"
+	"connectorapi-go/pkg/respcache"
+	"connectorapi-go/pkg/tracing"
 )
 
+// version is the release of the binary, set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
 // @title           Connector API Gateway
 // @version         1.0
 // @description     This is the API Gateway for ConnectorAPI.
@@ -33,43 +51,115 @@ import (
 
 // @schemes http https
 func main() {
-	cfg, err := config.Load("./configs/config.yaml")
+	opts := parseOptions(os.Args[1:])
+	if opts.command == "mint-key" {
+		os.Exit(runMintKey(opts.fromStdin))
+	}
+	if opts.command == "parse-request-id" {
+		os.Exit(runParseRequestID(opts.args))
+	}
+	validateOnly := opts.command == "validate-config"
+
+	cfg, err := config.Load(opts.configPath, opts.env)
 	if err != nil {
 		log.Fatalf("FATAL: Failed to load configuration: %v", err)
 	}
+	if opts.command == "verify-audit" {
+		path := cfg.Audit.Path
+		if len(opts.args) > 0 {
+			path = opts.args[0]
+		}
+		os.Exit(runVerifyAudit(path))
+	}
 
-	apiKeys, err := config.LoadAPIKeys("./configs/apikeys.json")
+	apiKeyStore := repo_adapter.NewFileAPIKeyStore(config.APIKeysFile(opts.apiKeysPath, opts.env), cfg.Admin.AuditPath)
+	apiClients, err := apiKeyStore.Load()
 	if err != nil {
 		log.Fatalf("FATAL: Failed to load apiKeys: %v", err)
 	}
+	repo_adapter.AssignClientIDs(apiClients)
 
-	dr, err := config.LoadDestinationsAndRoutes("./configs/destinations_routes.json")
+	dr, err := config.LoadDestinationsAndRoutes(opts.routesPath, opts.env)
 	if err != nil {
 		log.Fatalf("FATAL: Failed to load destinations and routes: %v", err)
 	}
+	if unmatched := config.UnmatchedEnvOverrides(os.Environ(), cfg, dr); len(unmatched) > 0 {
+		log.Fatalf("FATAL: Environment variables match no configuration key: %s", strings.Join(unmatched, ", "))
+	}
 
-	appLogger := logger.New(cfg.Logger.Level)
+	logLevel := cfg.Logger.Level
+	if validateOnly {
+		logLevel = "error"
+	}
+	appLogger, appLogLevel := logger.NewWithLevel(logLevel)
 	defer appLogger.Sync()
 	appLogger.Info("Logger initialized")
+	appLogger.Infow("Effective configuration",
+		"env", opts.env,
+		"config", config.Effective(cfg),
+		"destinationsAndRoutes", config.Effective(dr),
+	)
 
-	gin.SetMode(cfg.Server.Mode)
+	if validateOnly {
+		gin.SetMode(gin.ReleaseMode)
+	} else {
+		gin.SetMode(cfg.Server.Mode)
+	}
 	appLogger.Infow("Gin mode set", "mode", cfg.Server.Mode)
 
 	appLogger.Info("Initializing dependencies...")
 	metrics.Init()
+	mask.SetDefault(mask.New(cfg.Masking))
+	failures.SetDefault(failures.NewRing(cfg.Admin.RecentFailures))
+	requestIDNode := cfg.Server.RequestIDNode
+	if requestIDNode == -1 {
+		requestIDNode = reqid.NodeFromHostname()
+	}
+	requestIDs, err := reqid.New(requestIDNode)
+	if err != nil {
+		appLogger.Fatalw("Failed to initialize request ID generator", "node", requestIDNode, "error", err)
+	}
+	reqid.SetDefault(requestIDs)
+	appLogger.Infow("Request ID generator initialized", "node", requestIDNode)
 
 	// --- Adapters ---
-	apiKeyRepo := repo_adapter.NewAPIKeyRepository(apiKeys)
+	if !validateOnly {
+		var elkWriter elkLog.LogWriter
+		if cfg.ELK.Sink == "file" {
+			elkWriter = elkLog.NewFileWriter(cfg.ELKPath, cfg.ELK, appLogger)
+		} else {
+			bulkWriter, err := elkLog.NewBulkWriter(cfg.ELK, appLogger)
+			if err != nil {
+				appLogger.Fatalw("Failed to start ELK sink", "sink", cfg.ELK.Sink, "error", err)
+			}
+			elkWriter = bulkWriter
+		}
+		elkLog.SetWriter(elkWriter)
+		appLogger.Infow("ELK log writer initialized", "sink", cfg.ELK.Sink)
+		defer elkWriter.Close()
+
+		shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+		if err != nil {
+			appLogger.Fatalw("Failed to start tracing", "endpoint", cfg.Tracing.Endpoint, "error", err)
+		}
+		appLogger.Infow("Tracing initialized", "enabled", cfg.Tracing.Enabled, "endpoint", cfg.Tracing.Endpoint)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			shutdownTracing(ctx)
+		}()
+	}
+	apiKeyRepo := repo_adapter.NewAPIKeyRepository(apiClients, apiKeyStore)
 
 	// --- TCP Socket Client Initialization ---
 	tcpClient := tcp_client_adapter.NewBasicTCPSocketClient(
-		5*time.Second,  // Dial Timeout (e.g., 5 seconds to establish connection)
-		10*time.Second, // Read/Write Timeout (e.g., 10 seconds for data transfer)
+		cfg.TCP.DialTimeout,      // Dial Timeout (e.g., 5 seconds to establish connection)
+		cfg.TCP.ReadWriteTimeout, // Read/Write Timeout (e.g., 10 seconds for data transfer)
+		appLogger,
 	)
-	appLogger.Info("TCP Socket Client initialized")
+	tcpClient.LogPayloads = cfg.TCP.LogPayloads
+	appLogger.Infow("TCP Socket Client initialized", "logPayloads", cfg.TCP.LogPayloads)
 	
-	appLogger.Infow("Loaded routes", "routes", dr.Routes)
-	appLogger.Infow("Loaded destinations", "destinations", dr.Destinations)
 
 	// --- Core Services ---
 	collectionService := service_core.NewCollectionService(cfg, appLogger, tcpClient, dr.Routes, dr.Destinations)
@@ -99,13 +189,140 @@ func main() {
 	mobileHandler := handler_adapter.NewMobileHandler(mobileService, appLogger, apiKeyRepo, cfg)
 	applicationCapHandler := handler_adapter.NewApplicationCapHandler(applicationCapService, appLogger, apiKeyRepo, cfg)
 	applicationLowerHandler := handler_adapter.NewApplicationLowerHandler(applicationLowerService, appLogger, apiKeyRepo, cfg)
+	adminHandler := handler_adapter.NewAdminHandler(apiKeyRepo, cfg.Admin.Keys, appLogLevel, appLogger)
 
 	appLogger.Info("Setting up router...")
-	router := handler_adapter.SetupRouter(appLogger, apiKeyRepo, collectionHandler, agreementHandler, creditcardHandler, commonHandler, selfServiceHandler, registerHandler, customerLowerHandler, consentHandler, uhpHandler, mobileHandler, applicationCapHandler, applicationLowerHandler)
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.New(cfg.RateLimit)
+	}
+	var auditJournal *journal.Journal
+	if cfg.Audit.Enabled && !validateOnly {
+		auditJournal, err = journal.Open(cfg.Audit.Path)
+		if err != nil {
+			appLogger.Fatalw("Failed to open audit journal, check it with verify-audit", "path", cfg.Audit.Path, "error", err)
+		}
+		defer auditJournal.Close()
+	}
+	var idempotencyManager *idempotency.Manager
+	if cfg.Idempotency.Enabled && !validateOnly {
+		var store idempotency.Store = idempotency.NewMemoryStore()
+		if cfg.Idempotency.Store == "file" {
+			fileStore, err := idempotency.NewFileStore(cfg.Idempotency.Path, cfg.Idempotency.Key)
+			if err != nil {
+				appLogger.Fatalw("Failed to open idempotency store", "path", cfg.Idempotency.Path, "error", err)
+			}
+			store = fileStore
+		}
+		idempotencyManager = idempotency.NewManager(store, cfg.Idempotency.TTL)
+	}
+	var replayGuard *replay.Guard
+	if cfg.Replay.Enabled {
+		replayGuard = replay.New(cfg.Replay)
+	}
+	var responseCache *respcache.Cache
+	if cfg.Cache.Enabled {
+		responseCache = respcache.New(cfg.Cache.MaxEntries)
+	}
+	var coalesceRoutes []string
+	if cfg.Coalesce.Enabled {
+		coalesceRoutes = dr.ReadOnlyRoutes()
+	}
+	probe := tcp_client_adapter.ConnectProbe()
+	if cfg.Health.Echo.Enabled {
+		probe = tcp_client_adapter.EchoProbe(tcp_client_adapter.NewBasicTCPSocketClient(cfg.Health.ProbeTimeout, cfg.Health.ProbeTimeout, appLogger), cfg.Health.Echo)
+	}
+	readiness := health.NewChecker(cfg.Health, dr, probe)
+	readiness.AddComponent("config", apiKeyRepo.ConfigHealth)
+	readiness.AddComponent("logSink", elkLog.WriterHealth)
+	router := handler_adapter.SetupRouter(handler_adapter.RouterDeps{
+		Logger:            appLogger,
+		APIKeys:           apiKeyRepo,
+		Limiter:           limiter,
+		Idempotency:       idempotencyManager,
+		IdempotencyConfig: cfg.Idempotency,
+		Replay:            replayGuard,
+		Audit:             auditJournal,
+		AuditRoutes:       cfg.Audit.Routes,
+		Readiness:         readiness,
+		Cache:             responseCache,
+		CacheConfig:       cfg.Cache,
+		CoalesceRoutes:    coalesceRoutes,
+		ELKPath:           cfg.ELKPath,
+		Handlers: []handler_adapter.RouteRegistrar{
+			collectionHandler, agreementHandler, creditcardHandler, commonHandler, selfServiceHandler, registerHandler,
+			customerLowerHandler, consentHandler, uhpHandler, mobileHandler, applicationCapHandler, applicationLowerHandler,
+		},
+	})
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		appLogger.Fatalw("Invalid trusted proxies", "error", err)
+	}
+
+	report := config.Validate(cfg, dr, apiClients, apiHandlerRoutes(router), featureChecks...)
+	if validateOnly {
+		for _, issue := range report.Issues {
+			fmt.Println(issue.String())
+		}
+		if report.HasErrors() {
+			fmt.Printf("configuration invalid: %d error(s)\n", len(report.Errors()))
+			os.Exit(1)
+		}
+		fmt.Println("configuration OK")
+		return
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity == config.SeverityError {
+			appLogger.Errorw("Configuration error", "check", issue.Check, "message", issue.Message)
+		} else {
+			appLogger.Warnw("Configuration warning", "check", issue.Check, "message", issue.Message)
+		}
+	}
+	if report.HasErrors() {
+		appLogger.Fatalw("Refusing to start with invalid configuration", "errors", len(report.Errors()))
+	}
+
+	readiness.Start()
+	defer readiness.Stop()
+	var servers []*http.Server
+	if cfg.Admin.Port != "" {
+		introspectionHandler := handler_adapter.NewIntrospectionHandler(dr, readiness, failures.Default(), handler_adapter.NewBuildInfo(version))
+		adminRouter := handler_adapter.SetupAdminRouter(appLogger, adminHandler, introspectionHandler)
+		adminAddress := fmt.Sprintf(":%s", cfg.Admin.Port)
+		appLogger.Infow("Starting admin server", "address", adminAddress)
+		adminServer := newHTTPServer(adminAddress, adminRouter, cfg.Server)
+		adminServer.WriteTimeout = 0 // pprof profiles run longer than any request
+		listen(adminServer, "admin server", appLogger)
+		servers = append(servers, adminServer)
+	}
+	go apiKeyRepo.WatchExpiry(appLogger, cfg.APIKeyPolicy.ExpiryWarning, cfg.APIKeyPolicy.CheckInterval, nil)
 
 	serverAddress := fmt.Sprintf(":%s", cfg.Server.Port)
-	appLogger.Infow("Starting server", "address", serverAddress)
-	if err := router.Run(serverAddress); err != nil {
-		appLogger.Fatalw("Failed to start server", "error", err)
+	appLogger.Infow("Starting server", "address", serverAddress, "readTimeout", cfg.Server.ReadTimeout.String(), "writeTimeout", cfg.Server.WriteTimeout.String(), "idleTimeout", cfg.Server.IdleTimeout.String())
+	server := newHTTPServer(serverAddress, router, cfg.Server)
+	listen(server, "server", appLogger)
+	waitForShutdown(cfg.Server, readiness, appLogger, append(servers, server)...)
+}
+
+// featureChecks validate the settings each feature package owns.
+var featureChecks = []config.Check{
+	ratelimit.ValidateConfig,
+	mask.ValidateConfig,
+	journal.ValidateConfig,
+	idempotency.ValidateConfig,
+	replay.ValidateConfig,
+	elkLog.ValidateConfig,
+	tracing.ValidateConfig,
+	health.ValidateConfig,
+	respcache.ValidateConfig,
+}
+
+// apiHandlerRoutes lists the served /Api endpoints as METHOD:/path route keys.
+func apiHandlerRoutes(router *gin.Engine) []string {
+	var routes []string
+	for _, r := range router.Routes() {
+		if strings.HasPrefix(r.Path, "/Api/") {
+			routes = append(routes, r.Method+":"+r.Path)
+		}
 	}
+	return routes
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/config"
+)
+
+// runMintKey generates a new API key, or hashes an existing one read from stdin,
+// and prints the entry to add to a client's "keys" in apikeys.json.
+func runMintKey(fromStdin bool) int {
+	var key string
+	if fromStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "failed to read key from stdin: %v\n", err)
+			return 1
+		}
+		key = strings.TrimSpace(line)
+	} else {
+		generated, err := apikey.Generate()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate key: %v\n", err)
+			return 1
+		}
+		key = generated
+	}
+
+	hash, err := apikey.Hash(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to hash key: %v\n", err)
+		return 1
+	}
+	entry, err := json.Marshal(config.KeyCredential{Hash: hash})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode entry: %v\n", err)
+		return 1
+	}
+
+	if !fromStdin {
+		fmt.Printf("API key (hand over once, it is not stored): %s\n", key)
+	}
+	fmt.Printf("Key ID: %s\n", apikey.MaskKey(key))
+	fmt.Printf("apikeys.json entry: %s\n", entry)
+	return 0
+}
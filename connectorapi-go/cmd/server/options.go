@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+// options are the command-line settings shared by every subcommand.
+type options struct {
+	command     string
+	configPath  string
+	apiKeysPath string
+	routesPath  string
+	env         string
+	fromStdin   bool
+	args        []string
+}
+
+// parseOptions reads an optional leading subcommand followed by flags, e.g.
+//
+//	server validate-config -env uat -config ./configs/config.yaml
+//	server verify-audit -config ./configs/config.yaml [journal path]
+//	server parse-request-id RQ1M59QFK7N007DTZ6RF
+func parseOptions(arguments []string) options {
+	opts := options{command: "serve"}
+	if len(arguments) > 0 && !strings.HasPrefix(arguments[0], "-") {
+		opts.command = arguments[0]
+		arguments = arguments[1:]
+	}
+
+	fs := flag.NewFlagSet(opts.command, flag.ExitOnError)
+	fs.StringVar(&opts.configPath, "config", "./configs/config.yaml", "path of the base config file")
+	fs.StringVar(&opts.apiKeysPath, "apikeys", "./configs/apikeys.json", "path of the API key file")
+	fs.StringVar(&opts.routesPath, "routes", "./configs/destinations_routes.json", "path of the destinations and routes file")
+	fs.StringVar(&opts.env, "env", os.Getenv("CONNECTOR_ENV"), "environment overlay to merge on top of the base files, e.g. sit, uat, prod")
+	fs.BoolVar(&opts.fromStdin, "stdin", false, "mint-key: hash an existing key read from stdin instead of generating one")
+	fs.Parse(arguments)
+	opts.args = fs.Args()
+
+	return opts
+}
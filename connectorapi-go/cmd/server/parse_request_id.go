@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"connectorapi-go/pkg/reqid"
+)
+
+// runParseRequestID prints the creation time, node and sequence of generated request IDs.
+func runParseRequestID(ids []string) int {
+	if len(ids) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: parse-request-id <request ID>...")
+		return 1
+	}
+	status := 0
+	for _, id := range ids {
+		parsed, err := reqid.Parse(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", id, err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s time=%s node=%d seq=%d\n", id, parsed.Time.Format(time.RFC3339Nano), parsed.Node, parsed.Seq)
+	}
+	return status
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	tcp_client_adapter "connectorapi-go/internal/adapter/client"
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/health"
+)
+
+// newHTTPServer returns the server of handler on address, with the timeouts of cfg.
+func newHTTPServer(address string, handler http.Handler, cfg config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:         address,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+}
+
+// listen serves srv in the background. A server that cannot listen stops the process.
+func listen(srv *http.Server, name string, logger *zap.SugaredLogger) {
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalw("Failed to start "+name, "address", srv.Addr, "error", err)
+		}
+	}()
+}
+
+// waitForShutdown blocks until SIGINT or SIGTERM, then stops the servers without
+// cutting System I exchanges short: /readyz turns unready so that the load balancer
+// stops sending requests, after DrainDelay the servers refuse new connections, and
+// the requests and System I calls in flight get ShutdownTimeout to finish. A second
+// signal exits at once. The deferred Close calls of main then flush the ELK log and
+// close the audit journal.
+func waitForShutdown(cfg config.ServerConfig, readiness *health.Checker, logger *zap.SugaredLogger, servers ...*http.Server) {
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-signals.Done()
+	stop()
+
+	logger.Infow("Shutting down", "drainDelay", cfg.DrainDelay.String(), "shutdownTimeout", cfg.ShutdownTimeout.String())
+	readiness.SetNotReady("shutting down")
+	time.Sleep(cfg.DrainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				logger.Warnw("Requests still running at shutdown", "address", srv.Addr, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// a request abandoned by Shutdown may still be waiting on System I
+	if err := tcp_client_adapter.WaitIdle(ctx); err != nil {
+		logger.Warnw("System I calls still running at shutdown", "inFlight", tcp_client_adapter.InFlight())
+	}
+	logger.Info("Server stopped")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"connectorapi-go/pkg/journal"
+)
+
+// runVerifyAudit checks the hash chain of an audit journal and prints every break,
+// such as an edited, removed or reordered entry.
+func runVerifyAudit(path string) int {
+	entries, problems, err := journal.Verify(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read audit journal %s: %v\n", path, err)
+		return 1
+	}
+	for _, problem := range problems {
+		fmt.Println(problem.String())
+	}
+	if len(problems) > 0 {
+		fmt.Printf("audit journal %s is NOT intact: %d problem(s) in %d entries\n", path, len(problems), entries)
+		return 1
+	}
+	fmt.Printf("audit journal %s is intact: %d entries\n", path, entries)
+	return 0
+}
@@ -0,0 +1,499 @@
+package elk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// maxDownBackoff caps the wait between attempts to reach a sink that is down.
+const maxDownBackoff = time.Minute
+
+// bulkDoc is one ELK document and the index it belongs to. It is also the line
+// format of the spool files.
+type bulkDoc struct {
+	Index string          `json:"index"`
+	Doc   json.RawMessage `json:"doc"`
+}
+
+// bulkTarget delivers documents. send returns the documents worth sending again
+// and the number the target refused for good.
+type bulkTarget interface {
+	send(ctx context.Context, docs []bulkDoc) (retry []bulkDoc, rejected int, err error)
+	close()
+}
+
+// BulkWriter sends the ELK log as JSON documents to the Elasticsearch _bulk API or a
+// Logstash http or tcp input, in batches from one background goroutine. What the sink
+// does not take at the first attempt is spooled to SpoolPath and sent again from there
+// after RetryBackoff, doubled while the sink stays down, so the goroutine emptying the
+// queue never waits between attempts. Documents go to <Index>-yyyy.mm.dd of the request date.
+type BulkWriter struct {
+	config config.ELKConfig
+	logger *zap.SugaredLogger
+	target bulkTarget
+	queue  *entryQueue
+	done   chan struct{}
+	health writerHealth
+
+	// Owned by the writer goroutine.
+	down        bool
+	downFor     time.Duration
+	nextAttempt time.Time
+	spoolBytes  int64
+	now         func() time.Time
+}
+
+// NewBulkWriter starts a writer for cfg.Sink "elasticsearch" or "logstash".
+func NewBulkWriter(cfg config.ELKConfig, logger *zap.SugaredLogger) (*BulkWriter, error) {
+	target, err := newBulkTarget(cfg)
+	if err != nil {
+		return nil, err
+	}
+	w := &BulkWriter{
+		config: cfg,
+		logger: logger,
+		target: target,
+		queue:  newEntryQueue(cfg),
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+	if cfg.SpoolPath != "" {
+		if err := os.MkdirAll(cfg.SpoolPath, 0700); err != nil {
+			return nil, err
+		}
+		for _, file := range w.spoolFiles() {
+			if info, err := os.Stat(file); err == nil {
+				w.spoolBytes += info.Size()
+			}
+		}
+		metrics.ELKSinkSpoolBytes.Set(float64(w.spoolBytes))
+	}
+	go w.run()
+	return w, nil
+}
+
+func newBulkTarget(cfg config.ELKConfig) (bulkTarget, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid ELK sink url %q", cfg.URL)
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	switch {
+	case cfg.Sink == "elasticsearch":
+		return &elasticsearchTarget{client: client, url: strings.TrimSuffix(cfg.URL, "/") + "/_bulk", username: cfg.Username, password: cfg.Password}, nil
+	case cfg.Sink == "logstash" && u.Scheme == "tcp":
+		return &logstashTCPTarget{address: u.Host, timeout: cfg.Timeout}, nil
+	case cfg.Sink == "logstash":
+		return &logstashHTTPTarget{client: client, url: cfg.URL, username: cfg.Username, password: cfg.Password}, nil
+	default:
+		return nil, fmt.Errorf("unknown ELK sink %q", cfg.Sink)
+	}
+}
+
+// Write queues the lines, see entryQueue.put.
+func (w *BulkWriter) Write(timestamp time.Time, lines []string) error {
+	return w.queue.put(timestamp, lines)
+}
+
+// Close sends or spools every queued entry.
+func (w *BulkWriter) Close() error {
+	w.queue.close()
+	<-w.done
+	return nil
+}
+
+func (w *BulkWriter) run() {
+	defer close(w.done)
+	defer w.target.close()
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []bulkDoc
+	entries := 0
+	for {
+		select {
+		case e, ok := <-w.queue.ch:
+			if !ok {
+				w.flush(batch)
+				metrics.ELKLogQueueDepth.Set(0)
+				return
+			}
+			batch = append(batch, w.documents(e)...)
+			entries++
+			if entries >= w.config.BatchSize {
+				w.flush(batch)
+				batch, entries = nil, 0
+			}
+		case <-ticker.C:
+			metrics.ELKLogQueueDepth.Set(float64(len(w.queue.ch)))
+			w.flush(batch)
+			batch, entries = nil, 0
+			w.drainSpool()
+		}
+	}
+}
+
+// documents turns the "<time> INFO :{...}" lines of an entry into documents, the
+// first one being the main log and the others its line logs.
+func (w *BulkWriter) documents(e logEntry) []bulkDoc {
+	index := w.config.Index + "-" + e.timestamp.Format("2006.01.02")
+	timestamp, _ := json.Marshal(e.timestamp.Format(time.RFC3339Nano))
+	docs := make([]bulkDoc, 0, len(e.lines))
+	for i, line := range e.lines {
+		start := strings.IndexByte(line, '{')
+		if start < 0 || len(line)-start <= 2 {
+			continue
+		}
+		logType := "line"
+		if i == 0 {
+			logType = "main"
+		}
+		doc := make([]byte, 0, len(line)-start+64)
+		doc = append(doc, `{"@timestamp":`...)
+		doc = append(doc, timestamp...)
+		doc = append(doc, `,"LogType":"`+logType+`",`...)
+		doc = append(doc, line[start+1:]...)
+		docs = append(docs, bulkDoc{Index: index, Doc: doc})
+	}
+	return docs
+}
+
+func (w *BulkWriter) flush(docs []bulkDoc) {
+	if len(docs) == 0 {
+		return
+	}
+	if w.down && w.now().Before(w.nextAttempt) {
+		w.spool(docs)
+		return
+	}
+	if remaining := w.deliver(docs); len(remaining) > 0 {
+		w.markDown()
+		w.spool(remaining)
+		return
+	}
+	w.markUp()
+}
+
+// deliver sends docs once and returns what the sink did not take but may take later.
+func (w *BulkWriter) deliver(docs []bulkDoc) []bulkDoc {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Timeout)
+	retry, rejected, err := w.target.send(ctx, docs)
+	cancel()
+
+	metrics.ELKSinkDocumentsTotal.With(prometheus.Labels{"result": "sent"}).Add(float64(len(docs) - len(retry) - rejected))
+	if rejected > 0 {
+		metrics.ELKSinkDocumentsTotal.With(prometheus.Labels{"result": "rejected"}).Add(float64(rejected))
+		w.logger.Errorw("ELK sink rejected documents", "sink", w.config.Sink, "rejected", rejected)
+	}
+	if err != nil {
+		metrics.ELKLogErrorsTotal.With(prometheus.Labels{"op": "bulk"}).Inc()
+		w.logger.Warnw("ELK sink request failed", "sink", w.config.Sink, "documents", len(docs), "error", err)
+	}
+	return retry
+}
+
+func (w *BulkWriter) markDown() {
+	w.health.set(fmt.Errorf("%s sink unreachable, batches are spooled", w.config.Sink))
+	if !w.down {
+		w.down, w.downFor = true, w.config.RetryBackoff
+	} else if w.downFor *= 2; w.downFor > maxDownBackoff {
+		w.downFor = maxDownBackoff
+	}
+	w.nextAttempt = w.now().Add(w.downFor)
+}
+
+func (w *BulkWriter) markUp() {
+	w.health.set(nil)
+	w.down, w.downFor = false, 0
+}
+
+// Health returns an error while the sink is unreachable.
+func (w *BulkWriter) Health() error {
+	return w.health.get()
+}
+
+// spool writes docs to a new spool file, or drops them when there is no spool or it is full.
+func (w *BulkWriter) spool(docs []bulkDoc) {
+	drop := func(reason string, err error) {
+		metrics.ELKSinkDocumentsTotal.With(prometheus.Labels{"result": "dropped"}).Add(float64(len(docs)))
+		if err != nil {
+			metrics.ELKLogErrorsTotal.With(prometheus.Labels{"op": "spool"}).Inc()
+		}
+		w.logger.Errorw("ELK documents dropped", "sink", w.config.Sink, "documents", len(docs), "reason", reason, "error", err)
+	}
+	if w.config.SpoolPath == "" {
+		drop("no spoolPath", nil)
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			drop("encode", err)
+			return
+		}
+	}
+	if max := int64(w.config.SpoolMaxMB) << 20; w.spoolBytes+int64(buf.Len()) > max {
+		drop("spool full", nil)
+		return
+	}
+	name := filepath.Join(w.config.SpoolPath, fmt.Sprintf("spool-%020d.ndjson", w.now().UnixNano()))
+	if err := writeFileSync(name, buf.Bytes()); err != nil {
+		drop("spool write", err)
+		return
+	}
+	w.spoolBytes += int64(buf.Len())
+	metrics.ELKSinkSpoolBytes.Set(float64(w.spoolBytes))
+	metrics.ELKSinkDocumentsTotal.With(prometheus.Labels{"result": "spooled"}).Add(float64(len(docs)))
+}
+
+// drainSpool sends the spool files oldest first and stops at the first that fails.
+func (w *BulkWriter) drainSpool() {
+	if w.config.SpoolPath == "" || w.spoolBytes == 0 || (w.down && w.now().Before(w.nextAttempt)) {
+		return
+	}
+	for _, file := range w.spoolFiles() {
+		docs, size, err := readSpool(file)
+		if err != nil {
+			metrics.ELKLogErrorsTotal.With(prometheus.Labels{"op": "spool"}).Inc()
+			w.logger.Errorw("Unreadable ELK spool file set aside", "file", file, "error", err)
+			os.Rename(file, file+".bad")
+			w.spoolBytes -= size
+			continue
+		}
+
+		remaining := w.deliver(docs)
+		if len(remaining) > 0 {
+			w.markDown()
+			if len(remaining) < len(docs) {
+				w.spoolBytes -= size
+				os.Remove(file)
+				w.spool(remaining)
+			}
+			metrics.ELKSinkSpoolBytes.Set(float64(w.spoolBytes))
+			return
+		}
+		os.Remove(file)
+		w.spoolBytes -= size
+		metrics.ELKSinkSpoolBytes.Set(float64(w.spoolBytes))
+		w.logger.Infow("ELK spool file sent", "file", file, "documents", len(docs))
+	}
+	w.markUp()
+}
+
+func (w *BulkWriter) spoolFiles() []string {
+	files, _ := filepath.Glob(filepath.Join(w.config.SpoolPath, "spool-*.ndjson"))
+	sort.Strings(files)
+	return files
+}
+
+func readSpool(file string) ([]bulkDoc, int64, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	var docs []bulkDoc
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var doc bulkDoc
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			return nil, int64(len(data)), err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, int64(len(data)), scanner.Err()
+}
+
+func writeFileSync(name string, data []byte) error {
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if syncErr := f.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// ndjson writes one document per line, each preceded by meta when it is not nil.
+func ndjson(docs []bulkDoc, meta func(bulkDoc) []byte) []byte {
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		if meta != nil {
+			buf.Write(meta(doc))
+			buf.WriteByte('\n')
+		}
+		buf.Write(doc.Doc)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// retryableStatus tells whether a request refused with status may succeed later,
+// including credentials that are fixed while documents wait in the spool.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusUnauthorized || status == http.StatusForbidden || status >= 500
+}
+
+type elasticsearchTarget struct {
+	client             *http.Client
+	url                string
+	username, password string
+}
+
+type bulkResponse struct {
+	Errors bool                        `json:"errors"`
+	Items  []map[string]bulkItemResult `json:"items"`
+}
+
+type bulkItemResult struct {
+	Status int `json:"status"`
+}
+
+func (t *elasticsearchTarget) send(ctx context.Context, docs []bulkDoc) ([]bulkDoc, int, error) {
+	body := ndjson(docs, func(doc bulkDoc) []byte {
+		meta, _ := json.Marshal(map[string]map[string]string{"index": {"_index": doc.Index}})
+		return meta
+	})
+	status, respBody, err := post(ctx, t.client, t.url, "application/x-ndjson", body, t.username, t.password)
+	if err != nil {
+		return docs, 0, err
+	}
+	if status < 200 || status > 299 {
+		err := fmt.Errorf("bulk request returned HTTP %d", status)
+		if retryableStatus(status) {
+			return docs, 0, err
+		}
+		return nil, len(docs), err
+	}
+
+	var result bulkResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return docs, 0, fmt.Errorf("unreadable bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, 0, nil
+	}
+	var retry []bulkDoc
+	rejected := 0
+	for i, item := range result.Items {
+		if i >= len(docs) {
+			break
+		}
+		for _, r := range item {
+			switch {
+			case r.Status >= 200 && r.Status <= 299:
+			case retryableStatus(r.Status):
+				retry = append(retry, docs[i])
+			default:
+				rejected++
+			}
+		}
+	}
+	return retry, rejected, nil
+}
+
+func (t *elasticsearchTarget) close() {}
+
+type logstashHTTPTarget struct {
+	client             *http.Client
+	url                string
+	username, password string
+}
+
+func (t *logstashHTTPTarget) send(ctx context.Context, docs []bulkDoc) ([]bulkDoc, int, error) {
+	status, _, err := post(ctx, t.client, t.url, "application/x-ndjson", ndjson(docs, nil), t.username, t.password)
+	if err != nil {
+		return docs, 0, err
+	}
+	if status < 200 || status > 299 {
+		err := fmt.Errorf("logstash returned HTTP %d", status)
+		if retryableStatus(status) {
+			return docs, 0, err
+		}
+		return nil, len(docs), err
+	}
+	return nil, 0, nil
+}
+
+func (t *logstashHTTPTarget) close() {}
+
+// logstashTCPTarget writes newline-delimited JSON to a Logstash tcp input with the
+// json_lines codec, keeping the connection between batches.
+type logstashTCPTarget struct {
+	address string
+	timeout time.Duration
+	conn    net.Conn
+}
+
+func (t *logstashTCPTarget) send(ctx context.Context, docs []bulkDoc) ([]bulkDoc, int, error) {
+	if t.conn == nil {
+		conn, err := (&net.Dialer{Timeout: t.timeout}).DialContext(ctx, "tcp", t.address)
+		if err != nil {
+			return docs, 0, err
+		}
+		t.conn = conn
+	}
+	t.conn.SetWriteDeadline(time.Now().Add(t.timeout))
+	if _, err := t.conn.Write(ndjson(docs, nil)); err != nil {
+		t.close()
+		return docs, 0, err
+	}
+	return nil, 0, nil
+}
+
+func (t *logstashTCPTarget) close() {
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+}
+
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte, username, password string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	return resp.StatusCode, respBody, err
+}
@@ -0,0 +1,293 @@
+package elk
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// fakeBulk stands in for the Elasticsearch _bulk API. status, when set, answers
+// the whole request; itemStatus answers single documents by their Id.
+type fakeBulk struct {
+	mu         sync.Mutex
+	status     int
+	itemStatus map[string][]int
+	indexes    []string
+	docs       []map[string]any
+}
+
+func (f *fakeBulk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	var items []map[string]map[string]int
+	hasErrors := false
+	for i := 0; i+1 < len(lines); i += 2 {
+		var meta map[string]map[string]string
+		var doc map[string]any
+		json.Unmarshal([]byte(lines[i]), &meta)
+		if err := json.Unmarshal([]byte(lines[i+1]), &doc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		status := http.StatusCreated
+		id, _ := doc["Id"].(string)
+		if s := f.itemStatus[id]; len(s) > 0 {
+			status, f.itemStatus[id] = s[0], s[1:]
+		}
+		if status == http.StatusCreated {
+			f.indexes = append(f.indexes, meta["index"]["_index"])
+			f.docs = append(f.docs, doc)
+		} else {
+			hasErrors = true
+		}
+		items = append(items, map[string]map[string]int{"index": {"status": status}})
+	}
+	json.NewEncoder(w).Encode(map[string]any{"errors": hasErrors, "items": items})
+}
+
+func (f *fakeBulk) received() ([]string, []map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.indexes...), append([]map[string]any(nil), f.docs...)
+}
+
+func bulkConfig(t *testing.T, sink, url string) config.ELKConfig {
+	t.Helper()
+	cfg := writerConfig()
+	cfg.Sink, cfg.URL, cfg.Index = sink, url, "connectorapi"
+	cfg.Timeout, cfg.RetryBackoff = time.Second, time.Millisecond
+	cfg.SpoolPath, cfg.SpoolMaxMB = filepath.Join(t.TempDir(), "spool"), 1
+	return cfg
+}
+
+func newTestBulkWriter(t *testing.T, cfg config.ELKConfig) *BulkWriter {
+	t.Helper()
+	w, err := NewBulkWriter(cfg, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func logLine(id string) string {
+	return `2026-10-19 09:00:00.000 INFO :{"Id":"` + id + `","Status":"200"}`
+}
+
+// waitForDocs waits until es has indexed n documents.
+func waitForDocs(t *testing.T, es *fakeBulk, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, docs := es.received(); len(docs) == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d documents not indexed", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBulkWriterSendsDailyIndexes(t *testing.T) {
+	es := &fakeBulk{}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	w := newTestBulkWriter(t, bulkConfig(t, "elasticsearch", srv.URL))
+	day := time.Date(2026, 10, 19, 23, 59, 0, 0, time.UTC)
+	w.Write(day, []string{logLine("main-1"), logLine("line-1"), ""})
+	w.Write(day.Add(2*time.Minute), []string{logLine("main-2")})
+	w.Close()
+
+	indexes, docs := es.received()
+	if len(docs) != 3 {
+		t.Fatalf("%d documents indexed, want 3", len(docs))
+	}
+	want := []string{"connectorapi-2026.10.19", "connectorapi-2026.10.19", "connectorapi-2026.10.20"}
+	for i, doc := range docs {
+		if indexes[i] != want[i] {
+			t.Errorf("document %d in %s, want %s", i, indexes[i], want[i])
+		}
+		if doc["@timestamp"] == nil || doc["Status"] != "200" {
+			t.Errorf("document %d = %v", i, doc)
+		}
+	}
+	if docs[0]["LogType"] != "main" || docs[1]["LogType"] != "line" {
+		t.Errorf("log types %v, %v", docs[0]["LogType"], docs[1]["LogType"])
+	}
+}
+
+func TestBulkWriterRetriesRefusedItems(t *testing.T) {
+	es := &fakeBulk{itemStatus: map[string][]int{
+		"busy":    {http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		"invalid": {http.StatusBadRequest},
+	}}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	w := newTestBulkWriter(t, bulkConfig(t, "elasticsearch", srv.URL))
+	w.Write(time.Now(), []string{logLine("ok"), logLine("busy"), logLine("invalid")})
+	// busy goes through the spool, twice
+	waitForDocs(t, es, 2)
+	w.Close()
+
+	_, docs := es.received()
+	if len(docs) != 2 || docs[0]["Id"] != "ok" || docs[1]["Id"] != "busy" {
+		t.Errorf("indexed %v, want ok and busy once each", docs)
+	}
+	if files := w.spoolFiles(); len(files) != 0 {
+		t.Errorf("spooled %v", files)
+	}
+}
+
+func TestBulkWriterSpoolsWhileSinkIsDown(t *testing.T) {
+	es := &fakeBulk{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	cfg := bulkConfig(t, "elasticsearch", srv.URL)
+	w := newTestBulkWriter(t, cfg)
+	w.Write(time.Now(), []string{logLine("first")})
+	w.Write(time.Now(), []string{logLine("second")})
+	w.Close()
+
+	if _, docs := es.received(); len(docs) != 0 {
+		t.Fatalf("indexed %v while down", docs)
+	}
+	if files := w.spoolFiles(); len(files) == 0 {
+		t.Fatal("nothing spooled")
+	}
+	if w.Health() == nil {
+		t.Error("healthy while the sink is down")
+	}
+
+	// A new writer finds the spool and sends it once the sink is back.
+	es.mu.Lock()
+	es.status = 0
+	es.mu.Unlock()
+	w = newTestBulkWriter(t, cfg)
+	waitForDocs(t, es, 2)
+	w.Close()
+	if files := w.spoolFiles(); len(files) != 0 {
+		t.Errorf("spool files left: %v", files)
+	}
+	if err := w.Health(); err != nil {
+		t.Errorf("unhealthy after the spool was sent: %v", err)
+	}
+}
+
+func TestBulkWriterDoesNotWaitForTheSink(t *testing.T) {
+	es := &fakeBulk{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	cfg := bulkConfig(t, "elasticsearch", srv.URL)
+	cfg.QueueSize, cfg.BatchSize, cfg.RetryBackoff = 1, 1, time.Hour
+	w := newTestBulkWriter(t, cfg)
+	written := make(chan error)
+	go func() {
+		for i := 0; i < 20; i++ {
+			if err := w.Write(time.Now(), []string{logLine("entry")}); err != nil {
+				written <- err
+				return
+			}
+		}
+		written <- nil
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write blocked while the sink was down")
+	}
+	w.Close()
+
+	spooled := 0
+	for _, file := range w.spoolFiles() {
+		docs, _, err := readSpool(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spooled += len(docs)
+	}
+	if spooled != 20 {
+		t.Errorf("%d documents spooled, want 20", spooled)
+	}
+}
+
+func TestBulkWriterDropsWhenSpoolIsFull(t *testing.T) {
+	cfg := bulkConfig(t, "elasticsearch", "http://127.0.0.1:1")
+	w := newTestBulkWriter(t, cfg)
+	w.Write(time.Now(), []string{`x :{"Big":"` + strings.Repeat("x", 2<<20) + `"}`})
+	w.Close()
+
+	if files := w.spoolFiles(); len(files) != 0 {
+		t.Errorf("spooled past SpoolMaxMB: %v", files)
+	}
+	entries, _ := os.ReadDir(cfg.SpoolPath)
+	if len(entries) != 0 {
+		t.Errorf("spool dir has %d entries", len(entries))
+	}
+}
+
+func TestBulkWriterLogstashTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan map[string]any, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var doc map[string]any
+			json.Unmarshal(scanner.Bytes(), &doc)
+			received <- doc
+		}
+	}()
+
+	w := newTestBulkWriter(t, bulkConfig(t, "logstash", "tcp://"+ln.Addr().String()))
+	w.Write(time.Now(), []string{logLine("main"), logLine("line")})
+	w.Close()
+
+	for _, want := range []string{"main", "line"} {
+		select {
+		case doc := <-received:
+			if doc["Id"] != want || doc["LogType"] != want {
+				t.Errorf("received %v, want %s", doc, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s document not received", want)
+		}
+	}
+}
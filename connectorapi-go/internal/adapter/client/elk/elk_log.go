@@ -9,9 +9,15 @@ import (
 	"time"
 	"strconv"
 	"strings"
+	"sync"
 
+	apiKeyUtil "connectorapi-go/pkg/apikey"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/mask"
+	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -32,6 +38,7 @@ type LogMainData struct {
 	Status				string				`json:"Status"`
 	ErrorCode			string				`json:"ErrorCode,omitempty"`
 	ErrorMessage		string				`json:"ErrorMessage,omitempty"`
+	ServedFrom			string				`json:"ServedFrom,omitempty"` // see ServedFromKey
 	UsedTime			string				`json:"UsedTime"`
 	ServerName			string				`json:"ServerName"`
 	SeqNo				string				`json:"SeqNo"`
@@ -71,6 +78,16 @@ type LogLineData struct {
 	ResponseMessage		interface{}			`json:"ResponseMessage"`
 }
 
+// Keys of the gin context shared with the middlewares that answer without the handler.
+const (
+	// ServiceNameKey holds the service name of the main log written for the request.
+	ServiceNameKey = "ELK-ServiceName"
+	// ServedFromKey says where the response came from when it was not a System I call
+	// of this request: "cache", or "coalesced" when it was copied from an identical
+	// request running at the same time. It is logged as ServedFrom.
+	ServedFromKey = "ELK-ServedFrom"
+)
+
 type ResponseMessageFormat struct {
 	ErrorCode    string `json:"ErrorCode,omitempty"`
 	ErrorMessage string `json:"ErrorMessage,omitempty"`
@@ -104,11 +121,16 @@ func GenerateELKLogMain(c *gin.Context, timesRequest time.Time, request interfac
 		responseFormat = response
 	}
 
+	// Personal data never reaches the log file, see pkg/mask.
+	routeKey := c.Request.Method + ":" + path
+	request = mask.Value(routeKey, mask.Request, request)
+	responseFormat = mask.Value(routeKey, mask.Response, responseFormat)
+
 	logData := LogMainData{
 		TIMESTAMP:        formattedLogTimestamp,
 		LOGLEVEL:         "INFO",
 		RequestID:        c.GetHeader("Api-RequestID"),
-		TraceID:          "",
+		TraceID:          tracing.TraceID(c.Request.Context()),
 		SourceIP:         "0.0.0.0",
 		DestIP:           GetLocalIP(),
 		SourceHostname:   "Unknown host",
@@ -128,6 +150,7 @@ func GenerateELKLogMain(c *gin.Context, timesRequest time.Time, request interfac
 		RequestMessage:   request,
 		ResponseDateTime: formattedCurrentTimestamp,
 		ResponseMessage:  responseFormat,
+		ServedFrom:       c.GetString(ServedFromKey),
 		UsedTime:         usedTime,
 	}
 
@@ -148,13 +171,14 @@ func GenerateELKLogMain(c *gin.Context, timesRequest time.Time, request interfac
 }
 
 func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interface{}, response interface{}, appErr *appError.AppError, apikey string, endpoint string, serviceNameMain string, serviceNameLine string, userToken string, userRef string) string {
-	timestamp := time.Now()
-	formattedCurrentTimestamp := timestamp.Format("2006-01-02 15:04:05.000")
-	formattedRequestTimestamp := formattedCurrentTimestamp
-
-	duration := timestamp.Sub(timesRequest)
-	usedTime := fmt.Sprintf("%d", duration.Milliseconds())
+	seqNo := ReserveSeqNo(c, 1)
+	return GenerateELKLogLineWithSeqNo(c, seqNo, timesRequest, request, response, appErr, apikey, endpoint, serviceNameMain, serviceNameLine, userToken, userRef)
+}
 
+// ReserveSeqNo takes n consecutive line SeqNos of the request and returns the first.
+// Calls made concurrently reserve their SeqNos up front, so each line keeps its number
+// whatever order the calls finish in.
+func ReserveSeqNo(c *gin.Context, n int) int {
 	val, exists := c.Get("seqNoCounter")
 	var counter int
 	if exists {
@@ -162,8 +186,19 @@ func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interfac
 	} else {
 		counter = 1
 	}
-	seqNo := strconv.Itoa(counter)
-	c.Set("seqNoCounter", counter+1)
+	c.Set("seqNoCounter", counter+n)
+	return counter
+}
+
+// GenerateELKLogLineWithSeqNo is GenerateELKLogLine with a SeqNo from ReserveSeqNo.
+// It only reads c and may be called from several goroutines.
+func GenerateELKLogLineWithSeqNo(c *gin.Context, seqNo int, timesRequest time.Time, request interface{}, response interface{}, appErr *appError.AppError, apikey string, endpoint string, serviceNameMain string, serviceNameLine string, userToken string, userRef string) string {
+	timestamp := time.Now()
+	formattedCurrentTimestamp := timestamp.Format("2006-01-02 15:04:05.000")
+	formattedRequestTimestamp := formattedCurrentTimestamp
+
+	duration := timestamp.Sub(timesRequest)
+	usedTime := fmt.Sprintf("%d", duration.Milliseconds())
 
 	// parsedURL, _ := url.Parse(endpoint)
 	// path := parsedURL.Path
@@ -185,9 +220,13 @@ func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interfac
 		response = ""
 	}
 
+	routeKey := c.Request.Method + ":" + c.FullPath()
+	request = mask.Value(routeKey, mask.Request, request)
+	response = mask.Value(routeKey, mask.Response, response)
+
 	logData := LogLineData{
 		RequestID:        c.GetHeader("Api-RequestID"),
-		TraceID:          "",
+		TraceID:          tracing.TraceID(c.Request.Context()),
 		SourceIP:         GetLocalIP(),
 		DestIP:           destIP,
 		SourceHostname:   "ConnectorAPI",
@@ -199,7 +238,7 @@ func GenerateELKLogLine(c *gin.Context, timesRequest time.Time, request interfac
 		UserAgent:        "",
 		Status:           "200",
 		ServerName:       "ConnectorAPI",
-		SeqNo:            seqNo,
+		SeqNo:            strconv.Itoa(seqNo),
 		Header:           extractHeader(c, apikey, "line"),
 		UserToken:		  userToken,
 		UserRef:          userRef,
@@ -266,7 +305,7 @@ func extractHeader(c *gin.Context, apikey string, logType string) string {
 
 	header := ""
 	if logType == "main" {
-		header = "[APIKey:"+c.GetHeader("Api-Key")+
+		header = "[APIKey:"+apiKeyUtil.MaskKey(c.GetHeader("Api-Key"))+
 				 "|APIChannel:"+c.GetHeader("Api-Channel")+
 				 "|APILanguage:"+apiLanguage+
 				 "|APIAuthorizeToken:"+c.GetHeader("Api-AuthorizationToken")+
@@ -333,7 +372,39 @@ func GetLocalIP() string {
 // }
 
 
+var (
+	writerMu      sync.RWMutex
+	defaultWriter LogWriter
+	fileMu        sync.Mutex
+)
+
+// SetWriter makes FinalELKLog hand its lines to w instead of writing the file itself.
+func SetWriter(w LogWriter) {
+	writerMu.Lock()
+	defer writerMu.Unlock()
+	defaultWriter = w
+}
+
+func currentWriter() LogWriter {
+	writerMu.RLock()
+	defer writerMu.RUnlock()
+	return defaultWriter
+}
+
+// WriterHealth reports the last failure of the writer set by SetWriter, or nil.
+func WriterHealth() error {
+	if w, ok := currentWriter().(interface{ Health() error }); ok {
+		return w.Health()
+	}
+	return nil
+}
+
+// WriteLogToFile appends the lines to the day's file synchronously. It is used when
+// no LogWriter is set, e.g. in tests.
 func WriteLogToFile(logLines []string, timestamp time.Time, elkPath string) error {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
 	// if len(logLines) == 0 {
 	// 	return nil
 	// }
@@ -368,6 +439,9 @@ func WriteLogToFile(logLines []string, timestamp time.Time, elkPath string) erro
 
 type HandleErrorResponse func(c *gin.Context, appErr *appError.AppError)
 
+// FinalELKLog writes the main log of a request followed by its line logs.
+// A log that cannot be generated or written is logged and counted in
+// elk_log_errors_total, the request itself still succeeds; the result is always true.
 func FinalELKLog(
 	c *gin.Context,
 	logList *[]string,
@@ -383,11 +457,11 @@ func FinalELKLog(
 	elkPath string,
 	handleErrorResponse HandleErrorResponse,
 ) bool {
+	c.Set(ServiceNameKey, serviceName)
 	logMain := GenerateELKLogMain(c, timestamp, reqBody, respBody, appErr, serviceName, userToken, userRef)
 	if logMain == "" {
-		logger.Errorw("Error generating log: %v", logMain)
-		handleErrorResponse(c, appError.ErrInternalServer)
-		return false
+		metrics.ELKLogErrorsTotal.With(prometheus.Labels{"op": "generate"}).Inc()
+		logger.Errorw("Error generating ELK main log", "serviceName", serviceName)
 	}
 
 	allLogs := []string{logMain}
@@ -400,10 +474,13 @@ func FinalELKLog(
 		allLogs = append(allLogs, additionalLines...)
 	}
 
-	if err := WriteLogToFile(allLogs, timestamp, elkPath); err != nil {
-		logger.Errorw("Error writing log file:", err)
-		handleErrorResponse(c, appError.ErrInternalServer)
-		return false
+	if w := currentWriter(); w != nil {
+		if err := w.Write(timestamp, allLogs); err != nil {
+			logger.Warnw("ELK log entry dropped", "error", err, "serviceName", serviceName)
+		}
+	} else if err := WriteLogToFile(allLogs, timestamp, elkPath); err != nil {
+		metrics.ELKLogErrorsTotal.With(prometheus.Labels{"op": "write"}).Inc()
+		logger.Errorw("Error writing ELK log file", "error", err)
 	}
 
 	return true
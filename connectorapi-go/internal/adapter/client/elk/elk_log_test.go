@@ -0,0 +1,107 @@
+package elk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/mask"
+	"connectorapi-go/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const fullPanPath = "/Api/Mobile/MobileFullPAN"
+
+var pans = []string{"4111111111111111", "5500005555555559"}
+
+type cardRq struct {
+	CardNo   string `json:"CardNo"`
+	CardCode string `json:"CardCode"`
+}
+
+type fullPanRq struct {
+	IDCardNo   string   `json:"IDCardNo"`
+	CardListRq []cardRq `json:"CardList_rq"`
+}
+
+// logLines serves one MobileFullPAN call that writes a main and a line log.
+func logLines(t *testing.T) (main string, line string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	mask.SetDefault(mask.New(config.MaskingConfig{
+		Fields: map[string]string{"CardNo": "pan", "IDCardNo": "last4"},
+		Layouts: map[string]config.MaskLayout{
+			"POST:" + fullPanPath: {
+				Request:  []config.MaskField{{Name: "IDCardNo", Offset: 0, Length: 20}, {Name: "CardNo", Offset: 20, Length: 16}},
+				Response: []config.MaskField{{Name: "IDCardNo", Offset: 0, Length: 20}, {Name: "CardNo", Offset: 24, Length: 16, Every: 61}},
+			},
+		},
+	}))
+	t.Cleanup(func() { mask.SetDefault(mask.New(config.MaskingConfig{})) })
+
+	header := strings.Repeat("0", 123)
+	tcpRequest := header + "12345678901234567890" + pans[0] + "MB"
+	tcpResponse := header + "12345678901234567890" + "0002"
+	for _, pan := range pans {
+		tcpResponse += pan + strings.Repeat("1", 30) + "0102" + "00" + "20290131" + "N"
+	}
+
+	router := gin.New()
+	router.POST(fullPanPath, func(c *gin.Context) {
+		start := time.Now()
+		req := fullPanRq{IDCardNo: "1234567890123", CardListRq: []cardRq{{pans[0], "01"}, {pans[1], "02"}}}
+		line = GenerateELKLogLine(c, start, map[string]string{"data": tcpRequest}, map[string]string{"data": tcpResponse}, nil, "", "10.0.0.1:40110", "MobileFullPan", "MobileFullPan", "", "")
+		main = GenerateELKLogMain(c, start, req, map[string]interface{}{"CardList_rs": []cardRq{{pans[1], "02"}}}, nil, "MobileFullPan", "", "")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, fullPanPath, nil))
+	return main, line
+}
+
+func TestELKLogsNeverContainPAN(t *testing.T) {
+	main, line := logLines(t)
+	if main == "" || line == "" {
+		t.Fatal("log not generated")
+	}
+	for _, pan := range pans {
+		for name, log := range map[string]string{"main": main, "line": line} {
+			if strings.Contains(log, pan) {
+				t.Errorf("%s log contains card number %s: %s", name, pan, log)
+			}
+		}
+	}
+	for _, want := range []string{"411111******1111", "550000******5559", "*********0123"} {
+		if !strings.Contains(main, want) {
+			t.Errorf("main log lacks %s: %s", want, main)
+		}
+	}
+	if strings.Contains(line, "1234567890123") || !strings.Contains(line, "****************7890") {
+		t.Errorf("line log ID card number not masked: %s", line)
+	}
+}
+
+func TestELKLogsCarryTraceID(t *testing.T) {
+	if _, err := tracing.Init(context.Background(), config.TracingConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	var main, line string
+	router := gin.New()
+	router.POST(fullPanPath, func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tracing.Extract(c.Request.Context(), c.Request.Header))
+		line = GenerateELKLogLine(c, time.Now(), "", "", nil, "", "10.0.0.1:40110", "MobileFullPan", "MobileFullPan", "", "")
+		main = GenerateELKLogMain(c, time.Now(), "", "", nil, "MobileFullPan", "", "")
+	})
+	req := httptest.NewRequest(http.MethodPost, fullPanPath, nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	for name, log := range map[string]string{"main": main, "line": line} {
+		if !strings.Contains(log, `"TraceID":"4bf92f3577b34da6a3ce929d0e0e4736"`) {
+			t.Errorf("%s log lacks the trace ID: %s", name, log)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package elk
+
+import (
+	"net/url"
+
+	"connectorapi-go/pkg/config"
+)
+
+// ValidateConfig checks cfg.ELK, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	e := cfg.ELK
+	if e.OnFull != "drop" && e.OnFull != "block" {
+		report.Add(config.SeverityError, "elk", "unknown onFull %q, use drop or block", e.OnFull)
+	}
+	if e.QueueSize <= 0 || e.BatchSize <= 0 || e.FlushInterval <= 0 {
+		report.Add(config.SeverityError, "elk", "queueSize, batchSize and flushInterval must be positive")
+	}
+	if e.MaxSizeMB < 0 || e.MaxAge < 0 || e.CompressAfter < 0 || e.BlockTimeout < 0 {
+		report.Add(config.SeverityError, "elk", "maxSizeMB, maxAge, compressAfter and blockTimeout cannot be negative")
+	}
+	if e.MaxAge > 0 && e.CompressAfter >= e.MaxAge {
+		report.Add(config.SeverityWarning, "elk", "compressAfter %s is not below maxAge %s, files are deleted uncompressed", e.CompressAfter, e.MaxAge)
+	}
+
+	switch e.Sink {
+	case "file":
+		return
+	case "elasticsearch", "logstash":
+	default:
+		report.Add(config.SeverityError, "elk", "unknown sink %q, use file, elasticsearch or logstash", e.Sink)
+		return
+	}
+	u, err := url.Parse(e.URL)
+	switch {
+	case err != nil || u.Host == "":
+		report.Add(config.SeverityError, "elk", "sink %s needs a url", e.Sink)
+	case e.Sink == "elasticsearch" && u.Scheme != "http" && u.Scheme != "https":
+		report.Add(config.SeverityError, "elk", "elasticsearch url must be http or https")
+	case e.Sink == "logstash" && u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "tcp":
+		report.Add(config.SeverityError, "elk", "logstash url must be http, https or tcp")
+	}
+	if e.Sink == "elasticsearch" && e.Index == "" {
+		report.Add(config.SeverityError, "elk", "elasticsearch sink needs an index")
+	}
+	if e.Timeout <= 0 || e.RetryBackoff <= 0 || e.SpoolMaxMB < 0 {
+		report.Add(config.SeverityError, "elk", "timeout and retryBackoff must be positive, spoolMaxMB not negative")
+	}
+	if e.SpoolPath == "" {
+		report.Add(config.SeverityWarning, "elk", "no spoolPath, batches are dropped while the sink is unreachable")
+	}
+}
@@ -0,0 +1,70 @@
+package elk
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() config.ELKConfig {
+		return config.ELKConfig{Sink: "file", QueueSize: 10000, OnFull: "drop", BatchSize: 200, FlushInterval: time.Second, MaxSizeMB: 512, MaxAge: 30 * 24 * time.Hour, CompressAfter: 24 * time.Hour}
+	}
+	elasticsearch := func(e *config.ELKConfig) {
+		e.Sink, e.URL, e.Index = "elasticsearch", "https://es:9200", "connector"
+		e.Timeout, e.RetryBackoff, e.SpoolPath, e.SpoolMaxMB = 10*time.Second, time.Second, "./logs/spool", 1024
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(e *config.ELKConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(e *config.ELKConfig) {}, nil},
+		{"block with timeout", func(e *config.ELKConfig) { e.OnFull, e.BlockTimeout = "block", time.Second }, nil},
+		{"unknown onFull", func(e *config.ELKConfig) { e.OnFull = "wait" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: `unknown onFull "wait", use drop or block`}}},
+		{"no queue", func(e *config.ELKConfig) { e.QueueSize = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "queueSize, batchSize and flushInterval must be positive"}}},
+		{"no flush interval", func(e *config.ELKConfig) { e.FlushInterval = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "queueSize, batchSize and flushInterval must be positive"}}},
+		{"negative block timeout", func(e *config.ELKConfig) { e.BlockTimeout = -time.Second },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "maxSizeMB, maxAge, compressAfter and blockTimeout cannot be negative"}}},
+		{"compress after max age", func(e *config.ELKConfig) { e.MaxAge, e.CompressAfter = time.Hour, 2*time.Hour },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "elk", Message: "compressAfter 2h0m0s is not below maxAge 1h0m0s, files are deleted uncompressed"}}},
+		{"valid elasticsearch", elasticsearch, nil},
+		{"logstash over tcp", func(e *config.ELKConfig) {
+			elasticsearch(e)
+			e.Sink, e.URL, e.Index = "logstash", "tcp://logstash:5000", ""
+		}, nil},
+		{"unknown sink", func(e *config.ELKConfig) { e.Sink = "kafka" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: `unknown sink "kafka", use file, elasticsearch or logstash`}}},
+		{"sink without url", func(e *config.ELKConfig) { elasticsearch(e); e.URL = "" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "sink elasticsearch needs a url"}}},
+		{"elasticsearch over tcp", func(e *config.ELKConfig) { elasticsearch(e); e.URL = "tcp://es:9200" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "elasticsearch url must be http or https"}}},
+		{"logstash over ftp", func(e *config.ELKConfig) { elasticsearch(e); e.Sink, e.URL = "logstash", "ftp://logstash:5000" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "logstash url must be http, https or tcp"}}},
+		{"elasticsearch without index", func(e *config.ELKConfig) { elasticsearch(e); e.Index = "" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "elasticsearch sink needs an index"}}},
+		{"no retry backoff", func(e *config.ELKConfig) { elasticsearch(e); e.RetryBackoff = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "elk", Message: "timeout and retryBackoff must be positive, spoolMaxMB not negative"}}},
+		{"no spool", func(e *config.ELKConfig) { elasticsearch(e); e.SpoolPath = "" },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "elk", Message: "no spoolPath, batches are dropped while the sink is unreachable"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{ELK: valid()}
+			tt.mutate(&cfg.ELK)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, config.ValidationScope{})
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
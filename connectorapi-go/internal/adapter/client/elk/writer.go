@@ -0,0 +1,387 @@
+package elk
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrQueueFull    = errors.New("ELK log queue is full")
+	ErrWriterClosed = errors.New("ELK log writer is closed")
+)
+
+// maintainInterval is how often old files are compressed and deleted.
+const maintainInterval = 10 * time.Minute
+
+// LogWriter takes the log lines of one request. Lines of one call stay together.
+type LogWriter interface {
+	Write(timestamp time.Time, lines []string) error
+	Close() error
+}
+
+type logEntry struct {
+	timestamp time.Time
+	lines     []string
+}
+
+// entryQueue is the bounded queue between the requests and the goroutine of a writer.
+type entryQueue struct {
+	config config.ELKConfig
+	mu     sync.RWMutex // held for writing by close, so no put sends on a closed channel
+	closed bool
+	ch     chan logEntry
+}
+
+func newEntryQueue(cfg config.ELKConfig) *entryQueue {
+	return &entryQueue{config: cfg, ch: make(chan logEntry, cfg.QueueSize)}
+}
+
+// put queues the lines. With onFull "drop" a full queue drops them at once; with
+// "block" the caller waits up to BlockTimeout for room. Dropped entries are counted
+// in elk_log_dropped_total.
+func (q *entryQueue) put(timestamp time.Time, lines []string) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		metrics.ELKLogDroppedTotal.With(prometheus.Labels{"reason": "closed"}).Inc()
+		return ErrWriterClosed
+	}
+
+	e := logEntry{timestamp: timestamp, lines: lines}
+	select {
+	case q.ch <- e:
+		metrics.ELKLogQueueDepth.Set(float64(len(q.ch)))
+		return nil
+	default:
+	}
+	if q.config.OnFull == "block" {
+		var timeout <-chan time.Time
+		if q.config.BlockTimeout > 0 {
+			timer := time.NewTimer(q.config.BlockTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case q.ch <- e:
+			metrics.ELKLogQueueDepth.Set(float64(len(q.ch)))
+			return nil
+		case <-timeout:
+		}
+	}
+	metrics.ELKLogDroppedTotal.With(prometheus.Labels{"reason": "queue_full"}).Inc()
+	return ErrQueueFull
+}
+
+// close stops new entries; the goroutine still receives the queued ones.
+func (q *entryQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+}
+
+// writerHealth holds the last failure of a writer for the readiness report.
+type writerHealth struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (h *writerHealth) set(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = err
+}
+
+func (h *writerHealth) get() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// FileWriter appends log entries to LOG<yyyymmdd>.txt in one background goroutine, so
+// requests never wait for the disk and lines of concurrent requests never interleave.
+// A day's file reaching MaxSizeMB is renamed to LOG<yyyymmdd>.<n>.txt and a new one
+// started. Files are fsynced per batch; old files are compressed and deleted.
+type FileWriter struct {
+	dir    string
+	config config.ELKConfig
+	logger *zap.SugaredLogger
+	queue  *entryQueue
+	done   chan struct{}
+	health writerHealth
+
+	// Owned by the writer goroutine.
+	file         *os.File
+	buf          *bufio.Writer
+	day          string
+	size         int64
+	pending      int
+	lastMaintain time.Time
+	now          func() time.Time
+}
+
+// NewFileWriter starts a writer for dir, e.g. ELKPath.
+func NewFileWriter(dir string, cfg config.ELKConfig, logger *zap.SugaredLogger) *FileWriter {
+	w := &FileWriter{
+		dir:    dir,
+		config: cfg,
+		logger: logger,
+		queue:  newEntryQueue(cfg),
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+	go w.run()
+	return w
+}
+
+// Write queues the lines, see entryQueue.put.
+func (w *FileWriter) Write(timestamp time.Time, lines []string) error {
+	return w.queue.put(timestamp, lines)
+}
+
+// Close writes every queued entry, syncs and closes the file. Later writes are dropped.
+func (w *FileWriter) Close() error {
+	w.queue.close()
+	<-w.done
+	return nil
+}
+
+func (w *FileWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		w.fail("open", err)
+	}
+	w.maintain()
+
+	for {
+		select {
+		case e, ok := <-w.queue.ch:
+			if !ok {
+				w.sync()
+				w.closeFile()
+				metrics.ELKLogQueueDepth.Set(0)
+				return
+			}
+			w.write(e)
+			if w.pending >= w.config.BatchSize {
+				w.sync()
+			}
+		case <-ticker.C:
+			metrics.ELKLogQueueDepth.Set(float64(len(w.queue.ch)))
+			w.sync()
+			if w.now().Sub(w.lastMaintain) >= maintainInterval {
+				w.maintain()
+			}
+		}
+	}
+}
+
+func (w *FileWriter) write(e logEntry) {
+	day := e.timestamp.Format("20060102")
+	if w.file == nil || day != w.day {
+		w.closeFile()
+		if !w.open(day) {
+			metrics.ELKLogDroppedTotal.With(prometheus.Labels{"reason": "write_error"}).Inc()
+			return
+		}
+		w.maintain()
+	}
+	if max := int64(w.config.MaxSizeMB) << 20; max > 0 && w.size >= max {
+		w.rotate()
+		if w.file == nil {
+			metrics.ELKLogDroppedTotal.With(prometheus.Labels{"reason": "write_error"}).Inc()
+			return
+		}
+	}
+
+	for _, line := range e.lines {
+		if line == "" {
+			continue
+		}
+		n, err := w.buf.WriteString(line + "\n")
+		w.size += int64(n)
+		if err != nil {
+			w.fail("write", err)
+			w.closeFile()
+			return
+		}
+	}
+	w.pending++
+}
+
+func (w *FileWriter) path(day string) string {
+	return filepath.Join(w.dir, "LOG"+day+".txt")
+}
+
+func (w *FileWriter) open(day string) bool {
+	f, err := os.OpenFile(w.path(day), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		w.fail("open", err)
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		w.fail("open", err)
+		return false
+	}
+	w.file, w.buf, w.day, w.size = f, bufio.NewWriterSize(f, 64*1024), day, info.Size()
+	return true
+}
+
+// rotate renames the full file of the day to the next free LOG<day>.<n>.txt.
+func (w *FileWriter) rotate() {
+	day := w.day
+	w.sync()
+	w.closeFile()
+	for n := 1; ; n++ {
+		rotated := filepath.Join(w.dir, fmt.Sprintf("LOG%s.%d.txt", day, n))
+		if exists(rotated) || exists(rotated+".gz") {
+			continue
+		}
+		if err := os.Rename(w.path(day), rotated); err != nil {
+			w.fail("rotate", err)
+		}
+		break
+	}
+	w.open(day)
+}
+
+func (w *FileWriter) sync() {
+	if w.file == nil || w.pending == 0 {
+		return
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.fail("write", err)
+	} else if err := w.file.Sync(); err != nil {
+		w.fail("sync", err)
+	} else {
+		w.health.set(nil)
+	}
+	w.pending = 0
+}
+
+func (w *FileWriter) closeFile() {
+	if w.file == nil {
+		return
+	}
+	w.sync()
+	w.file.Close()
+	w.file, w.buf = nil, nil
+}
+
+// maintain deletes log files older than MaxAge and compresses the others that have
+// not been written for CompressAfter. The file in use is never touched.
+func (w *FileWriter) maintain() {
+	w.lastMaintain = w.now()
+	if w.config.MaxAge <= 0 && w.config.CompressAfter <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return
+	}
+	active := ""
+	if w.file != nil {
+		active = filepath.Base(w.path(w.day))
+	}
+	now := w.now()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == active || !strings.HasPrefix(name, "LOG") {
+			continue
+		}
+		isText, isGzip := strings.HasSuffix(name, ".txt"), strings.HasSuffix(name, ".txt.gz")
+		if !isText && !isGzip {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		age := now.Sub(info.ModTime())
+		path := filepath.Join(w.dir, name)
+		switch {
+		case w.config.MaxAge > 0 && age > w.config.MaxAge:
+			if err := os.Remove(path); err != nil {
+				w.fail("retention", err)
+			}
+		case isText && w.config.CompressAfter > 0 && age > w.config.CompressAfter:
+			if err := compress(path); err != nil {
+				w.fail("compress", err)
+			}
+		}
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// compress replaces path with path.gz, keeping its modification time for retention.
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if syncErr := dst.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	os.Chtimes(path+".gz", info.ModTime(), info.ModTime())
+	return os.Remove(path)
+}
+
+// Health returns the last failure, cleared once a batch is written again.
+func (w *FileWriter) Health() error {
+	return w.health.get()
+}
+
+func (w *FileWriter) fail(op string, err error) {
+	w.health.set(fmt.Errorf("%s: %w", op, err))
+	metrics.ELKLogErrorsTotal.With(prometheus.Labels{"op": op}).Inc()
+	w.logger.Errorw("ELK log writer failed", "op", op, "dir", w.dir, "error", err)
+}
@@ -0,0 +1,182 @@
+package elk
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Init()
+	os.Exit(m.Run())
+}
+
+func writerConfig() config.ELKConfig {
+	return config.ELKConfig{QueueSize: 100, OnFull: "block", BatchSize: 10, FlushInterval: 10 * time.Millisecond}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestFileWriterKeepsEntriesTogether(t *testing.T) {
+	dir := t.TempDir()
+	w := NewFileWriter(dir, writerConfig(), zap.NewNop().Sugar())
+	day := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local)
+
+	var wg sync.WaitGroup
+	for r := 0; r < 50; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			lines := []string{fmt.Sprintf("main %d", r), fmt.Sprintf("line %d.1", r), "", fmt.Sprintf("line %d.2", r)}
+			if err := w.Write(day, lines); err != nil {
+				t.Error(err)
+			}
+		}(r)
+	}
+	wg.Wait()
+	w.Close()
+
+	lines := readLines(t, filepath.Join(dir, "LOG20261019.txt"))
+	if len(lines) != 150 {
+		t.Fatalf("%d lines written, want 150", len(lines))
+	}
+	for i := 0; i < len(lines); i += 3 {
+		var r int
+		fmt.Sscanf(lines[i], "main %d", &r)
+		if lines[i+1] != fmt.Sprintf("line %d.1", r) || lines[i+2] != fmt.Sprintf("line %d.2", r) {
+			t.Fatalf("entry of request %d interleaved: %q", r, lines[i:i+3])
+		}
+	}
+	if err := w.Write(day, []string{"late"}); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("write after Close: err = %v", err)
+	}
+}
+
+func TestFileWriterRotatesBySizeAndDate(t *testing.T) {
+	dir := t.TempDir()
+	cfg := writerConfig()
+	cfg.MaxSizeMB = 1
+	w := NewFileWriter(dir, cfg, zap.NewNop().Sugar())
+	day := time.Date(2026, 10, 19, 23, 59, 0, 0, time.Local)
+	big := strings.Repeat("x", 300*1024)
+	for i := 0; i < 5; i++ {
+		w.Write(day, []string{big})
+	}
+	w.Write(day.Add(2*time.Minute), []string{"next day"})
+	w.Close()
+
+	for _, name := range []string{"LOG20261019.1.txt", "LOG20261019.txt", "LOG20261020.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s missing: %v", name, err)
+		}
+	}
+	if lines := readLines(t, filepath.Join(dir, "LOG20261019.1.txt")); len(lines) != 4 {
+		t.Errorf("rotated file has %d entries, want 4", len(lines))
+	}
+}
+
+func TestFileWriterRetentionAndCompression(t *testing.T) {
+	dir := t.TempDir()
+	old := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(name+"\n"), 0644)
+		mtime := time.Now().Add(-age)
+		os.Chtimes(path, mtime, mtime)
+		return path
+	}
+	expired := old("LOG20260901.txt", 40*24*time.Hour)
+	expiredGzip := old("LOG20260902.txt.gz", 40*24*time.Hour)
+	idle := old("LOG20261018.txt", 2*time.Hour)
+	recent := old("LOG20261019.1.txt", time.Minute)
+	other := old("app.log", 40*24*time.Hour)
+
+	cfg := writerConfig()
+	cfg.MaxAge, cfg.CompressAfter = 30*24*time.Hour, time.Hour
+	w := NewFileWriter(dir, cfg, zap.NewNop().Sugar())
+	w.Close()
+
+	for _, gone := range []string{expired, expiredGzip, idle} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s still there", gone)
+		}
+	}
+	for _, kept := range []string{recent, other, idle + ".gz"} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s missing: %v", kept, err)
+		}
+	}
+	f, _ := os.Open(idle + ".gz")
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); string(data) != "LOG20261018.txt\n" {
+		t.Errorf("compressed content = %q", data)
+	}
+}
+
+func TestFileWriterFullQueue(t *testing.T) {
+	for _, onFull := range []string{"drop", "block"} {
+		// Not started, so nothing drains the queue.
+		w := &FileWriter{queue: newEntryQueue(config.ELKConfig{QueueSize: 1, OnFull: onFull, BlockTimeout: 10 * time.Millisecond})}
+		if err := w.Write(time.Now(), []string{"first"}); err != nil {
+			t.Fatalf("%s: %v", onFull, err)
+		}
+		start := time.Now()
+		if err := w.Write(time.Now(), []string{"second"}); !errors.Is(err, ErrQueueFull) {
+			t.Errorf("%s: err = %v, want ErrQueueFull", onFull, err)
+		}
+		if waited := time.Since(start); (onFull == "block") != (waited >= 10*time.Millisecond) {
+			t.Errorf("%s: waited %s", onFull, waited)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(time.Time, []string) error { return ErrQueueFull }
+func (failingWriter) Close() error                    { return nil }
+
+func TestFinalELKLogIgnoresWriteFailures(t *testing.T) {
+	SetWriter(failingWriter{})
+	defer SetWriter(nil)
+
+	router := gin.New()
+	router.POST("/Api/Test", func(c *gin.Context) {
+		ok := FinalELKLog(c, nil, time.Now(), nil, gin.H{"ok": true}, nil, "Test", "", "", nil, zap.NewNop().Sugar(), t.TempDir(), func(c *gin.Context, _ *appError.AppError) {
+			t.Error("logging failure reached the client")
+		})
+		if ok {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		}
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/Api/Test", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
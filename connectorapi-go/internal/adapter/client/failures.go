@@ -0,0 +1,30 @@
+package client
+
+import (
+	"time"
+
+	"connectorapi-go/pkg/failures"
+	"connectorapi-go/pkg/mask"
+)
+
+// recordFailure keeps an exchange that did not answer OK in the failures ring,
+// with both messages masked by the layout of its route.
+func recordFailure(m *exchangeMetrics, address, payload, response, result string, err error) {
+	if result == "OK" {
+		return
+	}
+	exchange := failures.Exchange{
+		Time:       time.Now(),
+		RequestID:  headerRequestID(payload),
+		Route:      m.route,
+		Address:    address,
+		Result:     result,
+		DurationMs: milliseconds(time.Since(m.start)),
+		Request:    mask.Payload(m.route, mask.Request, payload),
+		Response:   mask.Payload(m.route, mask.Response, response),
+	}
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	failures.Add(exchange)
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/health"
+	"connectorapi-go/pkg/reqid"
+)
+
+// probeRoute is the route of echo probes in the systemi_* metrics and spans.
+const probeRoute = "health-probe"
+
+// ConnectProbe checks that a System I port accepts connections.
+func ConnectProbe() health.Probe {
+	return func(ctx context.Context, address string) error {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// EchoProbe sends the ping request of cfg through tcpClient and expects a blank
+// response code or one of cfg.OKCodes. tcpClient should time out within the probe timeout.
+func EchoProbe(tcpClient TCPSocketClient, cfg config.EchoProbe) health.Probe {
+	return func(ctx context.Context, address string) error {
+		header := utils.BuildFixedLengthHeader(cfg.System, cfg.Service, cfg.Format, utils.PadOrTruncate(reqid.Next(), 20), utils.PadIntWithZero(len(cfg.Body), 5))
+		response, err := tcpClient.SendAndReceive(WithRoute(ctx, probeRoute), address, header+cfg.Body)
+		code := utils.SystemIResultCode(response, err)
+		if err != nil {
+			return err
+		}
+		if code == "OK" {
+			return nil
+		}
+		for _, ok := range cfg.OKCodes {
+			if code == ok {
+				return nil
+			}
+		}
+		return fmt.Errorf("ping answered %s", code)
+	}
+}
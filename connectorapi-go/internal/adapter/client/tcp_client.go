@@ -2,112 +2,94 @@ package client
 
 import (
 	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/tracing"
+	"context"
 	"fmt"             
-	// "io"
 	"net"  // For TCP connections
+	"strconv"
+	"strings"
 	"time" // For timeouts
 	"bufio"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 // TCPSocketClient defines the interface for a TCP socket client.
+// ctx carries the trace and the route of the calling request, see WithRoute.
 type TCPSocketClient interface {
-	SendAndReceive(address string, combinedPayloadString string) (string, error)
+	SendAndReceive(ctx context.Context, address string, combinedPayloadString string) (string, error)
+}
+
+type routeKey struct{}
+
+// WithRoute returns ctx naming the METHOD:/path whose System I calls it carries.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFrom returns the route set by WithRoute, or "".
+func RouteFrom(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
 }
 
 // BasicTCPSocketClient implements TCPSocketClient with length-prefixing and TIS-620 encoding.
 type BasicTCPSocketClient struct {
 	DialTimeout      time.Duration // Timeout for establishing the connection
 	ReadWriteTimeout time.Duration // Timeout for read/write operations
+	LogPayloads      bool          // add the masked request and response to the log event of every exchange
+
+	logger *zap.SugaredLogger
 }
 
 // NewBasicTCPSocketClient creates a new instance of BasicTCPSocketClient.
-func NewBasicTCPSocketClient(dialTimeout, readWriteTimeout time.Duration) *BasicTCPSocketClient {
+// Every exchange is logged to logger at debug level, failed ones as warnings.
+func NewBasicTCPSocketClient(dialTimeout, readWriteTimeout time.Duration, logger *zap.SugaredLogger) *BasicTCPSocketClient {
 	return &BasicTCPSocketClient{
 		DialTimeout:      dialTimeout,
 		ReadWriteTimeout: readWriteTimeout,
+		logger:           logger,
 	}
 }
 
-// func (c *BasicTCPSocketClient) SendAndReceive(address string, combinedPayloadString string) (string, error) {
-// 	fmt.Println("Connecting to the server...")
-// 	conn, err := net.DialTimeout("tcp", address, c.DialTimeout)
-// 	if err != nil {
-// 		return "", fmt.Errorf("ER040: " + err.Error())
-// 	}
-// 	defer conn.Close()
-
-// 	fmt.Println("Encoding request to CP874...")
-// 	encodedRequest, err := utils.Utf8ToCP874(combinedPayloadString)
-// 	if err != nil {
-// 		return "", fmt.Errorf("ER099: Failed to encode request to CP874: " + err.Error())
-// 	}
-
-// 	_, err = conn.Write(encodedRequest)
-// 	if err != nil {
-// 		return "", fmt.Errorf("ER060: " + err.Error())
-// 	}
-
-// 	fmt.Println("Request sent (UTF-8):", combinedPayloadString)
-
-// 	fmt.Println("Reading response until \\r\\n...")
-// 	reader := bufio.NewReader(conn)
-// 	var fullResponse []byte
-
-// 	for {
-// 		conn.SetReadDeadline(time.Now().Add(c.DialTimeout))
-
-// 		line, err := reader.ReadBytes('\n')
-// 		if err != nil {
-// 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-// 				return "", fmt.Errorf("ER060: read timeout")
-// 			}
-// 			if err == io.EOF {
-// 				break
-// 			}
-// 			return "", fmt.Errorf("ER060: " + err.Error())
-// 		}
-
-// 		fullResponse = append(fullResponse, line...)
-
-// 		if len(fullResponse) >= 2 && fullResponse[len(fullResponse)-2] == '\r' && fullResponse[len(fullResponse)-1] == '\n' {
-// 			break
-// 		}
-// 	}
-
-// 	decoded, err := utils.DecodeCP874(fullResponse)
-// 	if err != nil {
-// 		return "", fmt.Errorf("ER099: Failed to decode response from CP874: " + err.Error())
-// 	}
-
-// 	fmt.Println("Final result (UTF-8):", decoded)
-// 	return decoded, nil
-// }
-
-func (c *BasicTCPSocketClient) SendAndReceive(address string, combinedPayloadString string) (string, error) {
-	fmt.Println("Connecting to the server...")
+func (c *BasicTCPSocketClient) SendAndReceive(ctx context.Context, address string, combinedPayloadString string) (responseStr string, err error) {
+	span := startSpan(ctx, address, combinedPayloadString)
+	exchange := startExchange(RouteFrom(ctx), address)
+	defer func() {
+		result := utils.SystemIResultCode(responseStr, err)
+		span.SetAttributes(attribute.String("systemi.result", result))
+		tracing.End(span, err)
+		exchange.done(result, err)
+		c.logExchange(exchange, address, combinedPayloadString, responseStr, result, err)
+		recordFailure(exchange, address, combinedPayloadString, responseStr, result, err)
+	}()
+
+	dialStart := time.Now()
 	conn, err := net.DialTimeout("tcp", address, c.DialTimeout)
+	exchange.dialed(time.Since(dialStart))
 	if err != nil {
 		return "", fmt.Errorf("ER040: " + err.Error())
 	}
 	defer conn.Close()
 
-	fmt.Println("Encoding request to CP874...")
 	encodedRequest, err := utils.Utf8ToCP874(combinedPayloadString)
 	if err != nil {
 		return "", fmt.Errorf("ER099: Failed to encode request to CP874: " + err.Error())
 	}
 
-	_, err = conn.Write(encodedRequest)
+	n, err := conn.Write(encodedRequest)
+	exchange.sent(n)
 	if err != nil {
 		return "", fmt.Errorf("ER060: " + err.Error())
 	}
 
-	fmt.Println("Request sent (UTF-8):", combinedPayloadString)
-
 	conn.SetReadDeadline(time.Now().Add(c.ReadWriteTimeout))
 
 	reader := bufio.NewReader(conn)
 	responseLine, err := reader.ReadBytes('\n')
+	exchange.received(len(responseLine))
 	if err != nil {
 		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 			return "", fmt.Errorf("ER050: read timeout")
@@ -120,6 +102,26 @@ func (c *BasicTCPSocketClient) SendAndReceive(address string, combinedPayloadStr
 		return "", fmt.Errorf("ER099: Failed to decode response from CP874: " + err.Error())
 	}
 
-	fmt.Println("Final result (UTF-8):", decoded)
 	return decoded, nil
-}
\ No newline at end of file
+}
+
+// startSpan starts the client span of one System I exchange. The service code and
+// format come from the fixed-length header of the payload.
+func startSpan(ctx context.Context, address string, payload string) trace.Span {
+	host, port, _ := net.SplitHostPort(address)
+	portNo, _ := strconv.Atoi(port)
+	attrs := []attribute.KeyValue{
+		attribute.String("connector.route", RouteFrom(ctx)),
+		attribute.String("server.address", host),
+		attribute.Int("server.port", portNo),
+	}
+	if len(payload) >= 28 {
+		attrs = append(attrs,
+			attribute.String("systemi.system", strings.TrimSpace(payload[:10])),
+			attribute.String("systemi.service", strings.TrimSpace(payload[10:25])),
+			attribute.String("systemi.format", payload[25:28]),
+		)
+	}
+	_, span := tracing.StartKind(ctx, "SystemI SendAndReceive", trace.SpanKindClient, attrs...)
+	return span
+}
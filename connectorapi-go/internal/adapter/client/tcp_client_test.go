@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/failures"
+	"connectorapi-go/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Init()
+	os.Exit(m.Run())
+}
+
+// fakeSystemI accepts one connection and answers with reply, or stays silent when
+// reply is empty.
+func fakeSystemI(t *testing.T, reply string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 4096)
+		conn.Read(buf)
+		if reply == "" {
+			time.Sleep(time.Second)
+			return
+		}
+		conn.Write([]byte(reply))
+	}()
+	return ln.Addr().String()
+}
+
+func payload() string {
+	return utils.BuildFixedLengthHeader("MOB_APP", "INQ_CUST_INFO", "001", "RQ1", "00020") + strings.Repeat("1", 20)
+}
+
+func TestSendAndReceiveMetrics(t *testing.T) {
+	route := "POST:/Api/Test/Metrics"
+	request := payload()
+	response := request[:67] + utils.PadOrTruncate("SVC117", 6) + utils.PadOrTruncate("ID card not found", 50) + "\r\n"
+	address := fakeSystemI(t, response)
+	_, port, _ := net.SplitHostPort(address)
+
+	client := NewBasicTCPSocketClient(time.Second, time.Second, zap.NewNop().Sugar())
+	got, err := client.SendAndReceive(WithRoute(context.Background(), route), address, request)
+	if err != nil || got != response {
+		t.Fatalf("SendAndReceive = %q, %v", got, err)
+	}
+
+	if n := testutil.ToFloat64(metrics.SystemIResponsesTotal.WithLabelValues(route, "SVC117")); n != 1 {
+		t.Errorf("SVC117 responses = %v, want 1", n)
+	}
+	if n := testutil.ToFloat64(metrics.SystemISentBytesTotal.WithLabelValues(route, port)); n != float64(len(request)) {
+		t.Errorf("sent bytes = %v, want %d", n, len(request))
+	}
+	if n := testutil.ToFloat64(metrics.SystemIReceivedBytesTotal.WithLabelValues(route, port)); n != float64(len(response)) {
+		t.Errorf("received bytes = %v, want %d", n, len(response))
+	}
+	if n := testutil.ToFloat64(metrics.SystemIInFlight.WithLabelValues("127.0.0.1")); n != 0 {
+		t.Errorf("in flight after the call = %v", n)
+	}
+	if n := testutil.CollectAndCount(metrics.SystemIRequestDuration, "systemi_request_duration_seconds"); n == 0 {
+		t.Error("round trip not observed")
+	}
+}
+
+func TestSendAndReceiveErrorClasses(t *testing.T) {
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	refused := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		address string
+		class   string
+	}{
+		{"connect refused", refused, "ER040"},
+		{"no response", fakeSystemI(t, ""), "ER050"},
+	}
+	for _, tt := range tests {
+		route := "POST:/Api/Test/" + tt.class
+		_, port, _ := net.SplitHostPort(tt.address)
+		client := NewBasicTCPSocketClient(time.Second, 50*time.Millisecond, zap.NewNop().Sugar())
+		_, err := client.SendAndReceive(WithRoute(context.Background(), route), tt.address, payload())
+		if err == nil || !strings.HasPrefix(err.Error(), tt.class) {
+			t.Errorf("%s: err = %v, want %s", tt.name, err, tt.class)
+		}
+		if n := testutil.ToFloat64(metrics.SystemITransportErrorsTotal.WithLabelValues(route, port, tt.class)); n != 1 {
+			t.Errorf("%s: %s errors = %v, want 1", tt.name, tt.class, n)
+		}
+	}
+}
+
+func TestWaitIdle(t *testing.T) {
+	address := fakeSystemI(t, "")
+	client := NewBasicTCPSocketClient(time.Second, 300*time.Millisecond, zap.NewNop().Sugar())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		client.SendAndReceive(context.Background(), address, payload())
+	}()
+	for InFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := WaitIdle(short); err != context.DeadlineExceeded {
+		t.Fatalf("WaitIdle during an exchange = %v, want deadline exceeded", err)
+	}
+	long, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := WaitIdle(long); err != nil {
+		t.Fatalf("WaitIdle = %v, want the exchange to finish", err)
+	}
+	<-finished
+	if n := InFlight(); n != 0 {
+		t.Errorf("InFlight() = %d after the exchange, want 0", n)
+	}
+}
+
+func TestSendAndReceiveLogsExchanges(t *testing.T) {
+	route := "POST:/Api/Test/Logging"
+	request := utils.BuildFixedLengthHeader("MOB_APP", "INQ_CARD", "001", "RQ-LOG", "00020") + "4111111111111111    "
+	response := request[:67] + utils.PadOrTruncate("", 56) + "\r\n"
+	ctx := WithRoute(context.Background(), route)
+
+	for _, logPayloads := range []bool{false, true} {
+		core, logs := observer.New(zapcore.DebugLevel)
+		client := NewBasicTCPSocketClient(time.Second, time.Second, zap.New(core).Sugar())
+		client.LogPayloads = logPayloads
+		if _, err := client.SendAndReceive(ctx, fakeSystemI(t, response), request); err != nil {
+			t.Fatal(err)
+		}
+
+		entries := logs.FilterMessage("System I exchange").All()
+		if len(entries) != 1 || entries[0].Level != zapcore.DebugLevel {
+			t.Fatalf("logPayloads %v: logged %v", logPayloads, logs.All())
+		}
+		fields := entries[0].ContextMap()
+		if fields["requestId"] != "RQ-LOG" || fields["route"] != route || fields["result"] != "OK" || fields["sentBytes"] != int64(len(request)) || fields["receivedBytes"] != int64(len(response)) {
+			t.Errorf("logPayloads %v: fields = %v", logPayloads, fields)
+		}
+		payload, logged := fields["request"].(string)
+		if logged != logPayloads {
+			t.Errorf("logPayloads %v: request logged = %v", logPayloads, logged)
+		}
+		// the test route has no masking layout, so only the header is left
+		if logged && (strings.Contains(payload, "4111111") || !strings.HasPrefix(payload, request[:123])) {
+			t.Errorf("request not masked: %q", payload)
+		}
+	}
+
+	// Failures are warnings, so they show without debug logging.
+	core, logs := observer.New(zapcore.InfoLevel)
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed.Close()
+	NewBasicTCPSocketClient(time.Second, time.Second, zap.New(core).Sugar()).SendAndReceive(ctx, closed.Addr().String(), request)
+	if entries := logs.All(); len(entries) != 1 || entries[0].Level != zapcore.WarnLevel || entries[0].ContextMap()["result"] != "ER040" {
+		t.Errorf("failed exchange logged %v", entries)
+	}
+}
+
+func TestFailedExchangesAreKeptMasked(t *testing.T) {
+	ring := failures.NewRing(10)
+	failures.SetDefault(ring)
+	defer failures.SetDefault(failures.NewRing(0))
+
+	route := "POST:/Api/Test/Failures"
+	request := utils.BuildFixedLengthHeader("MOB_APP", "INQ_CARD", "001", "RQ-FAIL", "00020") + "4111111111111111    "
+	answer := func(code string) string {
+		return request[:67] + utils.PadOrTruncate(code, 6) + utils.PadOrTruncate("", 50) + "\r\n"
+	}
+	client := NewBasicTCPSocketClient(time.Second, time.Second, zap.NewNop().Sugar())
+	ctx := WithRoute(context.Background(), route)
+	client.SendAndReceive(ctx, fakeSystemI(t, answer("")), request)
+	client.SendAndReceive(ctx, fakeSystemI(t, answer("SVC117")), request)
+
+	list, total := ring.List()
+	if total != 1 || len(list) != 1 {
+		t.Fatalf("%d failures kept of %d, want the SVC117 answer only", len(list), total)
+	}
+	got := list[0]
+	if got.RequestID != "RQ-FAIL" || got.Route != route || got.Result != "SVC117" {
+		t.Errorf("failure = %+v", got)
+	}
+	if strings.Contains(got.Request, "4111111") || !strings.HasPrefix(got.Request, request[:123]) {
+		t.Errorf("request not masked: %q", got.Request)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	refused := closed.Addr().String()
+	closed.Close()
+	ctx := context.Background()
+
+	if err := ConnectProbe()(ctx, fakeSystemI(t, "")); err != nil {
+		t.Errorf("connect probe of a listening port: %v", err)
+	}
+	if err := ConnectProbe()(ctx, refused); err == nil {
+		t.Error("connect probe of a closed port succeeded")
+	}
+
+	answer := func(code string) string {
+		return payload()[:67] + utils.PadOrTruncate(code, 6) + utils.PadOrTruncate("", 50) + "\r\n"
+	}
+	ping := config.EchoProbe{Enabled: true, System: "MONITOR", Service: "PING", Format: "001"}
+	tcpClient := NewBasicTCPSocketClient(time.Second, time.Second, zap.NewNop().Sugar())
+	if err := EchoProbe(tcpClient, ping)(ctx, fakeSystemI(t, answer(""))); err != nil {
+		t.Errorf("echo probe answered blank: %v", err)
+	}
+	if err := EchoProbe(tcpClient, ping)(ctx, fakeSystemI(t, answer("SVC902"))); err == nil || !strings.Contains(err.Error(), "SVC902") {
+		t.Errorf("echo probe answered SVC902: err = %v", err)
+	}
+	ping.OKCodes = []string{"SVC902"}
+	if err := EchoProbe(tcpClient, ping)(ctx, fakeSystemI(t, answer("SVC902"))); err != nil {
+		t.Errorf("echo probe with SVC902 allowed: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/mask"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// logExchange writes one event per exchange: at debug level, or as a warning when
+// the call failed in transport. The messages are only added, masked, with LogPayloads.
+func (c *BasicTCPSocketClient) logExchange(m *exchangeMetrics, address, payload, response, result string, err error) {
+	if c.logger == nil || (err == nil && !c.logger.Desugar().Core().Enabled(zapcore.DebugLevel)) {
+		return
+	}
+	fields := []interface{}{
+		"requestId", headerRequestID(payload),
+		"route", m.route,
+		"address", address,
+		"result", result,
+		"sentBytes", m.sentBytes,
+		"receivedBytes", m.receivedBytes,
+		"dialMs", milliseconds(m.dial),
+		"durationMs", milliseconds(time.Since(m.start)),
+	}
+	if c.LogPayloads {
+		fields = append(fields,
+			"request", mask.Payload(m.route, mask.Request, payload),
+			"response", mask.Payload(m.route, mask.Response, response),
+		)
+	}
+	if err != nil {
+		c.logger.Warnw("System I exchange failed", append(fields, "error", err.Error())...)
+		return
+	}
+	c.logger.Debugw("System I exchange", fields...)
+}
+
+// headerRequestID is the request ID in the fixed-length header of payload.
+func headerRequestID(payload string) string {
+	if len(payload) < 48 {
+		return ""
+	}
+	return strings.TrimSpace(payload[28:48])
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"connectorapi-go/pkg/metrics"
+)
+
+// exchangeMetrics records one System I exchange in the systemi_* metrics, and
+// keeps the figures for its log event.
+type exchangeMetrics struct {
+	route string
+	host  string
+	port  string
+	start time.Time
+
+	dial          time.Duration
+	sentBytes     int
+	receivedBytes int
+}
+
+// inFlight counts the exchanges in progress on every destination, for WaitIdle.
+var inFlight atomic.Int64
+
+// InFlight returns the number of System I exchanges in progress.
+func InFlight() int64 {
+	return inFlight.Load()
+}
+
+// WaitIdle waits until no System I exchange is in progress, e.g. on shutdown once
+// the server stopped accepting requests. It returns ctx.Err() if ctx ends first.
+func WaitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for inFlight.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func startExchange(route string, address string) *exchangeMetrics {
+	host, port, _ := net.SplitHostPort(address)
+	inFlight.Add(1)
+	metrics.SystemIInFlight.WithLabelValues(host).Inc()
+	return &exchangeMetrics{route: route, host: host, port: port, start: time.Now()}
+}
+
+func (m *exchangeMetrics) dialed(d time.Duration) {
+	m.dial = d
+	metrics.SystemIDialDuration.WithLabelValues(m.host, m.port).Observe(d.Seconds())
+}
+
+func (m *exchangeMetrics) sent(n int) {
+	m.sentBytes = n
+	metrics.SystemISentBytesTotal.WithLabelValues(m.route, m.port).Add(float64(n))
+}
+
+func (m *exchangeMetrics) received(n int) {
+	m.receivedBytes = n
+	metrics.SystemIReceivedBytesTotal.WithLabelValues(m.route, m.port).Add(float64(n))
+}
+
+// done counts the result: the ER0xx class of a failed call, otherwise the
+// response code of System I (see utils.SystemIResultCode).
+func (m *exchangeMetrics) done(result string, err error) {
+	inFlight.Add(-1)
+	metrics.SystemIInFlight.WithLabelValues(m.host).Dec()
+	metrics.SystemIRequestDuration.WithLabelValues(m.route, m.port).Observe(time.Since(m.start).Seconds())
+	if err != nil {
+		metrics.SystemITransportErrorsTotal.WithLabelValues(m.route, m.port, result).Inc()
+		return
+	}
+	metrics.SystemIResponsesTotal.WithLabelValues(m.route, result).Inc()
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const adminKey = "Admin-Key"
+const adminActor = "Admin-Actor"
+
+// defaultRotationOverlap keeps the previous keys of a client valid after a rotation
+// when the request does not say otherwise.
+const defaultRotationOverlap = 7 * 24 * time.Hour
+
+// adminHandler manages API clients through the /Admin group
+type adminHandler struct {
+	apikey    *utils.APIKeyRepository
+	adminKeys []adminCredential
+	logLevel  zap.AtomicLevel
+	logger    *zap.SugaredLogger
+}
+
+type adminCredential struct {
+	name       string
+	credential apikey.Credential
+}
+
+// --- Admin request / response ---
+type createClientRequest struct {
+	ClientName   string              `json:"clientName"`
+	Roles        []string            `json:"roles"`
+	Permissions  []config.Permission `json:"permissions"`
+	AllowedCIDRs []string            `json:"allowedCIDRs"`
+	Contact      string              `json:"contact"`
+}
+
+type permissionsRequest struct {
+	Roles       []string            `json:"roles"`
+	Permissions []config.Permission `json:"permissions"`
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"` // debug, info, warn or error
+}
+
+type logLevelResponse struct {
+	Level string `json:"level"`
+}
+
+type rotateRequest struct {
+	Overlap string `json:"overlap"` // e.g. "72h", defaultRotationOverlap when empty
+}
+
+type clientView struct {
+	ID           string              `json:"id"`
+	ClientName   string              `json:"clientName"`
+	Status       string              `json:"status"`
+	Roles        []string            `json:"roles,omitempty"`
+	Permissions  []config.Permission `json:"permissions,omitempty"`
+	AllowedCIDRs []string            `json:"allowedCIDRs,omitempty"`
+	Contact      string              `json:"contact,omitempty"`
+	Keys         []keyView           `json:"keys"`
+	// FieldEncryption shows the method and key ID only, never the key.
+	FieldEncryption *fieldEncryptionView `json:"fieldEncryption,omitempty"`
+}
+
+type fieldEncryptionView struct {
+	Method string `json:"method"`
+	KeyID  string `json:"keyId,omitempty"`
+}
+
+type keyView struct {
+	KeyID     string     `json:"keyId"`
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type issuedKeyResponse struct {
+	APIKey string     `json:"apiKey"` // shown once, only its hash is stored
+	Client clientView `json:"client"`
+}
+
+// NewAdminHandler creates a new instance of adminHandler.
+// Admin keys that cannot be parsed are skipped and logged. logLevel is the level
+// of logger, changed through /Admin/LogLevel.
+func NewAdminHandler(repo *utils.APIKeyRepository, keys []config.AdminKey, logLevel zap.AtomicLevel, logger *zap.SugaredLogger) *adminHandler {
+	h := &adminHandler{apikey: repo, logLevel: logLevel, logger: logger}
+	for _, k := range keys {
+		credential, err := apikey.Parse(k.Hash)
+		if err != nil || k.Name == "" {
+			logger.Warnw("Ignoring admin key", "name", k.Name, "error", err)
+			continue
+		}
+		h.adminKeys = append(h.adminKeys, adminCredential{name: k.Name, credential: credential})
+	}
+	if len(h.adminKeys) == 0 {
+		logger.Warn("No admin keys configured, the admin API rejects every request")
+	}
+	return h
+}
+
+// RegisterRoutes registers the admin routes to the router group
+func (h *adminHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.Use(h.authenticate)
+	clientRoutes := rg.Group("/Clients")
+	{
+		clientRoutes.GET("", h.ListClients)
+		clientRoutes.POST("", h.CreateClient)
+		clientRoutes.PUT("/:id/Permissions", h.SetPermissions)
+		clientRoutes.PUT("/:id/FieldEncryption", h.SetFieldEncryption)
+		clientRoutes.POST("/:id/Disable", h.DisableClient)
+		clientRoutes.POST("/:id/Enable", h.EnableClient)
+		clientRoutes.POST("/:id/Rotate", h.RotateKey)
+	}
+	rg.GET("/Roles", h.ListRoles)
+	rg.GET("/LogLevel", h.GetLogLevel)
+	rg.PUT("/LogLevel", h.SetLogLevel)
+}
+
+// authenticate accepts the request when its Admin-Key matches a configured admin key.
+func (h *adminHandler) authenticate(c *gin.Context) {
+	key := c.GetHeader(adminKey)
+	if key != "" {
+		for _, admin := range h.adminKeys {
+			if admin.credential.KeyID == apikey.KeyID(key) && admin.credential.Matches(key) {
+				c.Set(adminActor, admin.name)
+				c.Next()
+				return
+			}
+		}
+	}
+	h.logger.Warnw("Admin authorization failed", "path", c.FullPath(), "apiKeyID", apikey.MaskKey(key), "clientIP", c.ClientIP())
+	handleErrorResponse(c, appError.ErrUnauthorized)
+	c.Abort()
+}
+
+// ListClients returns every client with masked key IDs, never key hashes.
+func (h *adminHandler) ListClients(c *gin.Context) {
+	snapshot := h.apikey.Snapshot()
+	views := make([]clientView, 0, len(snapshot.Clients))
+	for _, client := range snapshot.Clients {
+		views = append(views, newClientView(client))
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// ListRoles returns the roles clients can be assigned to.
+func (h *adminHandler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, h.apikey.Snapshot().Roles)
+}
+
+// GetLogLevel returns the level of the application log.
+func (h *adminHandler) GetLogLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, logLevelResponse{Level: h.logLevel.Level().String()})
+}
+
+// SetLogLevel changes the level of the application log until the next restart,
+// e.g. to debug to see every System I exchange.
+func (h *adminHandler) SetLogLevel(c *gin.Context) {
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleErrorResponse(c, appError.ErrInvLogLevel)
+		return
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil || level > zapcore.ErrorLevel {
+		handleErrorResponse(c, appError.ErrInvLogLevel)
+		return
+	}
+	previous := h.logLevel.Level()
+	h.logLevel.SetLevel(level)
+	h.logger.Warnw("Log level changed", "actor", c.GetString(adminActor), "from", previous.String(), "to", level.String())
+	c.JSON(http.StatusOK, logLevelResponse{Level: level.String()})
+}
+
+// CreateClient adds a client and returns its first key.
+func (h *adminHandler) CreateClient(c *gin.Context) {
+	var req createClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleErrorResponse(c, invalidClientError(err))
+		return
+	}
+
+	key, client, err := h.apikey.CreateClient(c.GetString(adminActor), config.APIKey{
+		ClientName:   req.ClientName,
+		Roles:        req.Roles,
+		Permissions:  req.Permissions,
+		AllowedCIDRs: req.AllowedCIDRs,
+		Contact:      req.Contact,
+	})
+	if err != nil {
+		h.handleRepositoryError(c, err)
+		return
+	}
+	h.logger.Infow("API client created", "actor", c.GetString(adminActor), "clientId", client.ID, "apiKeyID", apikey.MaskKey(key))
+	c.JSON(http.StatusCreated, issuedKeyResponse{APIKey: key, Client: newClientView(client)})
+}
+
+// SetPermissions replaces the roles and permissions of a client.
+func (h *adminHandler) SetPermissions(c *gin.Context) {
+	var req permissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleErrorResponse(c, invalidClientError(err))
+		return
+	}
+
+	client, err := h.apikey.SetPermissions(c.GetString(adminActor), c.Param("id"), req.Roles, req.Permissions)
+	if err != nil {
+		h.handleRepositoryError(c, err)
+		return
+	}
+	h.logger.Infow("API client permissions changed", "actor", c.GetString(adminActor), "clientId", client.ID)
+	c.JSON(http.StatusOK, newClientView(client))
+}
+
+// SetFieldEncryption sets the key sensitive response fields are encrypted with for a client.
+// An empty method or "none" returns them in clear text again.
+func (h *adminHandler) SetFieldEncryption(c *gin.Context) {
+	var req config.FieldEncryption
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleErrorResponse(c, invalidClientError(err))
+		return
+	}
+	fe := &req
+	if req.Method == "" || req.Method == "none" {
+		fe = nil
+	}
+
+	client, err := h.apikey.SetFieldEncryption(c.GetString(adminActor), c.Param("id"), fe)
+	if err != nil {
+		h.handleRepositoryError(c, err)
+		return
+	}
+	h.logger.Infow("API client field encryption changed", "actor", c.GetString(adminActor), "clientId", client.ID, "method", req.Method)
+	c.JSON(http.StatusOK, newClientView(client))
+}
+
+// DisableClient rejects every key of a client from now on.
+func (h *adminHandler) DisableClient(c *gin.Context) {
+	h.setStatus(c, "inactive")
+}
+
+// EnableClient reactivates a disabled client.
+func (h *adminHandler) EnableClient(c *gin.Context) {
+	h.setStatus(c, "active")
+}
+
+func (h *adminHandler) setStatus(c *gin.Context, status string) {
+	client, err := h.apikey.SetStatus(c.GetString(adminActor), c.Param("id"), status)
+	if err != nil {
+		h.handleRepositoryError(c, err)
+		return
+	}
+	h.logger.Infow("API client status changed", "actor", c.GetString(adminActor), "clientId", client.ID, "status", status)
+	c.JSON(http.StatusOK, newClientView(client))
+}
+
+// RotateKey issues a new key; the previous keys stay valid for the overlap.
+func (h *adminHandler) RotateKey(c *gin.Context) {
+	var req rotateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleErrorResponse(c, invalidClientError(err))
+			return
+		}
+	}
+	overlap := defaultRotationOverlap
+	if req.Overlap != "" {
+		d, err := time.ParseDuration(req.Overlap)
+		if err != nil || d < 0 {
+			handleErrorResponse(c, invalidClientError(errors.New("overlap must be a positive duration such as 72h")))
+			return
+		}
+		overlap = d
+	}
+
+	key, client, err := h.apikey.RotateKey(c.GetString(adminActor), c.Param("id"), overlap)
+	if err != nil {
+		h.handleRepositoryError(c, err)
+		return
+	}
+	h.logger.Infow("API client key rotated", "actor", c.GetString(adminActor), "clientId", client.ID, "apiKeyID", apikey.MaskKey(key), "overlap", overlap.String())
+	c.JSON(http.StatusOK, issuedKeyResponse{APIKey: key, Client: newClientView(client)})
+}
+
+func (h *adminHandler) handleRepositoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, utils.ErrClientNotFound):
+		handleErrorResponse(c, appError.ErrClientNotFound)
+	case errors.Is(err, utils.ErrInvalidClient):
+		handleErrorResponse(c, invalidClientError(err))
+	default:
+		h.logger.Errorw("Failed to save API clients", "actor", c.GetString(adminActor), "error", err)
+		handleErrorResponse(c, appError.ErrInternalServer)
+	}
+}
+
+func invalidClientError(err error) *appError.AppError {
+	return &appError.AppError{
+		ErrorCode:    appError.ErrInvalidClient.ErrorCode,
+		ErrorMessage: appError.ErrInvalidClient.ErrorMessage + " (" + err.Error() + ")",
+		Err:          err,
+	}
+}
+
+func newClientView(client config.APIKey) clientView {
+	view := clientView{
+		ID:           client.ID,
+		ClientName:   client.ClientName,
+		Status:       client.Status,
+		Roles:        client.Roles,
+		Permissions:  client.Permissions,
+		AllowedCIDRs: client.AllowedCIDRs,
+		Contact:      client.Contact,
+		Keys:         []keyView{},
+	}
+	if fe := client.FieldEncryption; fe != nil {
+		view.FieldEncryption = &fieldEncryptionView{Method: fe.Method, KeyID: fe.KeyID}
+	}
+	for _, k := range client.Keys {
+		keyID := "invalid"
+		if credential, err := apikey.Parse(k.Hash); err == nil {
+			keyID = apikey.MaskKeyID(credential.KeyID)
+		}
+		view.Keys = append(view.Keys, keyView{KeyID: keyID, NotBefore: k.NotBefore, ExpiresAt: k.ExpiresAt})
+	}
+	return view
+}
@@ -80,7 +80,7 @@ func (h *applicationLowerHandler) SubmitLoanApplication(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
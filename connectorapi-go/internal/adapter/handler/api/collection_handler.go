@@ -82,7 +82,7 @@ func (h *collectionHandler) CollectionDetail(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -146,7 +146,7 @@ func (h *collectionHandler) CollectionLog(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -88,7 +88,7 @@ func (h *commonHandler) GetCustomerInfo(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -151,7 +151,7 @@ func (h *commonHandler) CheckApplyCondition(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -214,7 +214,7 @@ func (h *commonHandler) CheckApplyCondition2ndCard(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
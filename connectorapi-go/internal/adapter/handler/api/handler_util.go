@@ -1,15 +1,21 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 
 	appError "connectorapi-go/pkg/error"
 	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"go.uber.org/zap"
 )
@@ -29,10 +35,18 @@ func handleErrorResponse(c *gin.Context, appErr *appError.AppError) {
 		statusCode = http.StatusBadRequest
 	case appError.ErrInternalServer.ErrorCode:
 		statusCode = http.StatusInternalServerError
-	case appError.ErrUnauthorized.ErrorCode:
+	case appError.ErrUnauthorized.ErrorCode, appError.ErrKeyExpired.ErrorCode, appError.ErrKeyNotYetValid.ErrorCode:
 		statusCode = http.StatusUnauthorized
+	case appError.ErrIPNotAllowed.ErrorCode, appError.ErrValueNotAllowed.ErrorCode:
+		statusCode = http.StatusForbidden
 	case appError.ErrTimeOut.ErrorCode:
 		statusCode = http.StatusGatewayTimeout
+	case appError.ErrClientNotFound.ErrorCode:
+		statusCode = http.StatusNotFound
+	case appError.ErrTooManyRequests.ErrorCode, appError.ErrQuotaExceeded.ErrorCode:
+		statusCode = http.StatusTooManyRequests
+	case appError.ErrIdempotencyConflict.ErrorCode, appError.ErrIdempotencyInProgress.ErrorCode, appError.ErrDuplicateRequestID.ErrorCode:
+		statusCode = http.StatusConflict
 	default:
 		statusCode = http.StatusBadRequest
 	}
@@ -63,13 +77,45 @@ func getAPIHeaders(c *gin.Context) apiHeaders {
 // 	}
 // }
 
-func ValidateHeaders(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) *appError.AppError {
+// requestBody returns the raw body kept by RequestBodyMiddleware.
+func requestBody(c *gin.Context) []byte {
+	body, _ := c.Get(requestBodyKey)
+	data, _ := body.([]byte)
+	return data
+}
+
+// ValidateApiKey checks the API key of the request and maps lifecycle failures to their error codes.
+func ValidateApiKey(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) *appError.AppError {
 	headers := getAPIHeaders(c)
 
-	if !apiKeyRepo.Validate(headers.APIKey, method, path) {
-		logger.Warnw("Authorization failed", "path", path, "apiKey", headers.APIKey)
+	err := apiKeyRepo.Validate(headers.APIKey, method, path, c.ClientIP(), requestBody(c))
+	if err == nil {
+		return nil
+	}
+	logger.Warnw("Authorization failed", "path", path, "apiKeyID", apikey.MaskKey(headers.APIKey), "clientIP", c.ClientIP(), "reason", err.Error())
+
+	switch {
+	case errors.Is(err, utils.ErrKeyExpired):
+		return appError.ErrKeyExpired
+	case errors.Is(err, utils.ErrKeyNotYetValid):
+		return appError.ErrKeyNotYetValid
+	case errors.Is(err, utils.ErrIPNotAllowed):
+		return appError.ErrIPNotAllowed
+	case errors.Is(err, utils.ErrValueNotAllowed):
+		return appError.ErrValueNotAllowed
+	default:
 		return appError.ErrUnauthorized
 	}
+}
+
+func ValidateHeaders(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) (appErr *appError.AppError) {
+	_, span := tracing.Start(c.Request.Context(), "validate headers")
+	defer func() { endValidationSpan(span, appErr) }()
+	headers := getAPIHeaders(c)
+
+	if appErr := ValidateApiKey(c, method, path, apiKeyRepo, logger); appErr != nil {
+		return appErr
+	}
 
 	if headers.RequestID == "" || len(headers.RequestID) > 20 {
 		return appError.ErrApiRequestID
@@ -84,12 +130,18 @@ func ValidateHeaders(c *gin.Context, method string, path string, apiKeyRepo *uti
 	return nil
 }
 
-func ValidateHeadersForApiKeyAndApiRequestID(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) *appError.AppError {
+// keyAndRequestIDRoutes are the routes, METHOD:path, whose handler checks only the key
+// and the request ID of the headers, learned from ValidateHeadersForApiKeyAndApiRequestID.
+var keyAndRequestIDRoutes sync.Map
+
+func ValidateHeadersForApiKeyAndApiRequestID(c *gin.Context, method string, path string, apiKeyRepo *utils.APIKeyRepository, logger *zap.SugaredLogger) (appErr *appError.AppError) {
+	keyAndRequestIDRoutes.Store(method+":"+path, true)
+	_, span := tracing.Start(c.Request.Context(), "validate headers")
+	defer func() { endValidationSpan(span, appErr) }()
 	headers := getAPIHeaders(c)
 
-	if !apiKeyRepo.Validate(headers.APIKey, method, path) {
-		logger.Warnw("Authorization failed", "path", path, "apiKey", headers.APIKey)
-		return appError.ErrUnauthorized
+	if appErr := ValidateApiKey(c, method, path, apiKeyRepo, logger); appErr != nil {
+		return appErr
 	}
 
 	if headers.RequestID == "" || len(headers.RequestID) > 20 {
@@ -98,6 +150,24 @@ func ValidateHeadersForApiKeyAndApiRequestID(c *gin.Context, method string, path
 	return nil
 }
 
+// validateBody checks the struct tags of req inside a validation span.
+func validateBody(c *gin.Context, v *validator.Validate, req interface{}) error {
+	_, span := tracing.Start(c.Request.Context(), "validate body")
+	err := v.Struct(req)
+	tracing.End(span, err)
+	return err
+}
+
+// endValidationSpan ends a validation span, recording the error code of a rejection.
+func endValidationSpan(span trace.Span, appErr *appError.AppError) {
+	if appErr != nil {
+		span.SetAttributes(attribute.String("connector.error_code", appErr.ErrorCode))
+		tracing.End(span, errors.New(appErr.ErrorMessage))
+		return
+	}
+	span.End()
+}
+
 func formatValidationErrors(err error) []appError.ValidationErrorDetail {
 	var validationErrors []appError.ValidationErrorDetail
 
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/failures"
+	"connectorapi-go/pkg/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// introspectionHandler shows what the running gateway loaded and what failed
+// recently. It is served on the admin port only, see SetupAdminRouter.
+type introspectionHandler struct {
+	destinationsAndRoutes *config.DestinationsAndRoutes
+	readiness             *health.Checker
+	failures              *failures.Ring
+	build                 BuildInfo
+}
+
+// BuildInfo identifies the running binary.
+type BuildInfo struct {
+	Version      string    `json:"version"` // set with -ldflags "-X main.version=..."
+	GoVersion    string    `json:"goVersion"`
+	Revision     string    `json:"revision,omitempty"`
+	RevisionTime string    `json:"revisionTime,omitempty"`
+	Modified     bool      `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	StartedAt    time.Time `json:"startedAt"`
+	Uptime       string    `json:"uptime"`
+}
+
+// NewBuildInfo returns the build info of the running binary, with the VCS
+// revision the Go toolchain stamped into it, if any.
+func NewBuildInfo(version string) BuildInfo {
+	info := BuildInfo{Version: version, GoVersion: runtime.Version(), StartedAt: time.Now()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.RevisionTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+type failuresResponse struct {
+	Total    uint64              `json:"total"` // failures since start, including the ones no longer kept
+	Failures []failures.Exchange `json:"failures"`
+}
+
+// NewIntrospectionHandler creates a new instance of introspectionHandler.
+func NewIntrospectionHandler(dr *config.DestinationsAndRoutes, readiness *health.Checker, ring *failures.Ring, build BuildInfo) *introspectionHandler {
+	return &introspectionHandler{destinationsAndRoutes: dr, readiness: readiness, failures: ring, build: build}
+}
+
+// RegisterRoutes registers the introspection routes to the router group,
+// which must authenticate admin keys.
+func (h *introspectionHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/Routes", h.Routes)
+	rg.GET("/Health", h.Health)
+	rg.GET("/Build", h.Build)
+	rg.GET("/Failures", h.Failures)
+}
+
+// Routes returns the effective destinations and routes, secrets redacted.
+func (h *introspectionHandler) Routes(c *gin.Context) {
+	c.JSON(http.StatusOK, config.Effective(h.destinationsAndRoutes))
+}
+
+// Health returns the last probe of every System I port and the state of the
+// other components, whether the API is ready or not.
+func (h *introspectionHandler) Health(c *gin.Context) {
+	if h.readiness == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ready", "ready": true})
+		return
+	}
+	c.JSON(http.StatusOK, h.readiness.Report())
+}
+
+// Build returns the version and VCS revision of the running binary.
+func (h *introspectionHandler) Build(c *gin.Context) {
+	info := h.build
+	info.Uptime = time.Since(info.StartedAt).Round(time.Second).String()
+	c.JSON(http.StatusOK, info)
+}
+
+// Failures returns the last failed System I exchanges, newest first, at most
+// ?limit= of them.
+func (h *introspectionHandler) Failures(c *gin.Context) {
+	list, total := h.failures.List()
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit >= 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	c.JSON(http.StatusOK, failuresResponse{Total: total, Failures: list})
+}
@@ -73,17 +73,15 @@ func (h *mobileHandler) DashboardSummary(c *gin.Context) {
 		return
 	}
 
-	apiKey := c.GetHeader("Api-Key")
-	if !h.apikey.Validate(apiKey, c.Request.Method, c.FullPath()) {
-		h.logger.Errorw("Authorization failed", "path", c.FullPath(), "apiKey", apiKey)
-		handleErrorResponse(c, appError.ErrUnauthorized)
-		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appError.ErrUnauthorized, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if appErr := ValidateApiKey(c, c.Request.Method, c.FullPath(), h.apikey, h.logger); appErr != nil {
+		handleErrorResponse(c, appErr)
+		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appErr, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
 			return
 		}
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -163,17 +161,15 @@ func (h *mobileHandler) DashboardDetail(c *gin.Context) {
 		return
 	}
 
-	apiKey := c.GetHeader("Api-Key")
-	if !h.apikey.Validate(apiKey, c.Request.Method, c.FullPath()) {
-		h.logger.Errorw("Authorization failed", "path", c.FullPath(), "apiKey", apiKey)
-		handleErrorResponse(c, appError.ErrUnauthorized)
-		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appError.ErrUnauthorized, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if appErr := ValidateApiKey(c, c.Request.Method, c.FullPath(), h.apikey, h.logger); appErr != nil {
+		handleErrorResponse(c, appErr)
+		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appErr, serviceName, "", "", nil, h.logger, h.config.ELKPath, handleErrorResponse) {
 			return
 		}
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -262,7 +258,7 @@ func (h *mobileHandler) MobileFullPan(c *gin.Context) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
+	if err := validateBody(c, h.validator, req); err != nil {
     	appErr := HandleValidationError(err)
 		handleErrorResponse(c, appErr)
 		if appErr.ErrorCode == "SYS500" {
@@ -274,6 +270,20 @@ func (h *mobileHandler) MobileFullPan(c *gin.Context) {
     	return
 	}
 
+	// Clients with field encryption only ever receive, and we only ever log, the ciphertext.
+	encrypter, err := h.apikey.FieldEncrypter(getAPIHeaders(c).APIKey)
+	if err != nil {
+		h.logger.Errorw("Field encryption not available", "path", c.FullPath(), "error", err)
+		handleErrorResponse(c, appError.ErrInternalServer)
+		if !elkLog.FinalELKLog(c, &logList, timeNow, &req, "", appError.ErrInternalServer, serviceName, "", req.IDCardNo, nil, h.logger, h.config.ELKPath, handleErrorResponse) {
+			return
+		}
+		return
+	}
+	if encrypter != nil {
+		c.Set(utils.FieldEncrypterKey, encrypter)
+	}
+
 	mobileFullPanResult := h.service.MobileFullPan(c, req)
 	if mobileFullPanResult.AppError != nil {
 		handleErrorResponse(c, mobileFullPanResult.AppError)
@@ -284,7 +294,7 @@ func (h *mobileHandler) MobileFullPan(c *gin.Context) {
 	if mobileFullPanResult.DomainError != nil {
 		responseError = mobileFullPanResult.DomainError
 	}
-	if !elkLog.FinalELKLog(mobileFullPanResult.GinCtx, &logList, mobileFullPanResult.Timestamp, req, mobileFullPanResult.Response, mobileFullPanResult.DomainError, mobileFullPanResult.ServiceName, "", mobileFullPanResult.UserRef, []string{mobileFullPanResult.LogLine1}, h.logger, h.config.ELKPath, handleErrorResponse) {
+	if !elkLog.FinalELKLog(mobileFullPanResult.GinCtx, &logList, mobileFullPanResult.Timestamp, req, mobileFullPanResult.Response, mobileFullPanResult.DomainError, mobileFullPanResult.ServiceName, "", mobileFullPanResult.UserRef, mobileFullPanResult.LogLines, h.logger, h.config.ELKPath, handleErrorResponse) {
 		return
 	}
 	if responseError != nil {
@@ -1,14 +1,36 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"connectorapi-go/internal/adapter/client"
+	elkLog "connectorapi-go/internal/adapter/client/elk"
 	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/health"
+	"connectorapi-go/pkg/idempotency"
+	"connectorapi-go/pkg/journal"
 	"connectorapi-go/pkg/logger"
+	"connectorapi-go/pkg/mask"
 	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/ratelimit"
+	"connectorapi-go/pkg/replay"
+	"connectorapi-go/pkg/reqid"
+	"connectorapi-go/pkg/respcache"
+	"connectorapi-go/pkg/tracing"
 	_ "connectorapi-go/docs"
 
 	"github.com/gin-gonic/gin"
@@ -16,32 +38,49 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"golang.org/x/sync/singleflight"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 const apiRequestID = "Api-RequestID"
 const apiKey       = "Api-Key"
+const apiKeyID     = "Api-KeyID"
 const apiLanguage  = "Api-Language"
 const apiDeviceOS  = "Api-DeviceOS"
 const apiChannel   = "Api-Channel"
+const requestBodyKey = "Request-Body"
+
+// RouteRegistrar adds the routes of a handler to the /Api group.
+type RouteRegistrar interface {
+	RegisterRoutes(rg *gin.RouterGroup)
+}
+
+// RouterDeps is what the public router is built from. A nil Limiter, Idempotency,
+// Replay, Audit or Cache, or empty CoalesceRoutes, leaves that middleware out.
+type RouterDeps struct {
+	Logger            *zap.SugaredLogger
+	APIKeys           *utils.APIKeyRepository
+	Limiter           *ratelimit.Limiter
+	Idempotency       *idempotency.Manager
+	IdempotencyConfig config.IdempotencyConfig
+	Replay            *replay.Guard
+	Audit             *journal.Journal
+	AuditRoutes       []string
+	Readiness         *health.Checker
+	Cache             *respcache.Cache
+	CacheConfig       config.CacheConfig
+	CoalesceRoutes    []string
+	ELKPath           string
+	Handlers          []RouteRegistrar // registered under /Api
+}
 
 // SetupRouter
-func SetupRouter(
-	appLogger *zap.SugaredLogger,
-	repo *utils.APIKeyRepository,
-	collectionHandler *collectionHandler,
-	agreementHandler *agreementHandler,
-	creditCardHandler *creditCardHandler,
-	commonHandler *commonHandler,
-	selfServiceHandler *selfServiceHandler,
-	registerHandler *registerHandler,
-	customerLowerHandler *customerLowerHandler,
-	consentHandler *consentHandler,
-	uhpHandler *uhpHandler,
-	mobileHandler *mobileHandler,
-	applicationCapHandler *applicationCapHandler,
-	applicationLowerHandler *applicationLowerHandler,
-) *gin.Engine {
+func SetupRouter(deps RouterDeps) *gin.Engine {
+	appLogger, repo := deps.Logger, deps.APIKeys
 	router := gin.New()
 
 	// --- Global Middlewares ---
@@ -50,53 +89,96 @@ func SetupRouter(
 	router.Use(ApiLanguageMiddleware())
 	router.Use(ApiDeviceOSMiddleware())
 	router.Use(ApiChannelMiddleware())
+	router.Use(RequestBodyMiddleware())
 
-	router.Use(logger.GinLogger(appLogger, apiRequestID, apiLanguage, apiDeviceOS, apiChannel))
+	router.Use(logger.GinLogger(appLogger, apiRequestID, apiKeyID, apiLanguage, apiDeviceOS, apiChannel))
 	router.Use(PrometheusMiddleware())
 	router.Use(gin.Recovery())
 
 	// --- Public API Group ---
 
 	router.GET("/healthz", HealthCheck)
+	router.GET("/livez", HealthCheck)
+	router.GET("/readyz", ReadinessCheck(deps.Readiness))
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// --- API Group  ---
 	apiRoute := router.Group("/Api")
-	{
-		collectionHandler.RegisterRoutes(apiRoute)
-		agreementHandler.RegisterRoutes(apiRoute)
-		creditCardHandler.RegisterRoutes(apiRoute)
-		commonHandler.RegisterRoutes(apiRoute)
-		selfServiceHandler.RegisterRoutes(apiRoute)
-		registerHandler.RegisterRoutes(apiRoute)
-		customerLowerHandler.RegisterRoutes(apiRoute)
-		consentHandler.RegisterRoutes(apiRoute)
-		uhpHandler.RegisterRoutes(apiRoute)
-		mobileHandler.RegisterRoutes(apiRoute)
-		applicationCapHandler.RegisterRoutes(apiRoute)
-		applicationLowerHandler.RegisterRoutes(apiRoute)
+	apiRoute.Use(TracingMiddleware())
+	if deps.Limiter != nil {
+		apiRoute.Use(RateLimitMiddleware(deps.Limiter, repo, appLogger))
+	}
+	if deps.Idempotency != nil {
+		apiRoute.Use(IdempotencyMiddleware(deps.Idempotency, deps.IdempotencyConfig, repo, appLogger))
+	}
+	if deps.Replay != nil {
+		apiRoute.Use(ReplayMiddleware(deps.Replay, repo, appLogger))
+	}
+	if deps.Audit != nil {
+		apiRoute.Use(AuditMiddleware(deps.Audit, deps.AuditRoutes, repo, appLogger))
+	}
+	if deps.Cache != nil {
+		apiRoute.Use(CacheMiddleware(deps.Cache, deps.CacheConfig, repo, deps.ELKPath, appLogger))
+	}
+	if len(deps.CoalesceRoutes) > 0 {
+		apiRoute.Use(CoalesceMiddleware(deps.CoalesceRoutes, repo, deps.ELKPath, appLogger))
 	}
+	for _, handler := range deps.Handlers {
+		handler.RegisterRoutes(apiRoute)
+	}
+
+	return router
+}
+
+// SetupAdminRouter builds the router of the admin port: the /Admin API plus the
+// introspection endpoints and pprof, which the public port never serves. Every
+// route needs an admin key.
+func SetupAdminRouter(appLogger *zap.SugaredLogger, adminHandler *adminHandler, introspectionHandler *introspectionHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(ApiRequestIDMiddleware())
+	router.Use(logger.GinLogger(appLogger, apiRequestID, apiKeyID, apiLanguage, apiDeviceOS, apiChannel))
+	router.Use(gin.Recovery())
+
+	// adminHandler.RegisterRoutes adds the admin key check to the whole group,
+	// so it has to come first.
+	adminRoute := router.Group("/Admin")
+	adminHandler.RegisterRoutes(adminRoute)
+	introspectionHandler.RegisterRoutes(adminRoute)
+
+	pprofRoute := router.Group("/debug/pprof", adminHandler.authenticate)
+	pprofRoute.GET("/*profile", Pprof)
+	pprofRoute.POST("/*profile", Pprof)
 
 	return router
 }
 
+// Pprof serves net/http/pprof: the index, the named profiles and the
+// cmdline, profile, symbol and trace endpoints.
+func Pprof(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
 // --- Middlewares Definitions ---
 // RequestIDMiddleware checks for an incoming X-Request-ID, RequestID header
+// and generates a sortable ID (see pkg/reqid) when the client sent none.
 func ApiRequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reqID := utils.GetHeader(c, "X-Request-ID", "Api-RequestID", "RequestID")
 
 		if reqID == "" {
-			prefix := "RQ"
-			now := time.Now()
-			dateTime := now.Format("20060102150405")                // YYYYMMDDhhmmss
-
-			r := rand.New(rand.NewSource(time.Now().UnixNano()))    // seed random with time now
-			runningNo := r.Intn(10000)                              // random 0–9999
-			runningStr := fmt.Sprintf("%04d", runningNo)
-
-			reqID = prefix + dateTime + runningStr
+			reqID = reqid.Next()
 		}
 
 		c.Set(apiRequestID, reqID)
@@ -111,9 +193,9 @@ func ApiKeyMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		key := utils.GetHeader(c, "X-Key", "Api-Key", "APIKey")
 
+		// The key is never reflected in response headers, only its masked ID is kept for logging.
 		c.Set(apiKey, key)
-		c.Header("X-Key", key)
-		c.Header("Api-Key", key)
+		c.Set(apiKeyID, apikey.MaskKey(key))
 
 		c.Next()
 	}
@@ -158,6 +240,447 @@ func ApiChannelMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RequestBodyMiddleware keeps the raw body for checks that run after binding,
+// such as permission constraints, and restores it for the handler.
+func RequestBodyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err := io.ReadAll(c.Request.Body)
+			c.Request.Body.Close()
+			if err != nil {
+				c.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			c.Set(requestBodyKey, body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware rejects requests over the rate limit or daily quota of their client
+// with 429 and Retry-After, before the handler makes any TCP call.
+// Requests with an unknown key pass through and are rejected by the key validation.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, repo *utils.APIKeyRepository, logger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := repo.ClientName(c.GetString(apiKey))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		path := c.FullPath()
+		decision := limiter.Allow(client, c.Request.Method+":"+path)
+		if decision.Allowed {
+			c.Next()
+			return
+		}
+
+		metrics.ThrottledRequestsTotal.With(prometheus.Labels{"client": client, "path": path, "reason": decision.Reason}).Inc()
+		logger.Warnw("Request throttled", "client", client, "path", path, "reason", decision.Reason, "retryAfter", decision.RetryAfter.String(), "apiRequestID", c.GetString(apiRequestID))
+
+		appErr := appError.ErrTooManyRequests
+		if decision.Reason == ratelimit.ReasonQuota {
+			appErr = appError.ErrQuotaExceeded
+		}
+		c.Header("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(decision.RetryAfter)))
+		handleErrorResponse(c, appErr)
+		c.Abort()
+	}
+}
+
+// ReplayMiddleware rejects a request whose Api-RequestID its client already used within the
+// replay window (409 SYS017), or whose Api-Timestamp is missing when required or too far
+// from the server clock (400 SYS018), before the handler makes any TCP call.
+// Only request IDs sent by the client are checked, not the ones generated for it.
+// Requests with an unknown key pass through and are rejected by the key validation.
+func ReplayMiddleware(guard *replay.Guard, repo *utils.APIKeyRepository, logger *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, ok := repo.ClientName(c.GetString(apiKey))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		requestID := utils.GetHeader(c, "X-Request-ID", "Api-RequestID", "RequestID")
+		timestamp := utils.GetHeader(c, "Api-Timestamp", "X-Timestamp", "Timestamp")
+		err := guard.Check(client, requestID, timestamp)
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		appErr, reason := appError.ErrDuplicateRequestID, "duplicate"
+		if errors.Is(err, replay.ErrTimestamp) {
+			appErr, reason = appError.ErrInvTimestamp, "timestamp"
+		}
+		metrics.ReplayRejectionsTotal.With(prometheus.Labels{"client": client, "reason": reason}).Inc()
+		logger.Warnw("Request rejected as replay", "client", client, "path", c.FullPath(), "reason", reason, "apiRequestID", requestID, "timestamp", timestamp)
+		handleErrorResponse(c, appErr)
+		c.Abort()
+	}
+}
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header, e.g. a UUID or a ULID.
+const maxIdempotencyKeyLength = 64
+
+// IdempotencyMiddleware answers a retry carrying the Idempotency-Key of an earlier request
+// with the stored response of that request, without calling System I again. Keys are
+// scoped to the client. A retry with a different body gets 409 SYS014; a retry while the
+// first request still runs waits for it, up to waitTimeout, then gets 409 SYS015.
+// Only responses to an answer of System I are stored (see utils.BusinessResult), so a
+// request that failed, e.g. on a System I timeout, can be retried.
+// Requests whose key does not pass validation go on to be rejected by the handler.
+func IdempotencyMiddleware(m *idempotency.Manager, cfg config.IdempotencyConfig, repo *utils.APIKeyRepository, logger *zap.SugaredLogger) gin.HandlerFunc {
+	routes := make(map[string]bool, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		routes[route] = true
+	}
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		routeKey := c.Request.Method + ":" + c.FullPath()
+		if key == "" || !routes[routeKey] {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			handleErrorResponse(c, appError.ErrInvIdempotencyKey)
+			c.Abort()
+			return
+		}
+		if repo.Validate(c.GetString(apiKey), c.Request.Method, c.FullPath(), c.ClientIP(), requestBody(c)) != nil {
+			c.Next()
+			return
+		}
+		client, _ := repo.ClientName(c.GetString(apiKey))
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), cfg.WaitTimeout)
+		defer cancel()
+		record, ticket, err := m.Begin(ctx, client+":"+key, idempotency.Fingerprint(routeKey, requestBody(c)))
+		switch {
+		case errors.Is(err, idempotency.ErrConflict):
+			logger.Warnw("Idempotency-Key reused with a different request", "client", client, "route", routeKey, "apiRequestID", c.GetString(apiRequestID))
+			handleErrorResponse(c, appError.ErrIdempotencyConflict)
+			c.Abort()
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			handleErrorResponse(c, appError.ErrIdempotencyInProgress)
+			c.Abort()
+			return
+		case err != nil:
+			logger.Errorw("Idempotency store failed", "error", err, "client", client, "route", routeKey, "apiRequestID", c.GetString(apiRequestID))
+			handleErrorResponse(c, appError.ErrInternalServer)
+			c.Abort()
+			return
+		case record != nil:
+			logger.Infow("Replaying stored response for Idempotency-Key", "client", client, "route", routeKey, "apiRequestID", c.GetString(apiRequestID))
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.Status, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				ticket.Abandon()
+			}
+		}()
+		tee := &responseTee{ResponseWriter: c.Writer}
+		c.Writer = tee
+		c.Next()
+
+		// only an answer of System I is final: a retry after a timeout or SVC902, which
+		// reach the client as 400, must call System I again rather than get the failure
+		if result := c.GetString(utils.SystemIResultKey); tee.Status() >= http.StatusInternalServerError || !utils.BusinessResult(result) {
+			logger.Infow("Not storing response for Idempotency-Key, System I did not answer", "client", client, "route", routeKey, "systemIResult", result, "apiRequestID", c.GetString(apiRequestID))
+			return
+		}
+		completed = true
+		if err := ticket.Complete(tee.Status(), tee.Header().Get("Content-Type"), tee.body.Bytes()); err != nil {
+			logger.Errorw("Failed to store response for Idempotency-Key", "error", err, "client", client, "route", routeKey, "apiRequestID", c.GetString(apiRequestID))
+		}
+	}
+}
+
+// CacheMiddleware answers requests to the routes of cfg from the response cache, keyed on
+// the route, the normalized body and the permission scope of the client, until the TTL
+// of the route passes. Only 200 responses are stored, never errors of System I.
+// "Cache-Control: no-cache" skips the lookup and refreshes the entry. A cached answer
+// still gets its ELK main log, with ServedFrom "cache". Requests whose headers the
+// handler of the route would reject go on to be rejected by it.
+func CacheMiddleware(cache *respcache.Cache, cfg config.CacheConfig, repo *utils.APIKeyRepository, elkPath string, logger *zap.SugaredLogger) gin.HandlerFunc {
+	var serviceNames sync.Map // route -> service name of its ELK log, learned from the handler
+	return func(c *gin.Context) {
+		start := time.Now()
+		routeKey := utils.GetRouteKey(c)
+		ttl, ok := cfg.Routes[routeKey]
+		if !ok {
+			c.Next()
+			return
+		}
+		key := c.GetString(apiKey)
+		if !passesHeaderValidation(c, repo) {
+			c.Next()
+			return
+		}
+		cacheKey := respcache.Key(routeKey, repo.PermissionScope(key, c.Request.Method, c.FullPath()), requestBody(c))
+
+		result := "miss"
+		if strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") {
+			result = "bypass"
+		} else if entry, ok := cache.Get(cacheKey); ok {
+			metrics.ResponseCacheRequestsTotal.With(prometheus.Labels{"route": routeKey, "result": "hit"}).Inc()
+			serviceName, _ := serviceNames.Load(routeKey)
+			name, _ := serviceName.(string)
+			c.Set(elkLog.ServedFromKey, "cache")
+			elkLog.FinalELKLog(c, nil, start, json.RawMessage(requestBody(c)), json.RawMessage(entry.Body), nil, name, "", "", nil, logger, elkPath, handleErrorResponse)
+			c.Header("X-Cache", "HIT")
+			c.Header("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+			c.Data(entry.Status, entry.ContentType, entry.Body)
+			c.Abort()
+			return
+		}
+		metrics.ResponseCacheRequestsTotal.With(prometheus.Labels{"route": routeKey, "result": result}).Inc()
+		c.Header("X-Cache", strings.ToUpper(result))
+
+		tee := &responseTee{ResponseWriter: c.Writer}
+		c.Writer = tee
+		c.Next()
+
+		if tee.Status() != http.StatusOK {
+			return
+		}
+		if !utils.BusinessResult(c.GetString(utils.SystemIResultKey)) {
+			return
+		}
+		serviceNames.Store(routeKey, c.GetString(elkLog.ServiceNameKey))
+		evicted := cache.Set(cacheKey, tee.Status(), tee.Header().Get("Content-Type"), bytes.Clone(tee.body.Bytes()), ttl)
+		metrics.ResponseCacheEvictionsTotal.Add(float64(evicted))
+		metrics.ResponseCacheEntries.Set(float64(cache.Len()))
+	}
+}
+
+// coalescedResponse is the response of a request, copied to the identical requests
+// that waited for it.
+type coalescedResponse struct {
+	status        int
+	contentType   string
+	body          []byte
+	serviceName   string
+	systemIResult string
+}
+
+// CoalesceMiddleware lets concurrent identical requests to the given read-only routes
+// share one run of the handler, and so one System I call: the first request runs, the
+// others wait for it and get a copy of its response, errors included. Requests are
+// identical when their route, normalized body and permission scope match, as for the
+// response cache. Each waiting request still gets its own ELK main log, with ServedFrom
+// "coalesced". Requests whose key, request ID or JSON body does not pass validation are
+// not coalesced, nor are those whose headers the handler of the route would reject.
+func CoalesceMiddleware(routes []string, repo *utils.APIKeyRepository, elkPath string, logger *zap.SugaredLogger) gin.HandlerFunc {
+	readOnly := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		readOnly[route] = true
+	}
+	var group singleflight.Group
+	return func(c *gin.Context) {
+		start := time.Now()
+		routeKey := utils.GetRouteKey(c)
+		if !readOnly[routeKey] {
+			c.Next()
+			return
+		}
+		key := c.GetString(apiKey)
+		if !passesHeaderValidation(c, repo) || !json.Valid(requestBody(c)) {
+			c.Next()
+			return
+		}
+		flightKey := respcache.Key(routeKey, repo.PermissionScope(key, c.Request.Method, c.FullPath()), requestBody(c))
+
+		leader := false
+		shared, _, _ := group.Do(flightKey, func() (interface{}, error) {
+			leader = true
+			tee := &responseTee{ResponseWriter: c.Writer}
+			c.Writer = tee
+			c.Next()
+			return coalescedResponse{
+				status:        tee.Status(),
+				contentType:   tee.Header().Get("Content-Type"),
+				body:          bytes.Clone(tee.body.Bytes()),
+				serviceName:   c.GetString(elkLog.ServiceNameKey),
+				systemIResult: c.GetString(utils.SystemIResultKey),
+			}, nil
+		})
+		if leader {
+			return
+		}
+
+		response := shared.(coalescedResponse)
+		metrics.CoalescedRequestsTotal.With(prometheus.Labels{"route": routeKey}).Inc()
+		// Middlewares that run after the handler, such as the response cache, see the
+		// System I result of the request that made the call.
+		c.Set(utils.SystemIResultKey, response.systemIResult)
+		c.Set(elkLog.ServedFromKey, "coalesced")
+		c.Data(response.status, response.contentType, response.body)
+		c.Abort()
+
+		var appErr *appError.AppError
+		var errResponse appError.ErrorResponse
+		if response.status != http.StatusOK && json.Unmarshal(response.body, &errResponse) == nil && errResponse.ErrorCode != "" {
+			appErr = &appError.AppError{ErrorCode: errResponse.ErrorCode, ErrorMessage: errResponse.ErrorMessage}
+		}
+		elkLog.FinalELKLog(c, nil, start, json.RawMessage(requestBody(c)), json.RawMessage(response.body), appErr, response.serviceName, "", "", nil, logger, elkPath, handleErrorResponse)
+	}
+}
+
+// passesHeaderValidation reports whether the request passes the header check of the
+// handler of its route, so that a response shared with it or cached for it is one its
+// own handler would give. That is ValidateHeaders, the strictest check, unless the handler
+// was seen to call ValidateHeadersForApiKeyAndApiRequestID. It logs nothing: a request
+// that fails goes on to its handler, which rejects it.
+func passesHeaderValidation(c *gin.Context, repo *utils.APIKeyRepository) bool {
+	headers := getAPIHeaders(c)
+	if repo.Validate(c.GetString(apiKey), c.Request.Method, c.FullPath(), c.ClientIP(), requestBody(c)) != nil {
+		return false
+	}
+	if headers.RequestID == "" || len(headers.RequestID) > 20 {
+		return false
+	}
+	if _, keyAndRequestIDOnly := keyAndRequestIDRoutes.Load(utils.GetRouteKey(c)); keyAndRequestIDOnly {
+		return true
+	}
+	return headers.Channel != "" && headers.DeviceOS != ""
+}
+
+// validIdempotencyKey accepts up to maxIdempotencyKeyLength printable ASCII characters.
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// auditSubjectFields are the request fields naming whose state a request changes, in order of preference.
+var auditSubjectFields = []string{"IDCardNo", "AEONID", "AgreementNo", "Agreement"}
+
+// responseTee keeps a copy of the response body for middlewares that run after the handler.
+type responseTee struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseTee) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseTee) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// AuditMiddleware journals every request to routes that reached System I, with the client,
+// request ID, masked subject, System I result and API error code. Requests rejected before
+// the System I call change nothing and are not journaled. A failed write is logged and
+// counted; the response has already been sent by then.
+func AuditMiddleware(j *journal.Journal, routes []string, repo *utils.APIKeyRepository, logger *zap.SugaredLogger) gin.HandlerFunc {
+	audited := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		audited[route] = true
+	}
+	return func(c *gin.Context) {
+		routeKey := c.Request.Method + ":" + c.FullPath()
+		if !audited[routeKey] {
+			c.Next()
+			return
+		}
+
+		requestTime := time.Now()
+		tee := &responseTee{ResponseWriter: c.Writer}
+		c.Writer = tee
+		c.Next()
+
+		result := c.GetString(utils.SystemIResultKey)
+		if result == "" {
+			return
+		}
+		client, _ := repo.ClientName(c.GetString(apiKey))
+		subjectField, subject := auditSubject(requestBody(c))
+		var errResponse appError.ErrorResponse
+		json.Unmarshal(tee.body.Bytes(), &errResponse)
+
+		entry, err := j.Append(journal.Entry{
+			Route:         routeKey,
+			Client:        client,
+			RequestID:     c.GetString(apiRequestID),
+			Subject:       mask.Field(subjectField, subject),
+			SubjectField:  subjectField,
+			Status:        tee.Status(),
+			ErrorCode:     errResponse.ErrorCode,
+			SystemIResult: result,
+			RequestTime:   requestTime,
+			ResponseTime:  time.Now(),
+		})
+		if err != nil {
+			metrics.AuditWriteFailuresTotal.With(prometheus.Labels{"path": c.FullPath()}).Inc()
+			logger.Errorw("Failed to write audit journal entry", "error", err, "route", routeKey, "client", client, "apiRequestID", c.GetString(apiRequestID))
+			return
+		}
+		logger.Debugw("Audit journal entry written", "seq", entry.Seq, "route", routeKey, "apiRequestID", entry.RequestID)
+	}
+}
+
+// auditSubject returns the first subject field present in a JSON body, matched in any case.
+func auditSubject(body []byte) (string, string) {
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) != nil {
+		return "", ""
+	}
+	for _, name := range auditSubjectFields {
+		for key, value := range fields {
+			if s, ok := value.(string); ok && s != "" && strings.EqualFold(key, name) {
+				return name, s
+			}
+		}
+	}
+	return "", ""
+}
+
+// TracingMiddleware continues the traceparent of the caller, or starts a new trace,
+// with a server span around the request. The context of c.Request carries the span,
+// and the route for the spans of the System I client.
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method, path := c.Request.Method, c.FullPath()
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx = client.WithRoute(ctx, utils.GetRouteKey(c))
+		ctx, span := tracing.StartKind(ctx, method+" "+path, trace.SpanKindServer,
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.HTTPRoute(path),
+		)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(
+			semconv.HTTPResponseStatusCode(status),
+			attribute.String("connector.request_id", c.GetString(apiRequestID)),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
+
 // PrometheusMiddleware
 func PrometheusMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -172,6 +695,24 @@ func PrometheusMiddleware() gin.HandlerFunc {
 }
 
 // HealthCheck provides a simple health check endpoint.
+// It answers while the process serves requests, System I or not (liveness).
 func HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// ReadinessCheck answers 200 while every required route has a healthy System I port,
+// else 503, with the cached probe results per port (see pkg/health).
+func ReadinessCheck(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.JSON(http.StatusOK, gin.H{"status": "ready", "ready": true})
+			return
+		}
+		report := checker.Report()
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	elkLog "connectorapi-go/internal/adapter/client/elk"
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/config"
+	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/idempotency"
+	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/respcache"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Init()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+const testKey = "router-test-key-0000000000"
+
+func testRepository(t *testing.T, routes ...string) *utils.APIKeyRepository {
+	t.Helper()
+	hash, err := apikey.Hash(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var permissions []config.Permission
+	for _, route := range routes {
+		permissions = append(permissions, config.Permission{Route: route})
+	}
+	return utils.NewAPIKeyRepository(&config.APIClients{Clients: []config.APIKey{
+		{ClientName: "Partner", Status: "active", Keys: []config.KeyCredential{{Hash: hash}}, Permissions: permissions},
+	}}, nil)
+}
+
+// withRequest sets what the middlewares before the tested one leave in the context.
+func withRequest(body string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiKey, testKey)
+		c.Set(requestBodyKey, []byte(body))
+		c.Next()
+	}
+}
+
+// readOnlyRequest returns a request to route with the headers ValidateHeaders asks for,
+// less the ones named in without.
+func readOnlyRequest(path string, without ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"IDCardNo":"1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range map[string]string{"Api-Key": testKey, "Api-RequestID": "RQ-1", "Api-Channel": "MOBILE", "Api-DeviceOS": "IOS"} {
+		req.Header.Set(name, value)
+	}
+	for _, name := range without {
+		req.Header.Del(name)
+	}
+	return req
+}
+
+func TestIdempotencyRetriesSystemIFailures(t *testing.T) {
+	route := "POST:/Api/Collection/CollectionLog"
+	cfg := config.IdempotencyConfig{Routes: []string{route}, WaitTimeout: time.Second}
+	manager := idempotency.NewManager(idempotency.NewMemoryStore(), time.Hour)
+
+	answers := []struct {
+		response string
+		err      error
+	}{
+		{"", errors.New("ER050 read timeout")},
+		{strings.Repeat(" ", 67) + "SVC902", nil},
+		{strings.Repeat(" ", 67) + "      ", nil},
+	}
+	calls := 0
+	router := gin.New()
+	router.POST("/Api/Collection/CollectionLog", withRequest(`{"AgreementNo":"1"}`), IdempotencyMiddleware(manager, cfg, testRepository(t, route), zap.NewNop().Sugar()), func(c *gin.Context) {
+		answer := answers[calls]
+		calls++
+		utils.SetSystemIResult(c, answer.response, answer.err)
+		if !utils.BusinessResult(c.GetString(utils.SystemIResultKey)) {
+			handleErrorResponse(c, appError.ErrService)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"call": calls})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/Api/Collection/CollectionLog", strings.NewReader(`{"AgreementNo":"1"}`))
+		req.Header.Set("Idempotency-Key", "retry-1")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, want := range []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusOK} {
+		if w := send(); w.Code != want || w.Header().Get("Idempotent-Replayed") != "" {
+			t.Fatalf("attempt %d: status %d, replayed %q, want %d from System I", i+1, w.Code, w.Header().Get("Idempotent-Replayed"), want)
+		}
+	}
+	w := send()
+	if w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "true" || !strings.Contains(w.Body.String(), `"call":3`) {
+		t.Errorf("retry after the answer: status %d, replayed %q, body %s, want the stored answer", w.Code, w.Header().Get("Idempotent-Replayed"), w.Body.String())
+	}
+	if calls != 3 {
+		t.Errorf("System I called %d times, want 3", calls)
+	}
+}
+
+// elkCapture keeps the ELK lines FinalELKLog writes.
+type elkCapture struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (w *elkCapture) Write(timestamp time.Time, lines []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, lines...)
+	return nil
+}
+
+func (w *elkCapture) Close() error { return nil }
+
+func TestCoalesceSharesOneCall(t *testing.T) {
+	const n = 5
+	path := "/Api/Customer/GetCustomerInfo"
+	route := "POST:" + path
+	elk := &elkCapture{}
+	elkLog.SetWriter(elk)
+	defer elkLog.SetWriter(nil)
+
+	var arrived, calls atomic.Int32
+	router := gin.New()
+	router.POST(path, withRequest(`{"IDCardNo":"1"}`), func(c *gin.Context) {
+		arrived.Add(1)
+		c.Next()
+	}, CoalesceMiddleware([]string{route}, testRepository(t, route), t.TempDir()+"/", zap.NewNop().Sugar()), func(c *gin.Context) {
+		// Answer once every request is on its way to the flight.
+		for arrived.Load() < n {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		c.Set(elkLog.ServiceNameKey, "GetCustomerInfo")
+		c.JSON(http.StatusAccepted, gin.H{"call": calls.Add(1)})
+	})
+
+	responses := make(chan *httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, readOnlyRequest(path))
+			responses <- w
+		}()
+	}
+	wg.Wait()
+	close(responses)
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler called %d times, want 1", got)
+	}
+	for w := range responses {
+		if w.Code != http.StatusAccepted || w.Body.String() != `{"call":1}` {
+			t.Errorf("status %d, body %s, want the leader's 202 {\"call\":1}", w.Code, w.Body.String())
+		}
+	}
+	if len(elk.lines) != n-1 {
+		t.Fatalf("%d ELK lines, want one per follower: %v", len(elk.lines), elk.lines)
+	}
+	for _, line := range elk.lines {
+		// A line is "<time> <level> :<json>".
+		var entry elkLog.LogMainData
+		if err := json.Unmarshal([]byte(line[strings.Index(line, "{"):]), &entry); err != nil {
+			t.Fatalf("ELK line %q: %v", line, err)
+		}
+		if entry.ServedFrom != "coalesced" || entry.ServiceName != "GetCustomerInfo" {
+			t.Errorf("follower ELK entry: ServedFrom %q, ServiceName %q, want coalesced GetCustomerInfo", entry.ServedFrom, entry.ServiceName)
+		}
+	}
+}
+
+func TestCoalesceSkipsRequestsFailingHeaderValidation(t *testing.T) {
+	path := "/Api/Customer/GetCustomerInfo"
+	route := "POST:" + path
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	router := gin.New()
+	router.POST(path, withRequest(`{"IDCardNo":"1"}`), CoalesceMiddleware([]string{route}, testRepository(t, route), t.TempDir()+"/", zap.NewNop().Sugar()), func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		if appErr := ValidateHeaders(c, c.Request.Method, c.FullPath(), testRepository(t, route), zap.NewNop().Sugar()); appErr != nil {
+			handleErrorResponse(c, appErr)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"IDCardNo": "1"})
+	})
+
+	leader := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, readOnlyRequest(path))
+		leader <- w.Code
+	}()
+	<-started
+
+	for _, missing := range []string{"Api-Channel", "Api-DeviceOS"} {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, readOnlyRequest(path, missing))
+			done <- w
+		}()
+		select {
+		case w := <-done:
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("without %s: status %d, want the handler's rejection", missing, w.Code)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("without %s: the request joined the flight of a valid one", missing)
+		}
+	}
+	close(release)
+	if code := <-leader; code != http.StatusOK {
+		t.Errorf("leader: status %d, want 200", code)
+	}
+}
+
+// systemIAnswer is a System I response with a blank response code.
+var systemIAnswer = strings.Repeat(" ", 80)
+
+func TestCacheStoresOnlySystemIAnswers(t *testing.T) {
+	path := "/Api/Customer/GetCustomerInfo"
+	route := "POST:" + path
+	cfg := config.CacheConfig{Enabled: true, Routes: map[string]time.Duration{route: time.Minute}}
+	tests := []struct {
+		name       string
+		response   string
+		err        error
+		setsResult bool
+		stored     bool
+	}{
+		{"answer", systemIAnswer, nil, true, true},
+		{"business error code", systemIAnswer[:67] + "SVC101" + systemIAnswer[73:], nil, true, true},
+		{"no System I call recorded", "", nil, false, false},
+		{"timeout", "", errors.New("ER040 read timeout"), true, false},
+		{"short response", "   ", nil, true, false},
+		{"system error", systemIAnswer[:67] + "SVC902" + systemIAnswer[73:], nil, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST(path, withRequest(`{"IDCardNo":"1"}`), CacheMiddleware(respcache.New(10), cfg, testRepository(t, route), t.TempDir()+"/", zap.NewNop().Sugar()), func(c *gin.Context) {
+				if tt.setsResult {
+					utils.SetSystemIResult(c, tt.response, tt.err)
+				}
+				c.JSON(http.StatusOK, gin.H{"IDCardNo": "1"})
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), readOnlyRequest(path))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, readOnlyRequest(path))
+			if hit := w.Header().Get("X-Cache") == "HIT"; hit != tt.stored {
+				t.Errorf("second request: X-Cache %q, want stored %v", w.Header().Get("X-Cache"), tt.stored)
+			}
+		})
+	}
+}
+
+func TestCacheAsksForTheHeadersOfTheRouteHandler(t *testing.T) {
+	path := "/Api/uhp/GetRedbookInfo"
+	route := "POST:" + path
+	cfg := config.CacheConfig{Enabled: true, Routes: map[string]time.Duration{route: time.Minute}}
+	calls := 0
+	router := gin.New()
+	router.POST(path, withRequest(`{"IDCardNo":"1"}`), CacheMiddleware(respcache.New(10), cfg, testRepository(t, route), t.TempDir()+"/", zap.NewNop().Sugar()), func(c *gin.Context) {
+		calls++
+		if appErr := ValidateHeadersForApiKeyAndApiRequestID(c, c.Request.Method, c.FullPath(), testRepository(t, route), zap.NewNop().Sugar()); appErr != nil {
+			handleErrorResponse(c, appErr)
+			return
+		}
+		utils.SetSystemIResult(c, systemIAnswer, nil)
+		c.JSON(http.StatusOK, gin.H{"IDCardNo": "1"})
+	})
+
+	// The handler asks for no channel or device OS, the cache learns it from the first request.
+	for i, want := range []string{"", "MISS", "HIT"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, readOnlyRequest(path, "Api-Channel", "Api-DeviceOS"))
+		if w.Code != http.StatusOK || w.Header().Get("X-Cache") != want {
+			t.Errorf("request %d: status %d, X-Cache %q, want 200 and %q", i+1, w.Code, w.Header().Get("X-Cache"), want)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, readOnlyRequest(path, "Api-RequestID"))
+	if w.Code != http.StatusBadRequest || w.Header().Get("X-Cache") != "" {
+		t.Errorf("without a request ID: status %d, X-Cache %q, want the handler's rejection", w.Code, w.Header().Get("X-Cache"))
+	}
+}
+
+func TestCacheSkipsRequestsFailingHeaderValidation(t *testing.T) {
+	path := "/Api/Customer/GetCustomerInfo"
+	route := "POST:" + path
+	cfg := config.CacheConfig{Enabled: true, Routes: map[string]time.Duration{route: time.Minute}}
+	calls := 0
+	router := gin.New()
+	router.POST(path, withRequest(`{"IDCardNo":"1"}`), CacheMiddleware(respcache.New(10), cfg, testRepository(t, route), t.TempDir()+"/", zap.NewNop().Sugar()), func(c *gin.Context) {
+		calls++
+		if appErr := ValidateHeaders(c, c.Request.Method, c.FullPath(), testRepository(t, route), zap.NewNop().Sugar()); appErr != nil {
+			handleErrorResponse(c, appErr)
+			return
+		}
+		utils.SetSystemIResult(c, systemIAnswer, nil)
+		c.JSON(http.StatusOK, gin.H{"IDCardNo": "1"})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, readOnlyRequest(path))
+	if w.Code != http.StatusOK || w.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("first request: status %d, X-Cache %q, want a stored 200", w.Code, w.Header().Get("X-Cache"))
+	}
+	for _, missing := range []string{"Api-Channel", "Api-DeviceOS"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, readOnlyRequest(path, missing))
+		if w.Code != http.StatusBadRequest || w.Header().Get("X-Cache") != "" {
+			t.Errorf("without %s: status %d, X-Cache %q, want the handler's rejection", missing, w.Code, w.Header().Get("X-Cache"))
+		}
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, readOnlyRequest(path))
+	if w.Header().Get("X-Cache") != "HIT" || calls != 3 {
+		t.Errorf("valid request: X-Cache %q after %d handler runs, want a hit after 3", w.Header().Get("X-Cache"), calls)
+	}
+}
+
+func TestPublicRouterHasNoAdminRoutes(t *testing.T) {
+	router := SetupRouter(RouterDeps{Logger: zap.NewNop().Sugar(), APIKeys: testRepository(t)})
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, "/Admin") || strings.HasPrefix(route.Path, "/debug") {
+			t.Errorf("public router serves %s %s", route.Method, route.Path)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/fieldcrypt"
+	"connectorapi-go/pkg/permission"
+)
+
+var (
+	ErrClientNotFound = errors.New("api client not found")
+	ErrInvalidClient  = errors.New("invalid api client")
+)
+
+var nonIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// clientChange edits a copy of one client and returns the details to audit.
+type clientChange func(client *config.APIKey, now time.Time) (details interface{}, err error)
+
+// Snapshot returns a copy of the roles and clients currently in effect.
+func (r *APIKeyRepository) Snapshot() *config.APIClients {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return cloneClients(r.clients)
+}
+
+// CreateClient adds an active client with a new key and returns the key, which is not stored.
+func (r *APIKeyRepository) CreateClient(actor string, client config.APIKey) (string, config.APIKey, error) {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	key, err := apikey.Generate()
+	if err != nil {
+		return "", config.APIKey{}, err
+	}
+	hash, err := apikey.Hash(key)
+	if err != nil {
+		return "", config.APIKey{}, err
+	}
+
+	next := r.Snapshot()
+	client.ID = newClientID(next, client.ClientName)
+	client.Status = "active"
+	client.Keys = []config.KeyCredential{{Hash: hash}}
+	client.LegacyKey = nil
+	if err := validateClient(next, client); err != nil {
+		return "", config.APIKey{}, err
+	}
+	next.Clients = append(next.Clients, client)
+
+	record := r.auditRecord(actor, "create", client, map[string]interface{}{
+		"apiKeyID":    apikey.MaskKey(key),
+		"roles":       client.Roles,
+		"permissions": client.Permissions,
+	})
+	if err := r.commit(next, record); err != nil {
+		return "", config.APIKey{}, err
+	}
+	return key, client, nil
+}
+
+// SetStatus enables or disables a client.
+func (r *APIKeyRepository) SetStatus(actor, id, status string) (config.APIKey, error) {
+	action := "disable"
+	if status == "active" {
+		action = "enable"
+	}
+	client, err := r.update(actor, id, action, func(client *config.APIKey, _ time.Time) (interface{}, error) {
+		previous := client.Status
+		client.Status = status
+		return map[string]string{"from": previous, "to": status}, nil
+	})
+	return client, err
+}
+
+// SetPermissions replaces the roles and own permissions of a client.
+func (r *APIKeyRepository) SetPermissions(actor, id string, roles []string, permissions []config.Permission) (config.APIKey, error) {
+	client, err := r.update(actor, id, "permissions", func(client *config.APIKey, _ time.Time) (interface{}, error) {
+		client.Roles = roles
+		client.Permissions = permissions
+		return map[string]interface{}{"roles": roles, "permissions": permissions}, nil
+	})
+	return client, err
+}
+
+// SetFieldEncryption sets how sensitive response fields are encrypted for a client,
+// nil returns them in clear text again. Keys are never written to the audit trail.
+func (r *APIKeyRepository) SetFieldEncryption(actor, id string, fe *config.FieldEncryption) (config.APIKey, error) {
+	client, err := r.update(actor, id, "field-encryption", func(client *config.APIKey, _ time.Time) (interface{}, error) {
+		client.FieldEncryption = fe
+		if fe == nil {
+			return map[string]string{"method": "none"}, nil
+		}
+		return map[string]string{"method": fe.Method, "keyId": fe.KeyID}, nil
+	})
+	return client, err
+}
+
+// RotateKey adds a new key to a client and lets its current keys expire after overlap,
+// so partners can switch without downtime. It returns the new key, which is not stored.
+func (r *APIKeyRepository) RotateKey(actor, id string, overlap time.Duration) (string, config.APIKey, error) {
+	key, err := apikey.Generate()
+	if err != nil {
+		return "", config.APIKey{}, err
+	}
+	hash, err := apikey.Hash(key)
+	if err != nil {
+		return "", config.APIKey{}, err
+	}
+
+	client, err := r.update(actor, id, "rotate", func(client *config.APIKey, now time.Time) (interface{}, error) {
+		cutoff := now.Add(overlap)
+		for i := range client.Keys {
+			if client.Keys[i].ExpiresAt == nil || client.Keys[i].ExpiresAt.After(cutoff) {
+				client.Keys[i].ExpiresAt = &cutoff
+			}
+		}
+		client.Keys = append(client.Keys, config.KeyCredential{Hash: hash})
+		return map[string]interface{}{"apiKeyID": apikey.MaskKey(key), "previousKeysExpireAt": cutoff}, nil
+	})
+	if err != nil {
+		return "", config.APIKey{}, err
+	}
+	return key, client, nil
+}
+
+// update applies change to a copy of client id, validates and commits it.
+func (r *APIKeyRepository) update(actor, id, action string, change clientChange) (config.APIKey, error) {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	next := r.Snapshot()
+	index := -1
+	for i := range next.Clients {
+		if next.Clients[i].ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return config.APIKey{}, ErrClientNotFound
+	}
+
+	client := next.Clients[index]
+	details, err := change(&client, r.now())
+	if err != nil {
+		return config.APIKey{}, err
+	}
+	if err := validateClient(next, client); err != nil {
+		return config.APIKey{}, err
+	}
+	next.Clients[index] = client
+
+	if err := r.commit(next, r.auditRecord(actor, action, client, details)); err != nil {
+		return config.APIKey{}, err
+	}
+	return client, nil
+}
+
+// commit persists next and makes it the set of clients in effect.
+func (r *APIKeyRepository) commit(next *config.APIClients, record AuditRecord) error {
+	if r.store != nil {
+		if err := r.store.Save(next, record); err != nil {
+			r.mu.Lock()
+			r.saveErr = err
+			r.mu.Unlock()
+			return err
+		}
+	}
+	r.replace(next)
+	r.mu.Lock()
+	r.saveErr = nil
+	r.mu.Unlock()
+	return nil
+}
+
+// ConfigHealth reports whether the last admin change of the clients was saved.
+// After a failed save the clients before the change stay in effect.
+func (r *APIKeyRepository) ConfigHealth() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.saveErr != nil {
+		return fmt.Errorf("saving API clients failed: %w", r.saveErr)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) auditRecord(actor, action string, client config.APIKey, details interface{}) AuditRecord {
+	return AuditRecord{
+		Time:       r.now(),
+		Actor:      actor,
+		Action:     action,
+		ClientID:   client.ID,
+		ClientName: client.ClientName,
+		Details:    details,
+	}
+}
+
+// AssignClientIDs gives every client without an id one derived from its name.
+func AssignClientIDs(apiClients *config.APIClients) {
+	for i := range apiClients.Clients {
+		if apiClients.Clients[i].ID == "" {
+			apiClients.Clients[i].ID = newClientID(apiClients, apiClients.Clients[i].ClientName)
+		}
+	}
+}
+
+// newClientID derives an unused id from the client name, e.g. "FriendsAPI" -> "friendsapi-7".
+func newClientID(apiClients *config.APIClients, name string) string {
+	base := strings.Trim(nonIDChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "client"
+	}
+	used := make(map[string]bool, len(apiClients.Clients))
+	for _, c := range apiClients.Clients {
+		used[c.ID] = true
+	}
+	if !used[base] {
+		return base
+	}
+	for n := 1; ; n++ {
+		if id := fmt.Sprintf("%s-%d", base, n); !used[id] {
+			return id
+		}
+	}
+}
+
+// validateClient checks what the admin API may change; the startup linter covers the rest.
+func validateClient(apiClients *config.APIClients, client config.APIKey) error {
+	if strings.TrimSpace(client.ClientName) == "" {
+		return fmt.Errorf("%w: clientName is required", ErrInvalidClient)
+	}
+	if client.Status != "active" && client.Status != "inactive" {
+		return fmt.Errorf("%w: status must be active or inactive", ErrInvalidClient)
+	}
+	for _, role := range client.Roles {
+		if _, ok := apiClients.Roles[role]; !ok {
+			return fmt.Errorf("%w: unknown role %q", ErrInvalidClient, role)
+		}
+	}
+	matcher := permission.NewMatcher()
+	for _, p := range client.Permissions {
+		if err := matcher.Add(p.Route, p.Constraints); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidClient, err)
+		}
+	}
+	if len(ParseCIDRs(client.AllowedCIDRs)) != len(client.AllowedCIDRs) {
+		return fmt.Errorf("%w: invalid allowedCIDRs", ErrInvalidClient)
+	}
+	if fe := client.FieldEncryption; fe != nil {
+		if _, err := fieldcrypt.New(fe.Method, fe.PublicKey, fe.SharedKey, fe.KeyID); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidClient, err)
+		}
+	}
+	return nil
+}
+
+func cloneClients(apiClients *config.APIClients) *config.APIClients {
+	clone := &config.APIClients{}
+	if apiClients == nil {
+		return clone
+	}
+	// A JSON round trip copies every nested slice, map and time pointer.
+	data, err := json.Marshal(apiClients)
+	if err == nil {
+		err = json.Unmarshal(data, clone)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("clone api clients: %v", err))
+	}
+	return clone
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func newStoredRepository(t *testing.T) (*APIKeyRepository, *FileAPIKeyStore, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "apikeys.json")
+	initial := `{"roles": {"collection-partner": {"permissions": ["POST:/Api/Collection/*"]}}, "clients": []}`
+	if err := os.WriteFile(path, []byte(initial), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store := NewFileAPIKeyStore(path, filepath.Join(dir, "audit.log"))
+	apiClients, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAPIKeyRepository(apiClients, store), store, dir
+}
+
+func TestAdminChangesArePersistedAndAudited(t *testing.T) {
+	repo, store, dir := newStoredRepository(t)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	key, client, err := repo.CreateClient("ops", config.APIKey{ClientName: "Debt Agency", Roles: []string{"collection-partner"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.ID != "debt-agency" {
+		t.Errorf("ID = %q, want debt-agency", client.ID)
+	}
+	if err := repo.Validate(key, "POST", "/Api/Collection/CollectionLog", "10.0.0.1", nil); err != nil {
+		t.Errorf("new key rejected: %v", err)
+	}
+
+	newKey, _, err := repo.RotateKey("ops", client.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Validate(key, "POST", "/Api/Collection/CollectionLog", "10.0.0.1", nil); err != nil {
+		t.Errorf("old key rejected during overlap: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := repo.Validate(key, "POST", "/Api/Collection/CollectionLog", "10.0.0.1", nil); !errors.Is(err, ErrKeyExpired) {
+		t.Errorf("old key after overlap = %v, want %v", err, ErrKeyExpired)
+	}
+	if err := repo.Validate(newKey, "POST", "/Api/Collection/CollectionLog", "10.0.0.1", nil); err != nil {
+		t.Errorf("rotated key rejected: %v", err)
+	}
+
+	if _, err := repo.SetStatus("ops", client.ID, "inactive"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Validate(newKey, "POST", "/Api/Collection/CollectionLog", "10.0.0.1", nil); !errors.Is(err, ErrClientInactive) {
+		t.Errorf("disabled client = %v, want %v", err, ErrClientInactive)
+	}
+	if _, err := repo.SetPermissions("ops", client.ID, []string{"unknown"}, nil); !errors.Is(err, ErrInvalidClient) {
+		t.Errorf("unknown role = %v, want %v", err, ErrInvalidClient)
+	}
+	if _, err := repo.SetStatus("ops", "missing", "inactive"); !errors.Is(err, ErrClientNotFound) {
+		t.Errorf("missing client = %v, want %v", err, ErrClientNotFound)
+	}
+
+	reloaded, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Clients) != 1 || reloaded.Clients[0].Status != "inactive" || len(reloaded.Clients[0].Keys) != 2 {
+		t.Errorf("persisted clients = %+v", reloaded.Clients)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "apikeys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditData, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{key, newKey} {
+		if strings.Contains(string(data), secret) || strings.Contains(string(auditData), secret) {
+			t.Error("a raw key was written to disk")
+		}
+	}
+
+	var actions []string
+	scanner := bufio.NewScanner(strings.NewReader(string(auditData)))
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.Actor != "ops" || record.ClientID != "debt-agency" {
+			t.Errorf("audit record = %+v", record)
+		}
+		actions = append(actions, record.Action)
+	}
+	if got := strings.Join(actions, ","); got != "create,rotate,disable" {
+		t.Errorf("audited actions = %s, want create,rotate,disable", got)
+	}
+}
+
+func TestAdminChangesDuringValidation(t *testing.T) {
+	repo, _, _ := newStoredRepository(t)
+	key, client, err := repo.CreateClient("ops", config.APIKey{ClientName: "Agency", Roles: []string{"collection-partner"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					if err := repo.Validate(key, "POST", "/Api/Collection/CollectionLog", "10.0.0.1", nil); err != nil {
+						t.Errorf("Validate() = %v during admin changes", err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		if _, _, err := repo.RotateKey("ops", client.ID, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.SetPermissions("ops", client.ID, []string{"collection-partner"}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
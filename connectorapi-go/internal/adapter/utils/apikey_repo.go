@@ -1,47 +1,253 @@
 package utils
 
 import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/apikey"
 	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/fieldcrypt"
+	"connectorapi-go/pkg/metrics"
+	"connectorapi-go/pkg/permission"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrKeyUnknown       = errors.New("api key unknown")
+	ErrClientInactive   = errors.New("api client inactive")
+	ErrKeyNotYetValid   = errors.New("api key not yet valid")
+	ErrKeyExpired       = errors.New("api key expired")
+	ErrIPNotAllowed     = errors.New("client ip not allowed")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrValueNotAllowed  = permission.ErrValueNotAllowed
 )
 
-// Validation of API keys based on configuration
+// Validation of API keys based on configuration.
+// The clients are replaced as a whole on every admin change, so readers only hold
+// the read lock while looking up a key.
 type APIKeyRepository struct {
-	keys map[string]*config.APIKey
+	mu      sync.RWMutex
+	clients *config.APIClients
+	keys    map[string][]clientCredential // key ID -> credentials sharing that ID
+
+	writeMu sync.Mutex  // serializes admin changes
+	store   APIKeyStore // nil keeps admin changes in memory only
+	saveErr error       // of the last admin change, guarded by mu
+	now     func() time.Time
+}
+
+type apiClient struct {
+	config      *config.APIKey
+	networks    []*net.IPNet
+	permissions *permission.Matcher
+	encrypter   fieldcrypt.Encrypter // nil without FieldEncryption
+	encryptErr  error
 }
 
-// // New repository and pre-loads keys into map
-// func NewAPIKeyRepository(apiKeys []config.APIKey) *APIKeyRepository {
-// 	keyMap := make(map[string]*config.APIKey)
-// 	for i := range apiKeys {
-// 		keyMap[apiKeys[i].Key] = &apiKeys[i]
-// 	}
-// 	return &APIKeyRepository{keys: keyMap}
-// }
+type clientCredential struct {
+	credential apikey.Credential
+	key        config.KeyCredential
+	client     *apiClient
+}
+
+// NewAPIKeyRepository pre-loads the hashed keys of every client, indexed by key ID,
+// and compiles the permissions of its roles and its own. Admin changes are saved to store.
+func NewAPIKeyRepository(apiClients *config.APIClients, store APIKeyStore) *APIKeyRepository {
+	r := &APIKeyRepository{store: store, now: time.Now}
+	r.replace(apiClients)
+	return r
+}
 
-func NewAPIKeyRepository(apiKeys []config.APIKey) *APIKeyRepository {
-	keyMap := make(map[string]*config.APIKey)
+// replace swaps in a new set of clients.
+func (r *APIKeyRepository) replace(apiClients *config.APIClients) {
+	keys := indexKeys(apiClients)
+	r.mu.Lock()
+	r.clients = apiClients
+	r.keys = keys
+	r.mu.Unlock()
+}
+
+// indexKeys compiles every client. Entries that cannot be parsed are skipped,
+// Validate in pkg/config reports them.
+func indexKeys(apiClients *config.APIClients) map[string][]clientCredential {
+	keyMap := make(map[string][]clientCredential)
+	apiKeys := apiClients.Clients
 	for i := range apiKeys {
-		for _, k := range apiKeys[i].Key { // loop ทุก key ใน []string
-			keyMap[k] = &apiKeys[i]
+		client := &apiClient{
+			config:      &apiKeys[i],
+			networks:    ParseCIDRs(apiKeys[i].AllowedCIDRs),
+			permissions: permission.NewMatcher(),
+		}
+		for _, p := range apiClients.Permissions(apiKeys[i]) {
+			_ = client.permissions.Add(p.Route, p.Constraints)
+		}
+		if fe := apiKeys[i].FieldEncryption; fe != nil {
+			client.encrypter, client.encryptErr = fieldcrypt.New(fe.Method, fe.PublicKey, fe.SharedKey, fe.KeyID)
+		}
+		for _, k := range apiKeys[i].Keys {
+			credential, err := apikey.Parse(k.Hash)
+			if err != nil {
+				continue
+			}
+			keyMap[credential.KeyID] = append(keyMap[credential.KeyID], clientCredential{credential: credential, key: k, client: client})
 		}
 	}
-	return &APIKeyRepository{keys: keyMap}
+	return keyMap
 }
 
-// Validate checks if an API key is valid, active, and has permission
-func (r *APIKeyRepository) Validate(apiKey, method, path string) bool {
-	clientKey, exists := r.keys[apiKey]
-	if !exists || clientKey.Status != "active" {
-		return false
+// ParseCIDRs parses an allowlist; plain addresses are treated as single hosts
+// and invalid entries are dropped.
+func ParseCIDRs(cidrs []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			networks = append(networks, network)
+		}
 	}
+	return networks
+}
 
-	// Check if the key has permission for the specific METHOD:PATH
-	routeKey := method + ":" + path
-	for _, p := range clientKey.Permissions {
-		if p == routeKey {
-			return true
+// lookup returns the credential matching apiKey, comparing hashes in constant time.
+func (r *APIKeyRepository) lookup(apiKey string) *clientCredential {
+	if apiKey == "" {
+		return nil
+	}
+	var found *clientCredential
+	r.mu.RLock()
+	candidates := r.keys[apikey.KeyID(apiKey)]
+	r.mu.RUnlock()
+	for i := range candidates {
+		if candidates[i].credential.Matches(apiKey) && found == nil {
+			found = &candidates[i]
 		}
 	}
+	return found
+}
+
+// ClientName returns the name of the client an API key belongs to, without checking
+// its validity or permissions. Rate limits use it to attribute requests before Validate runs.
+func (r *APIKeyRepository) ClientName(apiKey string) (string, bool) {
+	match := r.lookup(apiKey)
+	if match == nil {
+		return "", false
+	}
+	return match.client.config.ClientName, true
+}
+
+// PermissionScope returns what the client of apiKey may see on a route, see
+// permission.Matcher.Scope. Clients with the same scope get the same response to the
+// same request, so cached responses are shared by scope; a client encrypting fields
+// with its own key has a scope of its own.
+func (r *APIKeyRepository) PermissionScope(apiKey, method, path string) string {
+	match := r.lookup(apiKey)
+	if match == nil {
+		return ""
+	}
+	scope := match.client.permissions.Scope(method, path)
+	if match.client.config.FieldEncryption != nil {
+		scope += "|client=" + match.client.config.ID
+	}
+	return scope
+}
+
+// Validate checks if an API key is valid, active, used from an allowed IP and has permission.
+// body is the raw request body, checked against the constraints of the matching permission.
+func (r *APIKeyRepository) Validate(apiKey, method, path, clientIP string, body []byte) error {
+	match := r.lookup(apiKey)
+	if match == nil {
+		return ErrKeyUnknown
+	}
+	clientKey := match.client.config
+	if clientKey.Status != "active" {
+		return ErrClientInactive
+	}
+
+	now := r.now()
+	if match.key.NotBefore != nil && now.Before(*match.key.NotBefore) {
+		return ErrKeyNotYetValid
+	}
+	if match.key.ExpiresAt != nil && !now.Before(*match.key.ExpiresAt) {
+		return ErrKeyExpired
+	}
+
+	if len(clientKey.AllowedCIDRs) > 0 && !ipAllowed(match.client.networks, clientIP) {
+		return ErrIPNotAllowed
+	}
 
+	err := match.client.permissions.Allow(method, path, body)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, permission.ErrValueNotAllowed):
+		return err
+	default:
+		return ErrPermissionDenied
+	}
+}
+
+func ipAllowed(networks []*net.IPNet, clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
 	return false
 }
+
+// CheckExpiry publishes the remaining lifetime of every expiring key and warns
+// about keys that expire within the given window.
+func (r *APIKeyRepository) CheckExpiry(logger *zap.SugaredLogger, warnWithin time.Duration) {
+	now := r.now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, candidates := range r.keys {
+		for _, c := range candidates {
+			if c.key.ExpiresAt == nil || c.client.config.Status != "active" {
+				continue
+			}
+			remaining := c.key.ExpiresAt.Sub(now)
+			keyID := apikey.MaskKeyID(c.credential.KeyID)
+			clientName := c.client.config.ClientName
+
+			if metrics.APIKeyExpirySeconds != nil {
+				metrics.APIKeyExpirySeconds.WithLabelValues(clientName, keyID).Set(remaining.Seconds())
+			}
+			switch {
+			case remaining <= 0:
+				logger.Errorw("API key expired", "client", clientName, "apiKeyID", keyID, "contact", c.client.config.Contact, "expiresAt", c.key.ExpiresAt)
+			case remaining <= warnWithin:
+				logger.Warnw("API key expires soon", "client", clientName, "apiKeyID", keyID, "contact", c.client.config.Contact, "expiresAt", c.key.ExpiresAt, "remaining", remaining.Round(time.Hour).String())
+			}
+		}
+	}
+}
+
+// WatchExpiry runs CheckExpiry now and then on every interval until stop is closed.
+func (r *APIKeyRepository) WatchExpiry(logger *zap.SugaredLogger, warnWithin, interval time.Duration, stop <-chan struct{}) {
+	r.CheckExpiry(logger, warnWithin)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.CheckExpiry(logger, warnWithin)
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/config"
+)
+
+func credential(t *testing.T, key string, notBefore, expiresAt *time.Time) config.KeyCredential {
+	t.Helper()
+	hash, err := apikey.Hash(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config.KeyCredential{Hash: hash, NotBefore: notBefore, ExpiresAt: expiresAt}
+}
+
+func TestAPIKeyRepositoryValidate(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(time.Hour)
+	future := now.Add(24 * time.Hour)
+	past := now.Add(-time.Hour)
+	permission := []config.Permission{{Route: "POST:/Api/Mobile/MobileFullPAN"}}
+
+	repo := NewAPIKeyRepository(&config.APIClients{Clients: []config.APIKey{
+		{
+			ClientName: "FriendsAPI",
+			Status:     "active",
+			Keys: []config.KeyCredential{
+				credential(t, "old-key-rotating-out-000", nil, &cutoff),
+				credential(t, "new-key-rotating-in0-000", nil, nil),
+				credential(t, "next-key-not-yet-valid-0", &future, nil),
+				credential(t, "expired-key-000000-00000", nil, &past),
+			},
+			Permissions:  permission,
+			AllowedCIDRs: []string{"10.254.0.0/16", "192.168.1.10"},
+		},
+		{
+			ClientName:  "Disabled",
+			Status:      "inactive",
+			Keys:        []config.KeyCredential{credential(t, "disabled-client-key-0000", nil, nil)},
+			Permissions: permission,
+		},
+	}}, nil)
+	repo.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		key     string
+		path    string
+		ip      string
+		wantErr error
+	}{
+		{"old key still valid before cutoff", "old-key-rotating-out-000", "/Api/Mobile/MobileFullPAN", "10.254.97.1", nil},
+		{"new key valid during overlap", "new-key-rotating-in0-000", "/Api/Mobile/MobileFullPAN", "192.168.1.10", nil},
+		{"not yet valid", "next-key-not-yet-valid-0", "/Api/Mobile/MobileFullPAN", "10.254.97.1", ErrKeyNotYetValid},
+		{"expired", "expired-key-000000-00000", "/Api/Mobile/MobileFullPAN", "10.254.97.1", ErrKeyExpired},
+		{"ip outside allowlist", "new-key-rotating-in0-000", "/Api/Mobile/MobileFullPAN", "172.16.0.1", ErrIPNotAllowed},
+		{"no permission", "new-key-rotating-in0-000", "/Api/Mobile/DashboardDetail", "10.254.97.1", ErrPermissionDenied},
+		{"unknown key", "unknown", "/Api/Mobile/MobileFullPAN", "10.254.97.1", ErrKeyUnknown},
+		{"empty key", "", "/Api/Mobile/MobileFullPAN", "10.254.97.1", ErrKeyUnknown},
+		{"inactive client", "disabled-client-key-0000", "/Api/Mobile/MobileFullPAN", "10.254.97.1", ErrClientInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.Validate(tt.key, "POST", tt.path, tt.ip, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	repo.now = func() time.Time { return cutoff }
+	if err := repo.Validate("old-key-rotating-out-000", "POST", "/Api/Mobile/MobileFullPAN", "10.254.97.1", nil); !errors.Is(err, ErrKeyExpired) {
+		t.Errorf("old key after cutoff = %v, want %v", err, ErrKeyExpired)
+	}
+}
+
+func TestAPIKeyRepositoryRoles(t *testing.T) {
+	repo := NewAPIKeyRepository(&config.APIClients{
+		Roles: map[string]config.Role{
+			"mobile-app": {Permissions: []config.Permission{
+				{Route: "POST:/Api/Mobile/*", Constraints: map[string][]string{"Channel": {"M", "W"}}},
+			}},
+		},
+		Clients: []config.APIKey{{
+			ClientName:  "MobileApp",
+			Status:      "active",
+			Keys:        []config.KeyCredential{credential(t, "mobile-app-key-0001-0000", nil, nil)},
+			Roles:       []string{"mobile-app"},
+			Permissions: []config.Permission{{Route: "POST:/Api/Consent/UpdateConsent"}},
+		}},
+	}, nil)
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		wantErr error
+	}{
+		{"role glob with allowed channel", "/Api/Mobile/DashboardDetail", `{"Channel":"M"}`, nil},
+		{"channel matched case-insensitively", "/Api/Mobile/MobileFullPAN", `{"channel":"W"}`, nil},
+		{"channel not allowed", "/Api/Mobile/DashboardDetail", `{"Channel":"B"}`, ErrValueNotAllowed},
+		{"second spelling not allowed", "/Api/Mobile/DashboardDetail", `{"Channel":"M","CHANNEL":"B"}`, ErrValueNotAllowed},
+		{"channel missing", "/Api/Mobile/DashboardDetail", `{}`, ErrValueNotAllowed},
+		{"own permission without constraint", "/Api/Consent/UpdateConsent", ``, nil},
+		{"glob stays within one segment", "/Api/Mobile/Dashboard/Detail", `{"Channel":"M"}`, ErrPermissionDenied},
+		{"outside role", "/Api/Common/GetCustomerInfo", `{"Channel":"M"}`, ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.Validate("mobile-app-key-0001-0000", "POST", tt.path, "10.0.0.1", []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRepositoryFieldEncrypter(t *testing.T) {
+	shared := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	repo := NewAPIKeyRepository(&config.APIClients{Clients: []config.APIKey{
+		{ClientName: "Plain", Status: "active", Keys: []config.KeyCredential{credential(t, "plain-key-00000000000000", nil, nil)}},
+		{ClientName: "Encrypted", Status: "active", Keys: []config.KeyCredential{credential(t, "encrypted-key-0000000000", nil, nil)},
+			FieldEncryption: &config.FieldEncryption{Method: "aes-gcm", SharedKey: shared}},
+		{ClientName: "Broken", Status: "active", Keys: []config.KeyCredential{credential(t, "broken-key-0000000000000", nil, nil)},
+			FieldEncryption: &config.FieldEncryption{Method: "jwe", PublicKey: "not a key"}},
+	}}, nil)
+
+	if e, err := repo.FieldEncrypter("plain-key-00000000000000"); e != nil || err != nil {
+		t.Errorf("client without field encryption = %v, %v", e, err)
+	}
+	if e, err := repo.FieldEncrypter("encrypted-key-0000000000"); e == nil || err != nil {
+		t.Errorf("client with field encryption = %v, %v", e, err)
+	}
+	// A broken configuration must never fall back to clear text.
+	if e, err := repo.FieldEncrypter("broken-key-0000000000000"); e != nil || !errors.Is(err, ErrFieldEncryption) {
+		t.Errorf("client with broken field encryption = %v, %v", e, err)
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// APIKeyStore persists API clients changed through the admin API.
+type APIKeyStore interface {
+	Load() (*config.APIClients, error)
+	// Save stores the complete set of clients and appends record to the audit trail.
+	Save(apiClients *config.APIClients, record AuditRecord) error
+}
+
+// AuditRecord describes one change of an API client. It never contains a key.
+type AuditRecord struct {
+	Time       time.Time   `json:"time"`
+	Actor      string      `json:"actor"`
+	Action     string      `json:"action"`
+	ClientID   string      `json:"clientId"`
+	ClientName string      `json:"clientName"`
+	Details    interface{} `json:"details,omitempty"`
+}
+
+// FileAPIKeyStore keeps the clients in apikeys.json and the audit trail as JSON lines.
+type FileAPIKeyStore struct {
+	mu        sync.Mutex
+	path      string
+	auditPath string
+}
+
+func NewFileAPIKeyStore(path, auditPath string) *FileAPIKeyStore {
+	return &FileAPIKeyStore{path: path, auditPath: auditPath}
+}
+
+func (s *FileAPIKeyStore) Load() (*config.APIClients, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return config.LoadAPIKeys(s.path, "")
+}
+
+// Save writes the audit record first, so that no change is ever stored without one,
+// then replaces the key file atomically.
+func (s *FileAPIKeyStore) Save(apiClients *config.APIClients, record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.appendAudit(record); err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+
+	data, err := json.MarshalIndent(apiClients, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileAPIKeyStore) appendAudit(record AuditRecord) error {
+	if s.auditPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.auditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
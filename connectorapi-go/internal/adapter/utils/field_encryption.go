@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"connectorapi-go/pkg/fieldcrypt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldEncrypterKey holds the fieldcrypt.Encrypter of the calling client in the gin context.
+const FieldEncrypterKey = "Field-Encrypter"
+
+var ErrFieldEncryption = errors.New("field encryption not available")
+
+// FieldEncrypter returns the encrypter of the client an API key belongs to, nil when the
+// client receives sensitive fields in clear text. A client whose encryption is configured
+// but cannot be set up gets ErrFieldEncryption, never clear text.
+func (r *APIKeyRepository) FieldEncrypter(apiKey string) (fieldcrypt.Encrypter, error) {
+	match := r.lookup(apiKey)
+	if match == nil || match.client.config.FieldEncryption == nil {
+		return nil, nil
+	}
+	if match.client.encryptErr != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFieldEncryption, match.client.encryptErr)
+	}
+	return match.client.encrypter, nil
+}
+
+// FieldEncrypterFrom returns the encrypter the handler stored for the request, nil if none.
+func FieldEncrypterFrom(c *gin.Context) fieldcrypt.Encrypter {
+	value, ok := c.Get(FieldEncrypterKey)
+	if !ok {
+		return nil
+	}
+	encrypter, _ := value.(fieldcrypt.Encrypter)
+	return encrypter
+}
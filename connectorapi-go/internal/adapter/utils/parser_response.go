@@ -3,94 +3,132 @@ package utils
 import (
 	"strconv"
 	"strings"
-	"fmt"
+	"unicode/utf8"
 )
 
+// FixedParser reads the fields of a fixed-length System I message, decoded from CP874.
+// Offsets and lengths count characters as in the System I layouts, not bytes: a Thai
+// character takes three bytes once decoded. The parser is a cursor: it walks the message
+// once while fields are read in increasing order and returns them as substrings of the
+// message, so reading a string field does not allocate. Reading an earlier field again
+// walks from the start.
 type FixedParser struct {
-	data []rune
+	data string
+	pos  int // byte offset of character char
+	char int
 }
 
 func NewFixedParser(data string) FixedParser {
-	return FixedParser{
-		data: []rune(data),
-	}
+	return FixedParser{data: data}
 }
 
-type DecimalString float64
+// DecimalString is an amount with two implied decimals, kept in hundredths as System I
+// sends it so that it never goes through float64. It is written to JSON as a number with
+// two decimals, e.g. 1234.50.
+type DecimalString int64
 
 func (d DecimalString) MarshalJSON() ([]byte, error) {
-    s := fmt.Sprintf("%.2f", d)        // แปลงเป็น string ทศนิยม 2 ตำแหน่ง
-    return []byte(s), nil               // ส่งเป็น JSON number
+	return d.appendDecimal(make([]byte, 0, 24)), nil
 }
 
-// ReadString ตัด substring จากตำแหน่ง start และ length แล้ว trim ช่องว่าง
-func (p FixedParser) ReadString(start, length int) string {
-	if start >= len(p.data) {
-		return ""
-	}
-	end := start + length
-	if end > len(p.data) {
-		end = len(p.data)
-	}
-	return strings.TrimSpace(string(p.data[start:end]))
+func (d DecimalString) String() string {
+	return string(d.appendDecimal(nil))
 }
 
-// ReadInt แปลง substring เป็น int
-func (p FixedParser) ReadInt(start, length int) int {
-	s := p.ReadString(start, length)
-	if s == "" {
-		return 0
+func (d DecimalString) appendDecimal(b []byte) []byte {
+	u := uint64(d)
+	if d < 0 {
+		b = append(b, '-')
+		u = -u
 	}
-	i, _ := strconv.Atoi(s)
-	return i
+	b = strconv.AppendUint(b, u/100, 10)
+	return append(b, '.', byte('0'+u/10%10), byte('0'+u%10))
 }
 
-// ReadFloat100 แปลง substring เป็น float64 แล้วหาร 100 (รองรับ decimal 2 ตำแหน่ง)
-func (p FixedParser) ReadFloat100(start, length int) float64 {
-	s := p.ReadString(start, length)
-	if s == "" {
-		return 0.0
+// seek moves the cursor to character n, or to the end of the message if it is shorter,
+// and returns its byte offset.
+func (p *FixedParser) seek(n int) int {
+	if n < p.char {
+		p.pos, p.char = 0, 0
 	}
-	i, _ := strconv.ParseInt(s, 10, 64)
-	return float64(i) / 100.0
+	for p.char < n && p.pos < len(p.data) {
+		if p.data[p.pos] < utf8.RuneSelf {
+			p.pos++
+		} else {
+			_, size := utf8.DecodeRuneInString(p.data[p.pos:])
+			p.pos += size
+		}
+		p.char++
+	}
+	return p.pos
 }
 
-func ReadRunes(runes []rune, start int, length int) []rune {
-	if start >= len(runes) {
-		return []rune{}
-	}
-	end := start + length
-	if end > len(runes) {
-		end = len(runes)
-	}
-	return runes[start:end]
+// Field returns length characters from start, untrimmed and cut short at the end of the message.
+func (p *FixedParser) Field(start, length int) string {
+	from := p.seek(start)
+	return p.data[from:p.seek(start+length)]
 }
 
-func ReadBlockStr(blockRunes []rune, start int, length int) string {
-	if start >= len(blockRunes) {
-		return ""
-	}
-	end := start + length
-	if end > len(blockRunes) {
-		end = len(blockRunes)
-	}
-	return strings.TrimSpace(string(blockRunes[start:end]))
+// ReadString returns the field at start, without leading and trailing spaces.
+func (p *FixedParser) ReadString(start, length int) string {
+	return strings.TrimSpace(p.Field(start, length))
+}
+
+// ReadInt returns the field at start as a number, 0 when it is blank or not a number.
+func (p *FixedParser) ReadInt(start, length int) int {
+	return int(parseNumber(p.ReadString(start, length)))
+}
+
+// ReadDecimal returns the field at start as an amount with two implied decimals,
+// 0 when it is blank or not a number.
+func (p *FixedParser) ReadDecimal(start, length int) DecimalString {
+	return DecimalString(parseNumber(p.ReadString(start, length)))
 }
 
-func  ReadBlockInt(blockRunes []rune, start int, length int) int {
-	s := ReadBlockStr(blockRunes, start, length)
+// parseNumber parses a signed decimal field as strconv.ParseInt does, returning 0 when it
+// is blank or not a number. Fields short enough not to overflow are parsed in place, so
+// that a field System I left filled with spaces or text does not allocate a *NumError.
+func parseNumber(s string) int64 {
+	if len(s) > 18 {
+		i, _ := strconv.ParseInt(s, 10, 64)
+		return i
+	}
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
 	if s == "" {
 		return 0
 	}
-	i, _ := strconv.Atoi(s)
-	return i
+	var n int64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return 0
+		}
+		n = n*10 + int64(c-'0')
+	}
+	if neg {
+		return -n
+	}
+	return n
+}
+
+// Block returns a parser over length characters from start, for the repeating blocks
+// of a message such as its list of cards; offsets in the block start at 0.
+func (p *FixedParser) Block(start, length int) FixedParser {
+	return NewFixedParser(p.Field(start, length))
 }
-		
-func ReadBlockFloat100ToDecimal(blockRunes []rune, start int, length int) DecimalString {
-    s := ReadBlockStr(blockRunes, start, length)
-    i, _ := strconv.ParseInt(s, 10, 64)
-    f := float64(i) / 100.0
-    return DecimalString(f)
+
+// Empty reports whether the message is empty, e.g. for a block past its end.
+func (p *FixedParser) Empty() bool {
+	return p.data == ""
+}
+
+// Len returns the number of characters in the message.
+func (p *FixedParser) Len() int {
+	return utf8.RuneCountInString(p.data)
 }
 
 func ConvertStringToInt(s string) int {
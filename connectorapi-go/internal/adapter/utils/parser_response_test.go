@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFixedParserCountsCharacters(t *testing.T) {
+	// "ชื่อ" is four characters of three bytes each once decoded from CP874.
+	p := NewFixedParser("AB" + "ชื่อ" + "  " + "00012345" + "-0000050")
+
+	if got := p.ReadString(0, 2); got != "AB" {
+		t.Errorf("ReadString(0,2) = %q, want AB", got)
+	}
+	if got := p.Field(2, 6); got != "ชื่อ  " {
+		t.Errorf("Field(2,6) = %q, want the name untrimmed", got)
+	}
+	if got := p.ReadString(2, 6); got != "ชื่อ" {
+		t.Errorf("ReadString(2,6) = %q, want ชื่อ", got)
+	}
+	if got := p.ReadInt(8, 8); got != 12345 {
+		t.Errorf("ReadInt(8,8) = %d, want 12345", got)
+	}
+	if got := p.ReadDecimal(16, 8); got != -50 {
+		t.Errorf("ReadDecimal(16,8) = %d, want -50", got)
+	}
+	// reading back from an earlier field walks again from the start
+	if got := p.ReadString(3, 2); got != "ื่" {
+		t.Errorf("ReadString(3,2) after a later field = %q, want ื่", got)
+	}
+	if got := p.Len(); got != 24 {
+		t.Errorf("Len() = %d, want 24", got)
+	}
+}
+
+func TestFixedParserPastEnd(t *testing.T) {
+	p := NewFixedParser("ก12")
+
+	if got := p.Field(1, 10); got != "12" {
+		t.Errorf("Field(1,10) = %q, want the field cut at the end", got)
+	}
+	if got := p.ReadString(5, 3); got != "" {
+		t.Errorf("ReadString(5,3) = %q, want empty", got)
+	}
+	if got := p.ReadInt(5, 3); got != 0 {
+		t.Errorf("ReadInt(5,3) = %d, want 0", got)
+	}
+	if b := p.Block(3, 10); !b.Empty() {
+		t.Errorf("Block(3,10) is not empty")
+	}
+	if b := p.Block(0, 2); b.Empty() || b.ReadString(0, 2) != "ก1" {
+		t.Errorf("Block(0,2) = %q, want ก1", b.Field(0, 2))
+	}
+}
+
+func TestFixedParserNumbers(t *testing.T) {
+	tests := []struct {
+		field string
+		want  int64
+	}{
+		{"00001234", 1234},
+		{"+0001234", 1234},
+		{"-0001234", -1234},
+		{"        ", 0},
+		{"-       ", 0},
+		{"12 34   ", 0},
+		{"0012.34 ", 0},
+		{"ก0000001", 0},
+		{"0000000000000000000123", 123},
+	}
+	for _, tt := range tests {
+		p := NewFixedParser(tt.field)
+		if got := p.ReadDecimal(0, 30); int64(got) != tt.want {
+			t.Errorf("ReadDecimal(%q) = %d, want %d", tt.field, got, tt.want)
+		}
+		if got := p.ReadInt(0, 30); int64(got) != tt.want {
+			t.Errorf("ReadInt(%q) = %d, want %d", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalStringJSON(t *testing.T) {
+	tests := []struct {
+		value DecimalString
+		want  string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-50, "-0.50"},
+		{123450, "1234.50"},
+		{-123456, "-1234.56"},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("json.Marshal(%d) = %s, want %s", int64(tt.value), b, tt.want)
+		}
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("String() = %s, want %s", got, tt.want)
+		}
+	}
+}
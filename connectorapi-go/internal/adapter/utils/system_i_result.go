@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SystemIResultKey holds the outcome of the System I call of a request in the gin context.
+const SystemIResultKey = "SystemI-Result"
+
+// SetSystemIResult records the outcome of a System I call for the audit journal and the
+// middlewares that store responses: the response code of the header, "OK" when it is
+// blank, or the ER0xx code of the TCP client when the call failed.
+func SetSystemIResult(c *gin.Context, responseStr string, err error) {
+	c.Set(SystemIResultKey, SystemIResultCode(responseStr, err))
+}
+
+// BusinessResult tells whether a result recorded by SetSystemIResult is the answer of
+// System I to the request. A failed call (ER0xx), a truncated response or a system
+// error of System I (SVC902) is not, and a retry may well succeed.
+func BusinessResult(result string) bool {
+	return result != "" && !strings.HasPrefix(result, "ER") && result != "SHORT" && result != "SVC902"
+}
+
+// SystemIResultCode is the value SetSystemIResult records.
+func SystemIResultCode(responseStr string, err error) string {
+	if err != nil {
+		if msg := err.Error(); len(msg) >= 5 && strings.HasPrefix(msg, "ER") {
+			return msg[:5]
+		}
+		return "ERROR"
+	}
+	if len(responseStr) < 73 {
+		return "SHORT"
+	}
+	code := strings.TrimSpace(responseStr[67:73])
+	if code == "" {
+		return "OK"
+	}
+	return code
+}
@@ -165,6 +165,8 @@ type MobileCardListRs struct {
 	FirstEmbossDate  int    `json:"FirstEmbossDate"`
 	FirstConfirmDate int    `json:"FirstConfirmDate"`
 	DigitalCardFlag  string `json:"DigitalCardFlag" validate:"max=1"`
+	ErrorCode        string `json:"ErrorCode,omitempty"`    // set when this card could not be read
+	ErrorMessage     string `json:"ErrorMessage,omitempty"`
 }
 
 type MobileFullPanResult struct {
@@ -177,5 +179,5 @@ type MobileFullPanResult struct {
     DomainError    *appError.AppError
     ServiceName    string
 	UserRef        string
-    LogLine1       string
+    LogLines       []string // one line log per card, in CardList_rq order
 }
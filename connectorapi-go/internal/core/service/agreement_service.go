@@ -12,6 +12,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -115,7 +116,7 @@ func (s *agreementService) UpdateStatus(c *gin.Context, updateStatusReq domain.U
 		}
 	}
 
-	portList, ok := destination.Ports["UpdateAgreementStatus"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.UpdateStatusResult{
@@ -149,7 +150,9 @@ func (s *agreementService) UpdateStatus(c *gin.Context, updateStatusReq domain.U
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatUpdateStatusRequest(updateStatusReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -160,10 +163,10 @@ func (s *agreementService) UpdateStatus(c *gin.Context, updateStatusReq domain.U
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -277,8 +280,9 @@ func (s *agreementService) UpdateStatus(c *gin.Context, updateStatusReq domain.U
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	updateStatusResponse, err := format.FormatUpdateStatusResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map updateStatusResponse:", err)
 
@@ -406,7 +410,7 @@ func (s *agreementService) AgreeMentBilling(c *gin.Context, AgreeMentBillingReq
 		}
 	}
 
-	portList, ok := destination.Ports["AgreeMentBilling"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.AgreeMentBillingResult{
@@ -442,7 +446,9 @@ func (s *agreementService) AgreeMentBilling(c *gin.Context, AgreeMentBillingReq
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatAgreeMentBillingRequest(AgreeMentBillingReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -453,10 +459,10 @@ func (s *agreementService) AgreeMentBilling(c *gin.Context, AgreeMentBillingReq
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -585,8 +591,9 @@ func (s *agreementService) AgreeMentBilling(c *gin.Context, AgreeMentBillingReq
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	AgreeMentBillingResponse, err := format.FormatAgreeMentBillingResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map AgreeMentBillingResponse:", err)
 
@@ -12,6 +12,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -189,7 +190,7 @@ func (s *applicationCapService) GetApplicationNo(c *gin.Context, getApplicationN
 		}
 	}
 
-	portList, ok := destination.Ports["GetApplicationNo"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetApplicationNoResult{
@@ -223,7 +224,9 @@ func (s *applicationCapService) GetApplicationNo(c *gin.Context, getApplicationN
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetApplicationNoRequest(getApplicationNoReq)
+	formatSpan.End()
 
 	var requestLength string
 	lenData := len(fixedLengthData)
@@ -249,10 +252,10 @@ func (s *applicationCapService) GetApplicationNo(c *gin.Context, getApplicationN
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -366,8 +369,9 @@ func (s *applicationCapService) GetApplicationNo(c *gin.Context, getApplicationN
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getApplicationNoResponse, err := format.FormatGetApplicationNoResponse(responseStr)
+	tracing.End(parseSpan, err)
 
 	if err != nil {
 		s.logger.Errorw("Error map getApplicationNoResponse:", err)
@@ -553,7 +557,7 @@ func (s *applicationCapService) SubmitCardApplication(c *gin.Context, submitCard
 		}
 	}
 
-	portList, ok := destination.Ports["SubmitCardApplication"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.SubmitCardApplicationResult{
@@ -587,7 +591,9 @@ func (s *applicationCapService) SubmitCardApplication(c *gin.Context, submitCard
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatSubmitCardApplicationRequest(submitCardApplicationReq)
+	formatSpan.End()
 
 	var requestLength string
 	lenData := len(fixedLengthData)
@@ -613,10 +619,10 @@ func (s *applicationCapService) SubmitCardApplication(c *gin.Context, submitCard
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -744,8 +750,9 @@ func (s *applicationCapService) SubmitCardApplication(c *gin.Context, submitCard
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	submitCardApplicationResponse, err := format.FormatSubmitCardApplicationResponse(responseStr)
+	tracing.End(parseSpan, err)
 
 	if err != nil {
 		s.logger.Errorw("Error map submitCardApplicationResponse:", err)
@@ -111,7 +111,7 @@ func (s *applicationLowerService) SubmitLoanApplication(c *gin.Context, submitLo
 		}
 	}
 
-	portList, ok := destination.Ports["SubmitLoanApplication"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.SubmitLoanApplicationResult{
@@ -144,7 +144,9 @@ func (s *applicationLowerService) SubmitLoanApplication(c *gin.Context, submitLo
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
 	submitLoanApplicationReq.RequestID = formattedRequestID
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatSubmitLoanApplicationRequest(submitLoanApplicationReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -155,10 +157,10 @@ func (s *applicationLowerService) SubmitLoanApplication(c *gin.Context, submitLo
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -276,7 +278,6 @@ func (s *applicationLowerService) SubmitLoanApplication(c *gin.Context, submitLo
 	// 	}
 	// }
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
 	if err != nil {
 		s.logger.Errorw("Error map submitLoanApplicationResponse:", err)
 
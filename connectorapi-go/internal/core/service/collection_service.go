@@ -12,6 +12,7 @@ import (
 	"connectorapi-go/pkg/config"
 
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -117,7 +118,7 @@ func (s *collectionService) CollectionDetail(c *gin.Context, collectionDetailReq
 		}
 	}
 
-	portList, ok := destination.Ports["CollectionDetail"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.CollectionDetailResult{
@@ -153,7 +154,9 @@ func (s *collectionService) CollectionDetail(c *gin.Context, collectionDetailReq
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatCollectionDetailRequest(collectionDetailReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -164,10 +167,10 @@ func (s *collectionService) CollectionDetail(c *gin.Context, collectionDetailReq
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -286,8 +289,9 @@ func (s *collectionService) CollectionDetail(c *gin.Context, collectionDetailReq
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	collectionDetailResponse, err := format.FormatCollectionDetailResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map collectionDetailResponse:", err)
 
@@ -411,7 +415,7 @@ func (s *collectionService) CollectionLog(c *gin.Context, collectionLogReq domai
 		}
 	}
 
-	portList, ok := destination.Ports["CollectionLog"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.CollectionLogResult{
@@ -445,7 +449,9 @@ func (s *collectionService) CollectionLog(c *gin.Context, collectionLogReq domai
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatCollectionLogRequest(collectionLogReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -456,9 +462,9 @@ func (s *collectionService) CollectionLog(c *gin.Context, collectionLogReq domai
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload", "payload", combinedPayloadString)
 
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -566,8 +572,9 @@ func (s *collectionService) CollectionLog(c *gin.Context, collectionLogReq domai
 		}
 	}
 
-	s.logger.Debugw("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	collectionLogResponse, err:= format.FormatCollectionLogResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map collectionLogResponse:", err)
 
@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -191,7 +192,7 @@ func (s *commonService) GetCustomerInfo(c *gin.Context, getCustomerInfoReq domai
 		}
 	}
 
-	portList, ok := destination.Ports["GetCustomerInfo"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetCustomerInfoResult{
@@ -256,6 +257,7 @@ func (s *commonService) GetCustomerInfo(c *gin.Context, getCustomerInfoReq domai
 	   }
     }
 
+	formatSpan := startFormatSpan(c, "format request")
 	switch {
     case getCustomerInfoReq.Mode == "S" && getCustomerInfoReq.UserRef !="":
 	System, Format, RequestLength, Language = "MOB_APP", "001", "00021", lang
@@ -267,6 +269,7 @@ func (s *commonService) GetCustomerInfo(c *gin.Context, getCustomerInfoReq domai
 	System, Format, RequestLength, Language = "APP_EKYC", "003", "00021", lang
 	fixedLengthData = format.FormatGetCustomerInfoRequest001And003(getCustomerInfoReq, Language)
     }
+	formatSpan.End()
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
 
@@ -279,10 +282,10 @@ func (s *commonService) GetCustomerInfo(c *gin.Context, getCustomerInfoReq domai
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -415,12 +418,9 @@ func (s *commonService) GetCustomerInfo(c *gin.Context, getCustomerInfoReq domai
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
-
 	var getCustomerInfoResponse interface{}
 
-	s.logger.Info("Received downstream TCP response", "response", formatfromsysi)
-
+	parseSpan := startFormatSpan(c, "format response")
 	switch formatfromsysi {
     case "001":
          getCustomerInfoResponse, err = format.FormatGetCustomerInfoResponse001(responseStr)
@@ -429,6 +429,7 @@ func (s *commonService) GetCustomerInfo(c *gin.Context, getCustomerInfoReq domai
     default:
          getCustomerInfoResponse, err = format.FormatGetCustomerInfoResponse003(responseStr)
     }
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getCustomerInfoResponse:", err)
 
@@ -555,7 +556,7 @@ func (s *commonService) CheckApplyCondition(c *gin.Context, checkApplyConditionR
 		}
 	}
 
-	portList, ok := destination.Ports["CheckApplyCondition"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.CheckApplyConditionResult{
@@ -639,7 +640,9 @@ func (s *commonService) CheckApplyCondition(c *gin.Context, checkApplyConditionR
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatCheckApplyConditionRequest(checkApplyConditionReq)
+	formatSpan.End()
 
 	requestLength := fmt.Sprintf("%05d", len(fixedLengthData))
 
@@ -652,10 +655,10 @@ func (s *commonService) CheckApplyCondition(c *gin.Context, checkApplyConditionR
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -787,8 +790,9 @@ func (s *commonService) CheckApplyCondition(c *gin.Context, checkApplyConditionR
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	checkApplyConditionResponse, err := format.FormatCheckApplyConditionResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map CheckApplyConditionResponse:", err)
 
@@ -912,7 +916,7 @@ func (s *commonService) CheckApplyCondition2ndCard(c *gin.Context, checkApplyCon
 		}
 	}
 
-	portList, ok := destination.Ports["CheckApplyCondition2ndCard"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.CheckApplyCondition2ndCardResult{
@@ -999,7 +1003,9 @@ func (s *commonService) CheckApplyCondition2ndCard(c *gin.Context, checkApplyCon
 		}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatCheckApplyCondition2ndCardRequest(checkApplyConditionCondition2ndCardReq)
+	formatSpan.End()
 
 	requestLength := fmt.Sprintf("%05d", len(fixedLengthData))
 
@@ -1012,10 +1018,10 @@ func (s *commonService) CheckApplyCondition2ndCard(c *gin.Context, checkApplyCon
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -1129,8 +1135,9 @@ func (s *commonService) CheckApplyCondition2ndCard(c *gin.Context, checkApplyCon
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	checkApplyCondition2ndCardResponse, err := format.FormatCheckApplyCondition2ndCardResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map CheckApplyCondition2ndCardResponse:", err)
 
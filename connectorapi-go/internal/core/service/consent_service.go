@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -124,7 +125,7 @@ func (s *consentService) UpdateConsent(c *gin.Context, updateConsentReq domain.U
 		}
 	}
 
-	portList, ok := destination.Ports["UpdateConsent"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.UpdateConsentResult{
@@ -215,7 +216,9 @@ func (s *consentService) UpdateConsent(c *gin.Context, updateConsentReq domain.U
     }
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatUpdateConsentRequest(updateConsentReq)
+	formatSpan.End()
 
 	requestLength := fmt.Sprintf("%05d", len(fixedLengthData))
 
@@ -228,10 +231,10 @@ func (s *consentService) UpdateConsent(c *gin.Context, updateConsentReq domain.U
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -366,8 +369,9 @@ func (s *consentService) UpdateConsent(c *gin.Context, updateConsentReq domain.U
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	updateConsentResponse, err := format.FormatUpdateConsentResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map UpdateConsentResponse:", err)
 
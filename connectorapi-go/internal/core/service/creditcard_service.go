@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -113,7 +114,7 @@ func (s *creditCardService) GetCardSales(c *gin.Context, getCardSalesReq domain.
 		}
 	}
 
-	portList, ok := destination.Ports["GetCardSales"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetCardSalesResult{
@@ -147,7 +148,9 @@ func (s *creditCardService) GetCardSales(c *gin.Context, getCardSalesReq domain.
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetCardSalesRequest(getCardSalesReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -158,10 +161,10 @@ func (s *creditCardService) GetCardSales(c *gin.Context, getCardSalesReq domain.
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -274,8 +277,9 @@ func (s *creditCardService) GetCardSales(c *gin.Context, getCardSalesReq domain.
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getCardSalesResponse, err := format.FormatGetCardSalesResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getCardSalesResponse:", err)
 
@@ -399,7 +403,7 @@ func (s *creditCardService) GetBigCardInfo(c *gin.Context, getBigCardInfoReq dom
 		}
 	}
 
-	portList, ok := destination.Ports["GetBigCardInfo"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetBigCardInfoResult{
@@ -433,7 +437,9 @@ func (s *creditCardService) GetBigCardInfo(c *gin.Context, getBigCardInfoReq dom
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetBigCardInfoRequest(getBigCardInfoReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -444,10 +450,10 @@ func (s *creditCardService) GetBigCardInfo(c *gin.Context, getBigCardInfoReq dom
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -613,8 +619,9 @@ func (s *creditCardService) GetBigCardInfo(c *gin.Context, getBigCardInfoReq dom
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getBigCardInfoResponse, err := format.FormatGetBigCardInfoResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getBigCardInfoResponse:", err)
 
@@ -738,7 +745,7 @@ func (s *creditCardService) GetCardDelinquent(c *gin.Context, getCardDelinquentR
 		}
 	}
 
-	portList, ok := destination.Ports["GetCardDelinquent"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetCardDelinquentResult{
@@ -772,7 +779,9 @@ func (s *creditCardService) GetCardDelinquent(c *gin.Context, getCardDelinquentR
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetCardDelinquentRequest(getCardDelinquentReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -783,10 +792,10 @@ func (s *creditCardService) GetCardDelinquent(c *gin.Context, getCardDelinquentR
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -888,8 +897,9 @@ func (s *creditCardService) GetCardDelinquent(c *gin.Context, getCardDelinquentR
 	}
 
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getCardSalesResponse, err := format.FormatGetCardDelinquentResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getCardSalesResponse:", err)
 
@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -113,7 +114,7 @@ func (s *customerLowerService) GetCustomerInfoMobileNo(c *gin.Context, getCustom
 		}
 	}
 
-	portList, ok := destination.Ports["GetCustomerInfoMobileNo"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetCustomerInfoMobileNoResult{
@@ -147,7 +148,9 @@ func (s *customerLowerService) GetCustomerInfoMobileNo(c *gin.Context, getCustom
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetCustomerInfoMobileNoRequest(getCustomerInfoMobileNoReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -158,10 +161,10 @@ func (s *customerLowerService) GetCustomerInfoMobileNo(c *gin.Context, getCustom
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 	
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -272,8 +275,9 @@ func (s *customerLowerService) GetCustomerInfoMobileNo(c *gin.Context, getCustom
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	gustomerInfoMobileNoResponse, err := format.FormatGetCustomerInfoMobileNoResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map gustomerInfoMobileNoResponse:", err)
 
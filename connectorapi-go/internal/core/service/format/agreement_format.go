@@ -69,16 +69,16 @@ func FormatAgreeMentBillingResponse(raw string) (domain.AgreeMentBillingResponse
 
 	dueDate                          := parser.ReadInt(0,8)
 	settlementDate                   := parser.ReadInt(8,8)
-	billingAmount                    := parser.ReadFloat100(16,11)
-	minPaymentAmount                 := parser.ReadFloat100(27,11)
-	fullPaymentAmount                := parser.ReadFloat100(38,11)
-	unbilledAmount                   := parser.ReadFloat100(49,11)
-	creditShoppingFloorLimit         := parser.ReadFloat100(60,11)
-	creditShoppingOutstanding        := parser.ReadFloat100(71,11)
-	creditShoppingAvailableLimit     := parser.ReadFloat100(82,11)
-	creditCashingFloorLimit          := parser.ReadFloat100(93,11)
-	creditCashingOutstanding         := parser.ReadFloat100(104,11)
-	creditCashingAvailableLimit      := parser.ReadFloat100(115,11)
+	billingAmount                    := parser.ReadDecimal(16,11)
+	minPaymentAmount                 := parser.ReadDecimal(27,11)
+	fullPaymentAmount                := parser.ReadDecimal(38,11)
+	unbilledAmount                   := parser.ReadDecimal(49,11)
+	creditShoppingFloorLimit         := parser.ReadDecimal(60,11)
+	creditShoppingOutstanding        := parser.ReadDecimal(71,11)
+	creditShoppingAvailableLimit     := parser.ReadDecimal(82,11)
+	creditCashingFloorLimit          := parser.ReadDecimal(93,11)
+	creditCashingOutstanding         := parser.ReadDecimal(104,11)
+	creditCashingAvailableLimit      := parser.ReadDecimal(115,11)
 	installmentNo                    := parser.ReadInt(126,3)
 	installmentCurrent               := parser.ReadInt(129,3)
 	paymentHistory                   := parser.ReadString(132,36)
@@ -86,16 +86,16 @@ func FormatAgreeMentBillingResponse(raw string) (domain.AgreeMentBillingResponse
 	return domain.AgreeMentBillingResponse{
 		DueDate:                      dueDate,
 		SettlementDate:               settlementDate,
-		BillingAmount:                utils.DecimalString(billingAmount),
-		MinPaymentAmount:             utils.DecimalString(minPaymentAmount),
-		FullPaymentAmount:            utils.DecimalString(fullPaymentAmount),
-		UnbilledAmount:               utils.DecimalString(unbilledAmount),
-		CreditShoppingFloorLimit:     utils.DecimalString(creditShoppingFloorLimit),
-		CreditShoppingOutstanding:    utils.DecimalString(creditShoppingOutstanding),
-		CreditShoppingAvailableLimit: utils.DecimalString(creditShoppingAvailableLimit),
-		CreditCashingFloorLimit:      utils.DecimalString(creditCashingFloorLimit),
-		CreditCashingOutstanding:     utils.DecimalString(creditCashingOutstanding),
-		CreditCashingAvailableLimit:  utils.DecimalString(creditCashingAvailableLimit),
+		BillingAmount:                billingAmount,
+		MinPaymentAmount:             minPaymentAmount,
+		FullPaymentAmount:            fullPaymentAmount,
+		UnbilledAmount:               unbilledAmount,
+		CreditShoppingFloorLimit:     creditShoppingFloorLimit,
+		CreditShoppingOutstanding:    creditShoppingOutstanding,
+		CreditShoppingAvailableLimit: creditShoppingAvailableLimit,
+		CreditCashingFloorLimit:      creditCashingFloorLimit,
+		CreditCashingOutstanding:     creditCashingOutstanding,
+		CreditCashingAvailableLimit:  creditCashingAvailableLimit,
 		InstallmentNo:                installmentNo,
 		InstallmentCurrent:           installmentCurrent,
 		PaymentHistory:               paymentHistory,
@@ -116,27 +116,21 @@ func FormatSubmitCardApplicationResponse(raw string) (domain.SubmitCardApplicati
 	cardStart         := 126
 
 	const cardLen = 103
-	runes := []rune(data)
-	totalRunes := len(runes)
-	remainingRunes := totalRunes - cardStart
-	txtlen := remainingRunes / cardLen 
+	txtlen := (parser.Len() - cardStart) / cardLen
 	cards := make([]domain.SubmitCardListRs, 0, txtlen)
 
 	for i := 0; i < txtlen; i++ {
 		start := cardStart + i*cardLen
-		if start >= len(runes) {
-			break
-		}
-		blockRunes := utils.ReadRunes(runes, start, cardLen)
+		block := parser.Block(start, cardLen)
 		cards = append(cards, domain.SubmitCardListRs{
-			MemberTempNo: utils.ReadBlockStr(blockRunes, 0, 16),
-			CardCode:     utils.ReadBlockStr(blockRunes, 16, 2),
-			ResultCode:   utils.ReadBlockStr(blockRunes, 18, 1),
-			ReasonCode:   utils.ReadBlockStr(blockRunes, 19, 2),
-			Remark1:      utils.ReadBlockStr(blockRunes, 21, 30),
-			Remark2:      utils.ReadBlockStr(blockRunes, 51, 30),
-			MaximumLimit: utils.ReadBlockFloat100ToDecimal(blockRunes, 81, 10),
-			PINNumber:    utils.ReadBlockStr(blockRunes, 91, 12),
+			MemberTempNo: block.ReadString(0, 16),
+			CardCode:     block.ReadString(16, 2),
+			ResultCode:   block.ReadString(18, 1),
+			ReasonCode:   block.ReadString(19, 2),
+			Remark1:      block.ReadString(21, 30),
+			Remark2:      block.ReadString(51, 30),
+			MaximumLimit: block.ReadDecimal(81, 10),
+			PINNumber:    block.ReadString(91, 12),
 		})
 	}
 
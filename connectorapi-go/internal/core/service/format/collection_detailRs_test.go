@@ -88,7 +88,7 @@ func TestFormatCollectionDetailResponse_AllFieldsWithLog(t *testing.T) {
 		t.Logf("  AgreementNo: '%s'", ag.AgreementNo)
 		t.Logf("  SeqOfAgreement: %d", ag.SeqOfAgreement)
 		t.Logf("  OutsourceID: '%s'", ag.OutsourceID)
-		t.Logf("  CurrentSUEOSTotal: %f", ag.CurrentSUEOSTotal)
+		t.Logf("  CurrentSUEOSTotal: %s", ag.CurrentSUEOSTotal)
 		t.Logf("  SUEStatusDescription: '%s'", ag.SUEStatusDescription)
 	}
 
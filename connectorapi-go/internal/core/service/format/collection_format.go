@@ -2,7 +2,6 @@ package format
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	// "time"
 	//"bytes"
@@ -24,138 +23,64 @@ func FormatCollectionDetailResponse(raw string) (domain.CollectionDetailResponse
 	if len(raw) <= headerLen {
 		return domain.CollectionDetailResponse{}, fmt.Errorf("raw data too short for header, length=%d", len(raw))
 	}
-	body := raw[headerLen:]
-	runes := []rune(body)
+	parser := utils.NewFixedParser(raw[headerLen:])
 
-	readRunes := func(start, length int) []rune {
-		if start >= len(runes) {
-			return []rune{}
-		}
-		end := start + length
-		if end > len(runes) {
-			end = len(runes)
-		}
-		return runes[start:end]
-	}
-
-	readString := func(start, length int) string {
-		rs := readRunes(start, length)
-		return strings.TrimSpace(string(rs))
-	}
-
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
-
-	// readFloat := func(start, length int) float64 {
-	// 	s := readString(start, length)
-	// 	if s == "" {
-	// 		return 0
-	// 	}
-	// 	f, _ := strconv.ParseFloat(s, 64)
-	// 	return f
-	// }
-
-	idCardNo := readString(0, 20)
-	noOfAgreement := readInt(20, 2)
+	idCardNo := parser.ReadString(0, 20)
+	noOfAgreement := parser.ReadInt(20, 2)
 
 	const agreementLen = 942
 	agreementStart := 22
 	agreements := make([]domain.CollectionDetailAgreement, 0, noOfAgreement)
 
 	for i := 0; i < noOfAgreement; i++ {
-		start := agreementStart + i*agreementLen
-		if start >= len(runes) {
+		block := parser.Block(agreementStart+i*agreementLen, agreementLen)
+		if block.Empty() {
 			break
 		}
-		blockRunes := readRunes(start, agreementLen)
-
-		readBlockStr := func(startField, length int) string {
-			if startField >= len(blockRunes) {
-				return ""
-			}
-			end := startField + length
-			if end > len(blockRunes) {
-				end = len(blockRunes)
-			}
-			return strings.TrimSpace(string(blockRunes[startField:end]))
-		}
-
-		readBlockInt := func(startField, length int) int {
-			s := readBlockStr(startField, length)
-			if s == "" {
-				return 0
-			}
-			i, _ := strconv.Atoi(s)
-			return i
-		}
-
-        // readBlockFloat := func(startField, length int) domain.DecimalString {
-        //     s := readBlockStr(startField, length)
-        //     if s == "" {
-        //     return 0.00
-        // }
-        // i, _ := strconv.ParseInt(s, 10, 64)      // แปลง string เป็น int
-        // f := float64(i) / 100.0                  // หาร 100 เพื่อทศนิยม 2 ตำแหน่ง
-        // return domain.DecimalString(f)
-        // }
-
-		readBlockFloat := func(startField, length int) utils.DecimalString {
-            s := readBlockStr(startField, length)
-            i, _ := strconv.ParseInt(s, 10, 64)
-            f := float64(i) / 100.0
-            return utils.DecimalString(f)
-       }
-
 
 		agreements = append(agreements, domain.CollectionDetailAgreement{
-			AgreementNo:              readBlockStr(0, 16),
-			SeqOfAgreement:           readBlockInt(16, 2),
-			OutsourceID:              readBlockStr(18, 4),
-			OutsourceName:            readBlockStr(22, 30),
-			BlockCode:                readBlockStr(52, 2),
-			CurrentSUEOSPrincipalNet: readBlockFloat(54, 10),
-			CurrentSUEOSPrincipalVAT: readBlockFloat(64, 10),
-			CurrentSUEOSInterestNet:  readBlockFloat(74, 10),
-			CurrentSUEOSInterestVAT:  readBlockFloat(84, 10),
-			CurrentSUEOSPenalty:      readBlockFloat(94, 9),
-			CurrentSUEOSHDCharge:     readBlockFloat(103, 9),
-			CurrentSUEOSOtherFee:     readBlockFloat(112, 9),
-			CurrentSUEOSTotal:        readBlockFloat(121, 10),
-			TotalPaymentAmount:       readBlockFloat(131, 10),
-			LastPaymentDate:          readBlockInt(141, 8),
-			SUESeqNo:                 readBlockInt(149, 2),
-			BeginSUEOSPrincipalNet:   readBlockFloat(151, 10),
-			BeginSUEOSPrincipalVAT:   readBlockFloat(161, 10),
-			BeginSUEOSInterestNet:    readBlockFloat(171, 10),
-			BeginSUEOSInterestVAT:    readBlockFloat(181, 10),
-			BeginSUEOSPenalty:        readBlockFloat(191, 10),
-			BeginSUEOSHDCharge:       readBlockFloat(201, 9),
-			BeginSUEOSOtherFee:       readBlockFloat(210, 9),
-			BeginSUEOSTotal:          readBlockFloat(219, 10),
-			SUEStatus:                readBlockInt(229, 2),
-			SUEStatusDescription:     readBlockStr(231, 30),
-			BlackCaseNo:              readBlockStr(261, 15),
-			BlackCaseDate:            readBlockInt(276, 8),
-			RedCaseNo:                readBlockStr(284, 15),
-			RedCaseDate:              readBlockInt(299, 8),
-			CourtCode:                readBlockStr(307, 4),
-			CourtName:                readBlockStr(311, 30),
-			JudgmentDate:             readBlockInt(341, 8),
-			JudgmentResultCode:       readBlockInt(349, 1),
-			JudgmentResultDescription: readBlockStr(350, 40),
-			JudgmentDetail:           readBlockStr(390, 500),
-			ExpectDate:               readBlockInt(890, 8),
-			AssetPrice:               readBlockFloat(898, 10),
-			JudgeAmount:              readBlockFloat(908, 10),
-			NoOfInstallment:          readBlockStr(918, 3),
-			InstallmentAmount:        readBlockFloat(921, 10),
-			TotalCurrentPerSUESeqNo:  readBlockFloat(931, 11),
+			AgreementNo:              block.ReadString(0, 16),
+			SeqOfAgreement:           block.ReadInt(16, 2),
+			OutsourceID:              block.ReadString(18, 4),
+			OutsourceName:            block.ReadString(22, 30),
+			BlockCode:                block.ReadString(52, 2),
+			CurrentSUEOSPrincipalNet: block.ReadDecimal(54, 10),
+			CurrentSUEOSPrincipalVAT: block.ReadDecimal(64, 10),
+			CurrentSUEOSInterestNet:  block.ReadDecimal(74, 10),
+			CurrentSUEOSInterestVAT:  block.ReadDecimal(84, 10),
+			CurrentSUEOSPenalty:      block.ReadDecimal(94, 9),
+			CurrentSUEOSHDCharge:     block.ReadDecimal(103, 9),
+			CurrentSUEOSOtherFee:     block.ReadDecimal(112, 9),
+			CurrentSUEOSTotal:        block.ReadDecimal(121, 10),
+			TotalPaymentAmount:       block.ReadDecimal(131, 10),
+			LastPaymentDate:          block.ReadInt(141, 8),
+			SUESeqNo:                 block.ReadInt(149, 2),
+			BeginSUEOSPrincipalNet:   block.ReadDecimal(151, 10),
+			BeginSUEOSPrincipalVAT:   block.ReadDecimal(161, 10),
+			BeginSUEOSInterestNet:    block.ReadDecimal(171, 10),
+			BeginSUEOSInterestVAT:    block.ReadDecimal(181, 10),
+			BeginSUEOSPenalty:        block.ReadDecimal(191, 10),
+			BeginSUEOSHDCharge:       block.ReadDecimal(201, 9),
+			BeginSUEOSOtherFee:       block.ReadDecimal(210, 9),
+			BeginSUEOSTotal:          block.ReadDecimal(219, 10),
+			SUEStatus:                block.ReadInt(229, 2),
+			SUEStatusDescription:     block.ReadString(231, 30),
+			BlackCaseNo:              block.ReadString(261, 15),
+			BlackCaseDate:            block.ReadInt(276, 8),
+			RedCaseNo:                block.ReadString(284, 15),
+			RedCaseDate:              block.ReadInt(299, 8),
+			CourtCode:                block.ReadString(307, 4),
+			CourtName:                block.ReadString(311, 30),
+			JudgmentDate:             block.ReadInt(341, 8),
+			JudgmentResultCode:       block.ReadInt(349, 1),
+			JudgmentResultDescription: block.ReadString(350, 40),
+			JudgmentDetail:           block.ReadString(390, 500),
+			ExpectDate:               block.ReadInt(890, 8),
+			AssetPrice:               block.ReadDecimal(898, 10),
+			JudgeAmount:              block.ReadDecimal(908, 10),
+			NoOfInstallment:          block.ReadString(918, 3),
+			InstallmentAmount:        block.ReadDecimal(921, 10),
+			TotalCurrentPerSUESeqNo:  block.ReadDecimal(931, 11),
 		})
 	}
 
@@ -12,7 +12,7 @@ func TestFormatCollectionLogResponse(t *testing.T) {
 	// body ต้องมีอย่างน้อย 36 ตัว (20 + 16)
 	body := "12345678901234567890ABCDEFGHIJKLMNO1" // 36 ตัว
 	longBody := body + "EXTRA"                     // มากกว่า 36 ตัว
-	trimBody := "  1234567890  1234567890123456  " // มีช่องว่าง 36 ตัว
+	trimBody := "  1234567890        " + "1234567890123456" // มีช่องว่าง 36 ตัว
 
 	tests := []struct {
 		name      string
@@ -141,8 +141,8 @@ func FormatGetCustomerInfoResponse003(raw string) (domain.GetCustomerInfoRespons
 	otherJobDescription              := parser.ReadString(551, 50)
 	workingPeriod                    := parser.ReadString(601, 4)
 	employmentStatus                 := parser.ReadString(605, 2)
-	salary                           := parser.ReadFloat100(607, 11)
-	otherIncome                      := parser.ReadFloat100(618, 11)
+	salary                           := parser.ReadDecimal(607, 11)
+	otherIncome                      := parser.ReadDecimal(618, 11)
 	otherIncomeResource              := parser.ReadString(629, 1)
 	otherIncomeResourceDescription   := parser.ReadString(630, 20)
 	sourceOfOtherIncomeCountry       := parser.ReadString(650, 3)
@@ -223,8 +223,8 @@ func FormatGetCustomerInfoResponse003(raw string) (domain.GetCustomerInfoRespons
 		OtherJobDescription:              otherJobDescription,
 		WorkingPeriod:                    workingPeriod,
 		EmploymentStatus:                 employmentStatus,
-		Salary:                           utils.DecimalString(salary),
-		OtherIncome:                      utils.DecimalString(otherIncome),
+		Salary:                           salary,
+		OtherIncome:                      otherIncome,
 		OtherIncomeResource:              otherIncomeResource,
 		OtherIncomeResourceDescription:   otherIncomeResourceDescription,
 		SourceOfOtherIncomeCountry:       sourceOfOtherIncomeCountry,
@@ -348,94 +348,30 @@ func FormatCheckApplyCondition2ndCardResponse(raw string) (domain.CheckApplyCond
 	if len(raw) <= headerLen {
 		return domain.CheckApplyCondition2ndCardResponse{}, fmt.Errorf("raw data too short for header, length=%d", len(raw))
 	}
-	body := raw[headerLen:]
-	runes := []rune(body)
+	parser := utils.NewFixedParser(raw[headerLen:])
 
-	readRunes := func(start, length int) []rune {
-		if start >= len(runes) {
-			return []rune{}
-		}
-		end := start + length
-		if end > len(runes) {
-			end = len(runes)
-		}
-		return runes[start:end]
-	}
-
-	readString := func(start, length int) string {
-		rs := readRunes(start, length)
-		return strings.TrimSpace(string(rs))
-	}
-
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
-
-	// readFloat := func(start, length int) float64 {
-	// 	s := readString(start, length)
-	// 	if s == "" {
-	// 		return 0
-	// 	}
-	// 	f, _ := strconv.ParseFloat(s, 64)
-	// 	return f
-	// }
-
-	idCardNo         := readString(0, 20)
-	maximumCR        := readInt(20, 2)
-	haveCardCR       := readInt(22, 2)
-	maximumYC        := readInt(24, 2)
-	haveCardYC       := readInt(26, 2)
-	totalOfApplyCard := readInt(28, 2)
+	idCardNo         := parser.ReadString(0, 20)
+	maximumCR        := parser.ReadInt(20, 2)
+	haveCardCR       := parser.ReadInt(22, 2)
+	maximumYC        := parser.ReadInt(24, 2)
+	haveCardYC       := parser.ReadInt(26, 2)
+	totalOfApplyCard := parser.ReadInt(28, 2)
 
 	const agreementLen = 56
 	agreementStart := 30
 	agreements := make([]domain.CheckApply2ndCardRsOBJ, 0, totalOfApplyCard)
 
 	for i := 0; i < totalOfApplyCard; i++ {
-		start := agreementStart + i*agreementLen
-		if start >= len(runes) {
+		block := parser.Block(agreementStart+i*agreementLen, agreementLen)
+		if block.Empty() {
 			break
 		}
-		blockRunes := readRunes(start, agreementLen)
-
-		readBlockStr := func(startField, length int) string {
-			if startField >= len(blockRunes) {
-				return ""
-			}
-			end := startField + length
-			if end > len(blockRunes) {
-				end = len(blockRunes)
-			}
-			return strings.TrimSpace(string(blockRunes[startField:end]))
-		}
-
-		// readBlockInt := func(startField, length int) int {
-		// 	s := readBlockStr(startField, length)
-		// 	if s == "" {
-		// 		return 0
-		// 	}
-		// 	i, _ := strconv.Atoi(s)
-		// 	return i
-		// }
-
-	// 	readBlockFloat := func(startField, length int) utils.DecimalString {
-    //         s := readBlockStr(startField, length)
-    //         i, _ := strconv.ParseInt(s, 10, 64)
-    //         f := float64(i) / 100.0
-    //         return utils.DecimalString(f)
-    //    }
-
 
 		agreements = append(agreements, domain.CheckApply2ndCardRsOBJ{
-			CardCode:              readBlockStr(0, 2),
-			ResultCode:            readBlockStr(2, 2),
-			ReasonCode:            readBlockStr(4, 2),
-			ReasonDescription:     readBlockStr(6, 50),
+			CardCode:              block.ReadString(0, 2),
+			ResultCode:            block.ReadString(2, 2),
+			ReasonCode:            block.ReadString(4, 2),
+			ReasonDescription:     block.ReadString(6, 50),
 		})
 	}
 
@@ -0,0 +1,94 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mockBody returns n characters of digits with a Thai character every seventh position,
+// so that offsets go through multi-byte characters as they do in production.
+func mockBody(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i%7 == 6 {
+			b.WriteString("ก")
+		} else {
+			b.WriteByte(byte('0' + i%10))
+		}
+	}
+	return b.String()
+}
+
+// mockMessage returns a System I response with a body of n characters.
+func mockMessage(n int) string {
+	return strings.Repeat(" ", 123) + mockBody(n)
+}
+
+// mockCollectionDetail returns a CollectionDetail response with the given number of agreements.
+func mockCollectionDetail(agreements int) string {
+	return strings.Repeat(" ", 123) + mockBody(20) + fmt.Sprintf("%02d", agreements) + mockBody(agreements*942)
+}
+
+var parserCases = []struct {
+	name      string
+	raw       string
+	maxAllocs float64
+	parse     func(raw string) error
+}{
+	{"CollectionDetail99", mockCollectionDetail(99), 1, func(raw string) error {
+		_, err := FormatCollectionDetailResponse(raw)
+		return err
+	}},
+	{"DashboardDetailNew", mockMessage(30 + 20*249), 2, func(raw string) error {
+		_, err := FormatDashboardDetailResponse(raw, false)
+		return err
+	}},
+	{"DashboardDetailOld", mockMessage(30 + 20*227), 1, func(raw string) error {
+		_, err := FormatDashboardDetailResponse(raw, true)
+		return err
+	}},
+	{"GetCustomerInfo001", mockMessage(132), 0, func(raw string) error {
+		_, err := FormatGetCustomerInfoResponse001(raw)
+		return err
+	}},
+	{"GetCustomerInfo003", mockMessage(1481), 0, func(raw string) error {
+		_, err := FormatGetCustomerInfoResponse003(raw)
+		return err
+	}},
+	{"GetCustomerInfo004", mockMessage(142), 0, func(raw string) error {
+		_, err := FormatGetCustomerInfoResponse004(raw)
+		return err
+	}},
+}
+
+// TestParserAllocs guards the parsers against going back to allocating per field: the
+// fields are substrings of the message, so only the lists of blocks are allocated.
+func TestParserAllocs(t *testing.T) {
+	for _, tc := range parserCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.parse(tc.raw); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			allocs := testing.AllocsPerRun(100, func() { _ = tc.parse(tc.raw) })
+			t.Logf("%s: %.0f allocs", tc.name, allocs)
+			if allocs > tc.maxAllocs {
+				t.Errorf("%s allocates %.0f times per response, want at most %.0f", tc.name, allocs, tc.maxAllocs)
+			}
+		})
+	}
+}
+
+func BenchmarkParsers(b *testing.B) {
+	for _, tc := range parserCases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(tc.raw)))
+			for i := 0; i < b.N; i++ {
+				if err := tc.parse(tc.raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -26,8 +26,8 @@ func FormatDashboardSummaryResponse(raw string, flagOldFormatReq bool) (domain.D
 	const dataLen = 354
 	var idCardNo string
 	var aeonID string
-	var remainMinimumPayment float64
-    var remainFullPayment float64
+	var remainMinimumPayment utils.DecimalString
+    var remainFullPayment utils.DecimalString
 	var remainMinPtr *utils.DecimalString
 	var remainFullPtr *utils.DecimalString
 	var counterNo string
@@ -56,30 +56,30 @@ func FormatDashboardSummaryResponse(raw string, flagOldFormatReq bool) (domain.D
 	mobileNo                     := parser.ReadString(80,15)
 	dueDate                      := parser.ReadInt(95,8)
 	// AS400ResponseCode := int 2 not use แต่ต้องตัด
-	creditShoppingFloorLimit     := parser.ReadFloat100(105,11)
-	creditShoppingOutstanding    := parser.ReadFloat100(116,11)
-	creditShoppingAvailableLimit := parser.ReadFloat100(127,11)
-	ceditCashingFloorLimit       := parser.ReadFloat100(138,11)
-	creditCashingOutstanding     := parser.ReadFloat100(149,11)
-	creditCashingAvailableLimit  := parser.ReadFloat100(160,11)
-	yourCashFloorLimit           := parser.ReadFloat100(171,11)
-	yourCashOutstanding          := parser.ReadFloat100(182,11)
-	yourCashAvailableLimit       := parser.ReadFloat100(193,11)
-	ropShoppingFloorLimit        := parser.ReadFloat100(204,11)
-	ropShoppingOutstanding       := parser.ReadFloat100(215,11)
-	ropShoppingAvailableLimit    := parser.ReadFloat100(226,11)
-	ropCashingFloorLimit         := parser.ReadFloat100(237,11)
-	ropCashingOutstanding        := parser.ReadFloat100(248,11)
-	ropCashingAvailableLimit     := parser.ReadFloat100(259,11)
-	totalMinimumPayment          := parser.ReadFloat100(270,11)
-	totalFullPayment             := parser.ReadFloat100(281,11)
-	totalPaidAmount              := parser.ReadFloat100(292,11)
+	creditShoppingFloorLimit     := parser.ReadDecimal(105,11)
+	creditShoppingOutstanding    := parser.ReadDecimal(116,11)
+	creditShoppingAvailableLimit := parser.ReadDecimal(127,11)
+	ceditCashingFloorLimit       := parser.ReadDecimal(138,11)
+	creditCashingOutstanding     := parser.ReadDecimal(149,11)
+	creditCashingAvailableLimit  := parser.ReadDecimal(160,11)
+	yourCashFloorLimit           := parser.ReadDecimal(171,11)
+	yourCashOutstanding          := parser.ReadDecimal(182,11)
+	yourCashAvailableLimit       := parser.ReadDecimal(193,11)
+	ropShoppingFloorLimit        := parser.ReadDecimal(204,11)
+	ropShoppingOutstanding       := parser.ReadDecimal(215,11)
+	ropShoppingAvailableLimit    := parser.ReadDecimal(226,11)
+	ropCashingFloorLimit         := parser.ReadDecimal(237,11)
+	ropCashingOutstanding        := parser.ReadDecimal(248,11)
+	ropCashingAvailableLimit     := parser.ReadDecimal(259,11)
+	totalMinimumPayment          := parser.ReadDecimal(270,11)
+	totalFullPayment             := parser.ReadDecimal(281,11)
+	totalPaidAmount              := parser.ReadDecimal(292,11)
 	pendingPaymentStatus         := parser.ReadString(303,2)
 	if !flagOldFormatReq {
-		remainMinimumPayment     = parser.ReadFloat100(305,11)
-    	remainFullPayment        = parser.ReadFloat100(316,11)
-		remainMin                := utils.DecimalString(remainMinimumPayment)
-		remainFull               := utils.DecimalString(remainFullPayment)
+		remainMinimumPayment     = parser.ReadDecimal(305,11)
+    	remainFullPayment        = parser.ReadDecimal(316,11)
+		remainMin                := remainMinimumPayment
+		remainFull               := remainFullPayment
 		remainMinPtr             = &remainMin
 		remainFullPtr            = &remainFull
 		counterNo                = parser.ReadString(327,4)
@@ -98,22 +98,16 @@ func FormatDashboardSummaryResponse(raw string, flagOldFormatReq bool) (domain.D
 	}
 
 	const termLen = 32
-	runes := []rune(data)
-	totalRunes := len(runes)
-	remainingRunes := totalRunes - termStart
-	txtlen := remainingRunes / termLen 
+	txtlen := (parser.Len() - termStart) / termLen
 	terms := make([]domain.DBDetailTermsListRq, 0, txtlen)
 
 	for i := 0; i < txtlen; i++ {
 		start := termStart + i*termLen
-		if start >= len(runes) {
-			break
-		}
-		blockRunes := utils.ReadRunes(runes, start, termLen)
+		block := parser.Block(start, termLen)
 		terms = append(terms, domain.DBDetailTermsListRq{
-			TermsType:         utils.ReadBlockStr(blockRunes, 0, 20),
-			TermsVersion:      utils.ReadBlockStr(blockRunes, 20, 10),
-			TermsAcceptStatus: utils.ReadBlockStr(blockRunes, 30, 2),
+			TermsType:         block.ReadString(0, 20),
+			TermsVersion:      block.ReadString(20, 10),
+			TermsAcceptStatus: block.ReadString(30, 2),
 		})
 	}
 
@@ -132,24 +126,24 @@ func FormatDashboardSummaryResponse(raw string, flagOldFormatReq bool) (domain.D
 		NameEN:                       nameEN,
 		MobileNo:                     mobileNo,
 		DueDate:                      dueDate,
-		CreditShoppingFloorLimit:     utils.DecimalString(creditShoppingFloorLimit),
-		CreditShoppingOutstanding:    utils.DecimalString(creditShoppingOutstanding),
-		CreditShoppingAvailableLimit: utils.DecimalString(creditShoppingAvailableLimit),
-		CreditCashingFloorLimit:      utils.DecimalString(ceditCashingFloorLimit),
-		CreditCashingOutstanding:     utils.DecimalString(creditCashingOutstanding),
-		CreditCashingAvailableLimit:  utils.DecimalString(creditCashingAvailableLimit),
-		YourCashFloorLimit:           utils.DecimalString(yourCashFloorLimit),
-		YourCashOutstanding:          utils.DecimalString(yourCashOutstanding),
-		YourCashAvailableLimit:       utils.DecimalString(yourCashAvailableLimit),
-		ROPShoppingFloorLimit:        utils.DecimalString(ropShoppingFloorLimit),
-		ROPShoppingOutstanding:       utils.DecimalString(ropShoppingOutstanding),
-		ROPShoppingAvailableLimit:    utils.DecimalString(ropShoppingAvailableLimit),
-		ROPCashingFloorLimit:         utils.DecimalString(ropCashingFloorLimit),
-		ROPCashingOutstanding:        utils.DecimalString(ropCashingOutstanding),
-		ROPCashingAvailableLimit:     utils.DecimalString(ropCashingAvailableLimit),
-		TotalMinimumPayment:          utils.DecimalString(totalMinimumPayment),
-		TotalFullPayment:             utils.DecimalString(totalFullPayment),
-		TotalPaidAmount:              utils.DecimalString(totalPaidAmount),
+		CreditShoppingFloorLimit:     creditShoppingFloorLimit,
+		CreditShoppingOutstanding:    creditShoppingOutstanding,
+		CreditShoppingAvailableLimit: creditShoppingAvailableLimit,
+		CreditCashingFloorLimit:      ceditCashingFloorLimit,
+		CreditCashingOutstanding:     creditCashingOutstanding,
+		CreditCashingAvailableLimit:  creditCashingAvailableLimit,
+		YourCashFloorLimit:           yourCashFloorLimit,
+		YourCashOutstanding:          yourCashOutstanding,
+		YourCashAvailableLimit:       yourCashAvailableLimit,
+		ROPShoppingFloorLimit:        ropShoppingFloorLimit,
+		ROPShoppingOutstanding:       ropShoppingOutstanding,
+		ROPShoppingAvailableLimit:    ropShoppingAvailableLimit,
+		ROPCashingFloorLimit:         ropCashingFloorLimit,
+		ROPCashingOutstanding:        ropCashingOutstanding,
+		ROPCashingAvailableLimit:     ropCashingAvailableLimit,
+		TotalMinimumPayment:          totalMinimumPayment,
+		TotalFullPayment:             totalFullPayment,
+		TotalPaidAmount:              totalPaidAmount,
 		PendingPaymentStatus:         pendingPaymentStatus,
 		RemainMinimumPayment:         remainMinPtr,
 		RemainFullPayment:            remainFullPtr,
@@ -177,10 +171,7 @@ func FormatDashboardDetailResponse(raw string, flagOldFormatReq bool) (domain.Da
 	const dataLen = 30
 	var idCardNo string
 	var aeonID string
-	var remainMinimumPayment float64
-    var remainFullPayment float64
-	var remainMinPtr *utils.DecimalString
-	var remainFullPtr *utils.DecimalString
+	var remains []utils.DecimalString
 	var dbDetailLen int
 
 	if len(raw) <= headerLen {
@@ -203,67 +194,61 @@ func FormatDashboardDetailResponse(raw string, flagOldFormatReq bool) (domain.Da
 	dueDate := parser.ReadInt(20,8)
 	// AS400ResponseCode := int 2 not use แต่ต้องตัด
 	dbDetailStart := 30
-	runes := []rune(data)
-	totalRunes := len(runes)
-	remainingRunes := totalRunes - dbDetailStart
-	txtlen := remainingRunes / dbDetailLen 
+	txtlen := (parser.Len() - dbDetailStart) / dbDetailLen
 	dbDetails := make([]domain.DBDetailListRs, 0, txtlen)
+	if !flagOldFormatReq {
+		// the remaining payments of all cards share one array rather than one allocation each
+		remains = make([]utils.DecimalString, 2*txtlen)
+	}
 
 	for i := 0; i < txtlen; i++ {
 		start := dbDetailStart + i*dbDetailLen
-		if start >= len(runes) {
-			break
-		}
-		blockRunes := utils.ReadRunes(runes, start, dbDetailLen)
+		block := parser.Block(start, dbDetailLen)
 		detail := domain.DBDetailListRs{
-			CreditCardNo:         utils.ReadBlockStr(blockRunes, 0, 16),
-			CardName:             utils.ReadBlockStr(blockRunes, 16, 30),
-			ProductType:          utils.ReadBlockStr(blockRunes, 46, 2),
-			CardCode:             utils.ReadBlockStr(blockRunes, 48, 2),
-			ATMauthorize:         utils.ReadBlockStr(blockRunes, 50, 14),
-			CardStatus:           utils.ReadBlockStr(blockRunes, 64, 16),
-			MinimumPaymentAmount: utils.ReadBlockFloat100ToDecimal(blockRunes, 80, 11),
-			FullPaymentAmount:    utils.ReadBlockFloat100ToDecimal(blockRunes, 91, 11),
-			PaidAmount:           utils.ReadBlockFloat100ToDecimal(blockRunes, 102, 11),
+			CreditCardNo:         block.ReadString(0, 16),
+			CardName:             block.ReadString(16, 30),
+			ProductType:          block.ReadString(46, 2),
+			CardCode:             block.ReadString(48, 2),
+			ATMauthorize:         block.ReadString(50, 14),
+			CardStatus:           block.ReadString(64, 16),
+			MinimumPaymentAmount: block.ReadDecimal(80, 11),
+			FullPaymentAmount:    block.ReadDecimal(91, 11),
+			PaidAmount:           block.ReadDecimal(102, 11),
 		}
 		if !flagOldFormatReq {
-			remainMinimumPayment                = parser.ReadFloat100(113,11)
-			remainFullPayment                   = parser.ReadFloat100(124,11)
-			remainMin                           := utils.DecimalString(remainMinimumPayment)
-			remainFull                          := utils.DecimalString(remainFullPayment)
-			remainMinPtr                        = &remainMin
-			remainFullPtr                       = &remainFull
-			detail.RemainMinimumPayment         = remainMinPtr
-			detail.RemainFullPayment            = remainFullPtr
-			detail.CreditShoppingFloorLimit     = utils.ReadBlockFloat100ToDecimal(blockRunes, 135, 11)
-			detail.CreditShoppingOutstanding    = utils.ReadBlockFloat100ToDecimal(blockRunes, 146, 11)
-			detail.CreditShoppingAvailableLimit = utils.ReadBlockFloat100ToDecimal(blockRunes, 157, 11)
-			detail.CreditCashingFloorLimit      = utils.ReadBlockFloat100ToDecimal(blockRunes, 168, 11)
-			detail.CreditCashingOutstanding     = utils.ReadBlockFloat100ToDecimal(blockRunes, 179, 11)
-			detail.CreditCashingAvailableLimit  = utils.ReadBlockFloat100ToDecimal(blockRunes, 190, 11)
-			detail.AvailablePoint               = utils.ReadBlockFloat100ToDecimal(blockRunes, 201, 11)
-			detail.BillingAmount                = utils.ReadBlockFloat100ToDecimal(blockRunes, 212, 11)
-			detail.UnbilledAmount               = utils.ReadBlockFloat100ToDecimal(blockRunes, 223, 11)
-			detail.InstallmentNo                = utils.ReadBlockInt(blockRunes, 234, 3)
-			detail.InstallmentCurrent           = utils.ReadBlockInt(blockRunes, 237, 3)
-			detail.DigitalCardFlag              = utils.ReadBlockStr(blockRunes,240, 1)
-			detail.ApplicationDate              = utils.ReadBlockInt(blockRunes, 241, 8)
+			remains[2*i]                        = block.ReadDecimal(113,11)
+			remains[2*i+1]                      = block.ReadDecimal(124,11)
+			detail.RemainMinimumPayment         = &remains[2*i]
+			detail.RemainFullPayment            = &remains[2*i+1]
+			detail.CreditShoppingFloorLimit     = block.ReadDecimal(135, 11)
+			detail.CreditShoppingOutstanding    = block.ReadDecimal(146, 11)
+			detail.CreditShoppingAvailableLimit = block.ReadDecimal(157, 11)
+			detail.CreditCashingFloorLimit      = block.ReadDecimal(168, 11)
+			detail.CreditCashingOutstanding     = block.ReadDecimal(179, 11)
+			detail.CreditCashingAvailableLimit  = block.ReadDecimal(190, 11)
+			detail.AvailablePoint               = block.ReadDecimal(201, 11)
+			detail.BillingAmount                = block.ReadDecimal(212, 11)
+			detail.UnbilledAmount               = block.ReadDecimal(223, 11)
+			detail.InstallmentNo                = block.ReadInt(234, 3)
+			detail.InstallmentCurrent           = block.ReadInt(237, 3)
+			detail.DigitalCardFlag              = block.ReadString(240, 1)
+			detail.ApplicationDate              = block.ReadInt(241, 8)
 		} else {
 			detail.RemainMinimumPayment         = nil
 			detail.RemainFullPayment            = nil
-			detail.CreditShoppingFloorLimit     = utils.ReadBlockFloat100ToDecimal(blockRunes, 113, 11)
-			detail.CreditShoppingOutstanding    = utils.ReadBlockFloat100ToDecimal(blockRunes, 124, 11)
-			detail.CreditShoppingAvailableLimit = utils.ReadBlockFloat100ToDecimal(blockRunes, 135, 11)
-			detail.CreditCashingFloorLimit      = utils.ReadBlockFloat100ToDecimal(blockRunes, 146, 11)
-			detail.CreditCashingOutstanding     = utils.ReadBlockFloat100ToDecimal(blockRunes, 157, 11)
-			detail.CreditCashingAvailableLimit  = utils.ReadBlockFloat100ToDecimal(blockRunes, 168, 11)
-			detail.AvailablePoint               = utils.ReadBlockFloat100ToDecimal(blockRunes, 179, 11)
-			detail.BillingAmount                = utils.ReadBlockFloat100ToDecimal(blockRunes, 190, 11)
-			detail.UnbilledAmount               = utils.ReadBlockFloat100ToDecimal(blockRunes, 201, 11)
-			detail.InstallmentNo                = utils.ReadBlockInt(blockRunes, 212, 3)
-			detail.InstallmentCurrent           = utils.ReadBlockInt(blockRunes, 215, 3)
-			detail.DigitalCardFlag              = utils.ReadBlockStr(blockRunes, 218, 1)
-			detail.ApplicationDate              = utils.ReadBlockInt(blockRunes, 219, 8)
+			detail.CreditShoppingFloorLimit     = block.ReadDecimal(113, 11)
+			detail.CreditShoppingOutstanding    = block.ReadDecimal(124, 11)
+			detail.CreditShoppingAvailableLimit = block.ReadDecimal(135, 11)
+			detail.CreditCashingFloorLimit      = block.ReadDecimal(146, 11)
+			detail.CreditCashingOutstanding     = block.ReadDecimal(157, 11)
+			detail.CreditCashingAvailableLimit  = block.ReadDecimal(168, 11)
+			detail.AvailablePoint               = block.ReadDecimal(179, 11)
+			detail.BillingAmount                = block.ReadDecimal(190, 11)
+			detail.UnbilledAmount               = block.ReadDecimal(201, 11)
+			detail.InstallmentNo                = block.ReadInt(212, 3)
+			detail.InstallmentCurrent           = block.ReadInt(215, 3)
+			detail.DigitalCardFlag              = block.ReadString(218, 1)
+			detail.ApplicationDate              = block.ReadInt(219, 8)
 		}
 		dbDetails = append(dbDetails, detail)
 	}
@@ -304,28 +289,22 @@ func FormatMobileFullPanResponse(raw string) (domain.MobileFullPanResponse, erro
 	cardStart := 24
 
 	const cardLen = 61
-	runes := []rune(data)
-	totalRunes := len(runes)
-	remainingRunes := totalRunes - cardStart
-	txtlen := remainingRunes / cardLen 
+	txtlen := (parser.Len() - cardStart) / cardLen
 	cards := make([]domain.MobileCardListRs, 0, txtlen)
 
 	for i := 0; i < txtlen; i++ {
 		start := cardStart + i*cardLen
-		if start >= len(runes) {
-			break
-		}
-		blockRunes := utils.ReadRunes(runes, start, cardLen)
+		block := parser.Block(start, cardLen)
 		cards = append(cards, domain.MobileCardListRs{
-			CardNo:           utils.ReadBlockStr(blockRunes, 0, 16),
-			CardType:         utils.ReadBlockStr(blockRunes, 46, 2),
-			CardCode:         utils.ReadBlockStr(blockRunes, 48, 2),
-			HoldCode:         utils.ReadBlockStr(blockRunes, 50, 2),
-			ExpireDate:       utils.ReadBlockInt(blockRunes, 52, 8),
+			CardNo:           block.ReadString(0, 16),
+			CardType:         block.ReadString(46, 2),
+			CardCode:         block.ReadString(48, 2),
+			HoldCode:         block.ReadString(50, 2),
+			ExpireDate:       block.ReadInt(52, 8),
 			SendMode:         "",
 			FirstEmbossDate:  0,
 			FirstConfirmDate: 0,
-			DigitalCardFlag:  utils.ReadBlockStr(blockRunes, 60, 1),
+			DigitalCardFlag:  block.ReadString(60, 1),
 
 		})
 	}
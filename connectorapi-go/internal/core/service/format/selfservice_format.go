@@ -3,7 +3,6 @@ package format
 import (
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 	//"bytes"
 
@@ -34,63 +33,25 @@ func FormatMyCardResponseNormal(raw string) (domain.MyCardResponseNormal, error)
 	if len(raw) <= headerLen {
 		return domain.MyCardResponseNormal{}, fmt.Errorf("raw data too short for header, length=%d", len(raw))
 	}
-	body := raw[headerLen:]
-	runes := []rune(body)
+	parser := utils.NewFixedParser(raw[headerLen:])
 
-	readRunes := func(start, length int) []rune {
-		if start >= len(runes) {
-			return []rune{}
-		}
-		end := start + length
-		if end > len(runes) {
-			end = len(runes)
-		}
-		return runes[start:end]
-	}
-
-	readString := func(start, length int) string {
-		rs := readRunes(start, length)
-		return strings.TrimSpace(string(rs))
-	}
-
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
-
-	idCardNo                  := readString(0, 20)
-	totalCreditCard           := readInt(20, 4)
+	idCardNo                  := parser.ReadString(0, 20)
+	totalCreditCard           := parser.ReadInt(20, 4)
 
 	const agreementLen = 61
 	agreementStart := 24
 	agreements := make([]domain.MyCardListNormal, 0, totalCreditCard)
 
 	for i := 0; i < totalCreditCard; i++ {
-		start := agreementStart + i*agreementLen
-		if start >= len(runes) {
+		block := parser.Block(agreementStart+i*agreementLen, agreementLen)
+		if block.Empty() {
 			break
 		}
-		blockRunes := readRunes(start, agreementLen)
 
-		readBlockStr := func(startField, length int) string {
-			if startField >= len(blockRunes) {
-				return ""
-			}
-			end := startField + length
-			if end > len(blockRunes) {
-				end = len(blockRunes)
-			}
-			return strings.TrimSpace(string(blockRunes[startField:end]))
-		}
-
-		rawCreditCardNo := readBlockStr(0, 16)
-		cardStatus := readBlockStr(50, 2)
-		expireDateStr := readBlockStr(52, 8)
-		digitalCardFlag := readBlockStr(60, 1)
+		rawCreditCardNo := block.ReadString(0, 16)
+		cardStatus := block.ReadString(50, 2)
+		expireDateStr := block.ReadString(52, 8)
+		digitalCardFlag := block.ReadString(60, 1)
 
 		maskedCreditCardNo := rawCreditCardNo
 		if len(rawCreditCardNo) == 16 {
@@ -115,12 +76,12 @@ func FormatMyCardResponseNormal(raw string) (domain.MyCardResponseNormal, error)
 
 		agreements = append(agreements, domain.MyCardListNormal{
 			CreditCardNo:        maskedCreditCardNo,
-			CardName:            readBlockStr(16, 30),
-			ProductType:         readBlockStr(46, 2),
-			BusinessCode:        readBlockStr(48, 2),
+			CardName:            block.ReadString(16, 30),
+			ProductType:         block.ReadString(46, 2),
+			BusinessCode:        block.ReadString(48, 2),
 			CardStatus:          status,
-			ExpireDate:          readBlockStr(52, 8),
-			// DYCA:                readBlockStr(60, 1),
+			ExpireDate:          block.ReadString(52, 8),
+			// DYCA:                block.ReadString(60, 1),
 			DigitalCardFlag:     finalDigitalCardFlag,
 		})
 	}
@@ -137,72 +98,25 @@ func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
 	if len(raw) <= headerLen {
 		return domain.MyCardResponseAll{}, fmt.Errorf("raw data too short for header, length=%d", len(raw))
 	}
-	body := raw[headerLen:]
-	runes := []rune(body)
-
-	readRunes := func(start, length int) []rune {
-		if start >= len(runes) {
-			return []rune{}
-		}
-		end := start + length
-		if end > len(runes) {
-			end = len(runes)
-		}
-		return runes[start:end]
-	}
-
-	readString := func(start, length int) string {
-		rs := readRunes(start, length)
-		return strings.TrimSpace(string(rs))
-	}
-	
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
+	parser := utils.NewFixedParser(raw[headerLen:])
 
-	idCardNo               := readString(0, 20)
-	customerNameEN         := readString(20, 30)
-	customerNameTH         := readString(50, 30)
-	totalCreditCard     := readInt(80, 3)
+	idCardNo               := parser.ReadString(0, 20)
+	customerNameEN         := parser.ReadString(20, 30)
+	customerNameTH         := parser.ReadString(50, 30)
+	totalCreditCard     := parser.ReadInt(80, 3)
 
 	const agreementLen = 68
 	agreementStart := 83
 	agreements := make([]domain.MyCardListAll, 0, totalCreditCard)
 
 	for i := 0; i < totalCreditCard; i++ {
-	start := agreementStart + i*agreementLen
-	if start >= len(runes) {
+	block := parser.Block(agreementStart+i*agreementLen, agreementLen)
+	if block.Empty() {
 		break
 	}
-	blockRunes := readRunes(start, agreementLen)
-
-	readBlockStr := func(startField, length int) string {
-		if startField >= len(blockRunes) {
-			return ""
-		}
-		end := startField + length
-		if end > len(blockRunes) {
-			end = len(blockRunes)
-		}
-		return strings.TrimSpace(string(blockRunes[startField:end]))
-	}
-
-	readBlockInt := func(startField, length int) int {
-		s := readBlockStr(startField, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
 
 	// Raw values
-	rawCreditCardNo := readBlockStr(0, 16)
+	rawCreditCardNo := block.ReadString(0, 16)
 
 	maskedCreditCardNo := rawCreditCardNo
 	if len(rawCreditCardNo) == 16 {
@@ -211,18 +125,18 @@ func FormatMyCardResponseAll(raw string) (domain.MyCardResponseAll, error) {
 
 	agreements = append(agreements, domain.MyCardListAll{
 		CreditCardNo:        maskedCreditCardNo,
-		CardCode:            readBlockStr(16, 2),
-		ProductType:         readBlockStr(18, 2),
-		CardType:            readBlockStr(20, 1),
-		CardStatus:          readBlockStr(21, 1),
-		ExpireDate:          readBlockInt(22, 8),
-		HoldCode:            readBlockStr(30, 2),
-		RetreatCode:         readBlockStr(32, 1),
-		SendMode:            readBlockStr(33, 1),
-		FirstEmbossDate:     readBlockInt(34, 8),
-		FirstConfirmDate:    readBlockInt(42, 8),
-		ShoppingLimit:       readBlockInt(50, 9),
-		CashingLimit:        readBlockInt(59, 9),
+		CardCode:            block.ReadString(16, 2),
+		ProductType:         block.ReadString(18, 2),
+		CardType:            block.ReadString(20, 1),
+		CardStatus:          block.ReadString(21, 1),
+		ExpireDate:          block.ReadInt(22, 8),
+		HoldCode:            block.ReadString(30, 2),
+		RetreatCode:         block.ReadString(32, 1),
+		SendMode:            block.ReadString(33, 1),
+		FirstEmbossDate:     block.ReadInt(34, 8),
+		FirstConfirmDate:    block.ReadInt(42, 8),
+		ShoppingLimit:       block.ReadInt(50, 9),
+		CashingLimit:        block.ReadInt(59, 9),
 	})
 }
 
@@ -2,8 +2,6 @@ package format
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 	//"bytes"
 
 	"connectorapi-go/internal/core/domain" 
@@ -50,11 +48,11 @@ func FormatGetRedbookInfoResponse(raw string) (domain.GetRedbookInfoResponse, er
 	effectiveYear  := parser.ReadInt(184, 4)
 	effectiveMonth := parser.ReadInt(188, 2)
 	vehicleCode    := parser.ReadString(190, 8)
-	avgWholesale   := parser.ReadFloat100(198, 8)
-	avgRetail      := parser.ReadFloat100(206, 8)
-	goodWholesale  := parser.ReadFloat100(214, 8)
-	goodRetail     := parser.ReadFloat100(222, 8)
-	newPrice       := parser.ReadFloat100(230, 8)
+	avgWholesale   := parser.ReadDecimal(198, 8)
+	avgRetail      := parser.ReadDecimal(206, 8)
+	goodWholesale  := parser.ReadDecimal(214, 8)
+	goodRetail     := parser.ReadDecimal(222, 8)
+	newPrice       := parser.ReadDecimal(230, 8)
 
 	return domain.GetRedbookInfoResponse{
 		AgentCode:      agentCode,
@@ -67,11 +65,11 @@ func FormatGetRedbookInfoResponse(raw string) (domain.GetRedbookInfoResponse, er
 		EffectiveYear:  effectiveYear,
 		EffectiveMonth: effectiveMonth,
 		VehicleCode:    vehicleCode,
-		AvgWholesale:   utils.DecimalString(avgWholesale),
-		AvgRetail:      utils.DecimalString(avgRetail),
-		GoodWholesale:  utils.DecimalString(goodWholesale),
-		GoodRetail:     utils.DecimalString(goodRetail),
-		NewPrice:       utils.DecimalString(newPrice),
+		AvgWholesale:   avgWholesale,
+		AvgRetail:      avgRetail,
+		GoodWholesale:  goodWholesale,
+		GoodRetail:     goodRetail,
+		NewPrice:       newPrice,
 	}, nil
 }
 
@@ -105,13 +103,13 @@ func FormatGetDealerCommissionResponse(raw string) (domain.GetDealerCommissionRe
 	agreementNo          := parser.ReadString(18, 12)
 	commissionCode       := parser.ReadString(30, 8)
 	agentCategory        := parser.ReadString(38, 2)
-	totalCommission      := parser.ReadFloat100(40, 9)
-	vatRate              := parser.ReadFloat100(49, 4)
-	vat                  := parser.ReadFloat100(53, 9)
-	grandTotalCommission := parser.ReadFloat100(62, 9)
-	whtRate              := parser.ReadFloat100(71, 4)
-	whtTax               := parser.ReadFloat100(75, 9)
-	netTotalCommission   := parser.ReadFloat100(84, 9)
+	totalCommission      := parser.ReadDecimal(40, 9)
+	vatRate              := parser.ReadDecimal(49, 4)
+	vat                  := parser.ReadDecimal(53, 9)
+	grandTotalCommission := parser.ReadDecimal(62, 9)
+	whtRate              := parser.ReadDecimal(71, 4)
+	whtTax               := parser.ReadDecimal(75, 9)
+	netTotalCommission   := parser.ReadDecimal(84, 9)
 
 	return domain.GetDealerCommissionResponse{
 		AgentCode:            agentCode,
@@ -119,13 +117,13 @@ func FormatGetDealerCommissionResponse(raw string) (domain.GetDealerCommissionRe
 		AgreementNo:          agreementNo,
 		CommissionCode:       commissionCode,
 		AgentCategory:        agentCategory,
-		TotalCommission:      utils.DecimalString(totalCommission),
-		VATRate:              utils.DecimalString(vatRate),
-		VAT:                  utils.DecimalString(vat),
-		GrandTotalCommission: utils.DecimalString(grandTotalCommission),
-		WHTRate:              utils.DecimalString(whtRate),
-		WHTTax:               utils.DecimalString(whtTax),
-		NetTotalCommission:   utils.DecimalString(netTotalCommission),
+		TotalCommission:      totalCommission,
+		VATRate:              vatRate,
+		VAT:                  vat,
+		GrandTotalCommission: grandTotalCommission,
+		WHTRate:              whtRate,
+		WHTTax:               whtTax,
+		NetTotalCommission:   netTotalCommission,
 	}, nil
 }
 
@@ -145,94 +143,30 @@ func FormatGetDealerAgreementResponse(raw string) (domain.GetDealerAgreementResp
 	if len(raw) <= headerLen {
 		return domain.GetDealerAgreementResponse{}, fmt.Errorf("raw data too short for header, length=%d", len(raw))
 	}
-	body := raw[headerLen:]
-	runes := []rune(body)
+	parser := utils.NewFixedParser(raw[headerLen:])
 
-	readRunes := func(start, length int) []rune {
-		if start >= len(runes) {
-			return []rune{}
-		}
-		end := start + length
-		if end > len(runes) {
-			end = len(runes)
-		}
-		return runes[start:end]
-	}
-
-	readString := func(start, length int) string {
-		rs := readRunes(start, length)
-		return strings.TrimSpace(string(rs))
-	}
-
-	readInt := func(start, length int) int {
-		s := readString(start, length)
-		if s == "" {
-			return 0
-		}
-		i, _ := strconv.Atoi(s)
-		return i
-	}
-
-	// readFloat := func(start, length int) float64 {
-	// 	s := readString(start, length)
-	// 	if s == "" {
-	// 		return 0
-	// 	}
-	// 	f, _ := strconv.ParseFloat(s, 64)
-	// 	return f
-	// }
-
-	agentCode            := readString(0, 8)
-	marketingCode        := readString(8, 10)
-	transactionDateFrom  := readInt(18, 8)
-	transactionDateTo    := readInt(26, 8)
-	agreementNo          := readString(34, 12)
-	totalAgreement       := readInt(46, 3)
+	agentCode            := parser.ReadString(0, 8)
+	marketingCode        := parser.ReadString(8, 10)
+	transactionDateFrom  := parser.ReadInt(18, 8)
+	transactionDateTo    := parser.ReadInt(26, 8)
+	agreementNo          := parser.ReadString(34, 12)
+	totalAgreement       := parser.ReadInt(46, 3)
 
 	const agreementLen = 76
 	agreementStart := 49
 	agreements := make([]domain.AgreementListobj, 0, totalAgreement)
 
 	for i := 0; i < totalAgreement; i++ {
-		start := agreementStart + i*agreementLen
-		if start >= len(runes) {
+		block := parser.Block(agreementStart+i*agreementLen, agreementLen)
+		if block.Empty() {
 			break
 		}
-		blockRunes := readRunes(start, agreementLen)
-
-		readBlockStr := func(startField, length int) string {
-			if startField >= len(blockRunes) {
-				return ""
-			}
-			end := startField + length
-			if end > len(blockRunes) {
-				end = len(blockRunes)
-			}
-			return strings.TrimSpace(string(blockRunes[startField:end]))
-		}
-
-		readBlockInt := func(startField, length int) int {
-			s := readBlockStr(startField, length)
-			if s == "" {
-				return 0
-			}
-			i, _ := strconv.Atoi(s)
-			return i
-		}
-
-	// 	readBlockFloat := func(startField, length int) utils.DecimalString {
-    //         s := readBlockStr(startField, length)
-    //         i, _ := strconv.ParseInt(s, 10, 64)
-    //         f := float64(i) / 100.0
-    //         return utils.DecimalString(f)
-    //    }
-
 
 		agreements = append(agreements, domain.AgreementListobj{
-			AgreementNo:             readBlockStr(0, 12),
-			TransactionDate:         readBlockInt(12, 8),
-			CustomerName:            readBlockStr(20, 50),
-			Status:                  readBlockStr(70, 6),
+			AgreementNo:             block.ReadString(0, 12),
+			TransactionDate:         block.ReadInt(12, 8),
+			CustomerName:            block.ReadString(20, 50),
+			Status:                  block.ReadString(70, 6),
 		})
 	}
 
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 	// "strconv"
 
@@ -12,6 +13,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -132,7 +134,7 @@ func (s *mobileService) DashboardSummary(c *gin.Context, dashboardSummaryReq dom
 		}
 	}
 
-	portList, ok := destination.Ports["DashboardSummary"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.DashboardSummaryResult{
@@ -178,7 +180,9 @@ func (s *mobileService) DashboardSummary(c *gin.Context, dashboardSummaryReq dom
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatDashboardSummaryRequest(flagOldFormatReq, dashboardSummaryReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		systemName,
@@ -189,10 +193,10 @@ func (s *mobileService) DashboardSummary(c *gin.Context, dashboardSummaryReq dom
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -310,8 +314,9 @@ func (s *mobileService) DashboardSummary(c *gin.Context, dashboardSummaryReq dom
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	dashboardSummaryResponse, err := format.FormatDashboardSummaryResponse(responseStr, flagOldFormatReq)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map dashboardSummaryResponse:", err)
 
@@ -456,7 +461,7 @@ func (s *mobileService) DashboardDetail(c *gin.Context, dashboardDetailReq domai
 		}
 	}
 
-	portList, ok := destination.Ports["DashboardDetail"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.DashboardDetailResult{
@@ -502,7 +507,9 @@ func (s *mobileService) DashboardDetail(c *gin.Context, dashboardDetailReq domai
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatDashboardDetailRequest(flagOldFormatReq, dashboardDetailReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		systemName,
@@ -513,10 +520,10 @@ func (s *mobileService) DashboardDetail(c *gin.Context, dashboardDetailReq domai
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -634,8 +641,9 @@ func (s *mobileService) DashboardDetail(c *gin.Context, dashboardDetailReq domai
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	dashboardDetailResponse, err := format.FormatDashboardDetailResponse(responseStr, flagOldFormatReq)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map dashboardDetailResponse:", err)
 
@@ -701,11 +709,6 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 	routeKey := utils.GetRouteKey(c)
 	const destinationName = "systemI"
 	serviceName := "MobileFullPan"
-	var domainErr *appError.AppError
-	var logLine1 string
-	var formatReq interface{}
-	var formatResp interface{}
-	var formatErr interface{}
 
 	reqID, _ := c.Get("Api-RequestID")
 	apiRequestID, ok := reqID.(string)
@@ -720,7 +723,7 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
 		}
 	}
 	
@@ -737,7 +740,23 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
+		}
+	}
+
+	if len(mobileFullPanReq.CardListRq) == 0 || len(mobileFullPanReq.CardListRq) != mobileFullPanReq.TotalCard {
+		s.logger.Errorw("Mismatch in number of cards", "Expected", mobileFullPanReq.TotalCard, "Actual", len(mobileFullPanReq.CardListRq))
+		return domain.MobileFullPanResult{
+			Response:    nil,
+			AppError:    appError.ErrInvTotalOfList,
+			GinCtx:      nil,
+			Timestamp:   timestamp,
+			ReqBody:     nil,
+			RespBody:    nil,
+			DomainError: nil,
+			ServiceName: serviceName,
+			UserRef:     mobileFullPanReq.IDCardNo,
+			LogLines:    nil,
 		}
 	}
 
@@ -754,7 +773,7 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
 		}
 	}
 
@@ -771,7 +790,7 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
 		}
 	}
 	if destination.Type != "tcp" {
@@ -786,11 +805,11 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
 		}
 	}
 
-	portList, ok := destination.Ports["MobileFullPan"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.MobileFullPanResult{
@@ -803,7 +822,7 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
 		}
 	}
 	port := utils.RandomPortFromList(portList)
@@ -819,62 +838,60 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 			DomainError: nil,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    nil,
 		}
 	}
 
-	mobileFullPanFormatRq := domain.MobileFullPanFormatRequest{
-		IDCardNo: mobileFullPanReq.IDCardNo,
-		CreditCardNo: mobileFullPanReq.CardListRq[0].CardNo,
-		BusinessCode: mobileFullPanReq.CardListRq[0].CardCode,
+	// Each card is a System I call of its own. The calls run concurrently on distinct
+	// ports of the pool, starting at a random one, at most maxFanOut at a time.
+	cards := mobileFullPanReq.CardListRq
+	workers := len(cards)
+	if workers > len(portList) {
+		workers = len(portList)
 	}
-	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
-	fixedLengthData := format.FormatMobileFullPanRequest(mobileFullPanFormatRq)
-
-	header := utils.BuildFixedLengthHeader(
-		route.System,
-		route.Service,
-		route.Format,
-		formattedRequestID,
-		route.RequestLength,
-	)
-
-	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
-
-	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
-
-	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
-	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
-
-	formatReq = map[string]string{"data": combinedPayloadString}
-	formatResp = map[string]string{"data": cleanRsponseStr}
-
-	if err != nil {
-		s.logger.Errorw("Downstream TCP service call failed", "error", err, "address", tcpAddress)
-
-		formatErr = map[string]string{"data": err.Error()}
-        errMsg := err.Error()
-
-        switch {
-        case strings.Contains(errMsg, "ER040"), strings.Contains(errMsg, "ER060"):
-            temp := *appError.ErrTimeOut
-            temp.StatusCode = "504"
-            domainErr = &temp
-
-        case strings.Contains(errMsg, "ER099"):
-            temp := *appError.ErrInternalServer
-            temp.StatusCode = "500"
-            domainErr = &temp
-
-        default:
-            domainErr = appError.ErrService
-        }
+	if maxFanOut := s.config.TCP.MaxFanOut; maxFanOut > 0 && workers > maxFanOut {
+		workers = maxFanOut
+	}
+	firstPort := 0
+	for i, p := range portList {
+		if p == port {
+			firstPort = i
+			break
+		}
+	}
+	firstSeqNo := elkLog.ReserveSeqNo(c, len(cards))
+
+	calls := make([]mobileFullPanCall, len(cards))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerPort := portList[(firstPort+w)%len(portList)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				calls[i] = s.mobileFullPanCard(c, routeKey, route, destination.IP, workerPort, apiRequestID, mobileFullPanReq.IDCardNo, cards[i], firstSeqNo+i)
+			}
+		}()
+	}
+	for i := range cards {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	c.Set(utils.SystemIResultKey, fullPanResult(calls))
 
-		logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatErr, domainErr, "", destination.IP+":"+port, serviceName, "MobileFullPan", "", mobileFullPanReq.IDCardNo)
-		if logLine1 == "" {
-			s.logger.Errorw("Error generating log: %v", logLine1)
+	mobileFullPanResponse := domain.MobileFullPanResponse{
+		IDCardNo:   mobileFullPanReq.IDCardNo,
+		CardListRs: make([]domain.MobileCardListRs, 0, len(cards)),
+	}
+	logLines := make([]string, 0, len(calls))
+	var firstFailure *mobileFullPanCall
+	var encryptErr error
+	for i := range calls {
+		call := &calls[i]
+		if call.logLine == "" {
+			s.logger.Errorw("Error generating log: %v", call.logLine)
 			return domain.MobileFullPanResult{
 				Response:    nil,
 				AppError:    appError.ErrInternalServer,
@@ -885,128 +902,233 @@ func (s *mobileService) MobileFullPan(c *gin.Context, mobileFullPanReq domain.Mo
 				DomainError: nil,
 				ServiceName: serviceName,
 				UserRef:     mobileFullPanReq.IDCardNo,
-				LogLine1:    "",
+				LogLines:    nil,
 			}
 		}
+		logLines = append(logLines, call.logLine)
 
-		return domain.MobileFullPanResult{
-			Response:    nil,
-			AppError:    nil,
-			GinCtx:      c,
-			Timestamp:   timestamp,
-			ReqBody:     mobileFullPanReq,
-			RespBody:    formatErr,
-			DomainError: domainErr,
-			ServiceName: serviceName,
-			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    logLine1,
-		}
-	}
-
-	errorCode := strings.TrimSpace(responseStr[67:73])
-	errorMessage := strings.TrimSpace(responseStr[73:123])
-	if errorCode != "" {
-		switch errorCode {
-		case "SVC105", "SVC117":
-			domainErr = appError.ErrInvIDCardNo
-		case "SVC118":
-			domainErr = appError.ErrInvCreditCard
-		default:
-			if errorCode != "" {
-				s.logger.Info("Unknown error code from System I : ", "code", errorCode, "message", errorMessage)
-				domainErr = appError.ErrSystemIUnexpect
+		if call.domainErr != nil {
+			if firstFailure == nil {
+				firstFailure = call
 			}
-		}
-		temp := *domainErr
-		temp.Code = errorCode
-		temp.Message = errorMessage
-		domainErr = &temp
-
-		logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatResp, domainErr, "", destination.IP+":"+port, serviceName, "MobileFullPan", "", mobileFullPanReq.IDCardNo)
-		if logLine1 == "" {
-			s.logger.Errorw("Error generating log: %v", logLine1)
-			return domain.MobileFullPanResult{
-				Response:    nil,
-				AppError:    appError.ErrInternalServer,
-				GinCtx:      nil,
-				Timestamp:   timestamp,
-				ReqBody:     nil,
-				RespBody:    nil,
-				DomainError: nil,
-				ServiceName: serviceName,
-				UserRef:     mobileFullPanReq.IDCardNo,
-				LogLine1:    "",
+			failed := domain.MobileFullPanResponse{CardListRs: []domain.MobileCardListRs{{
+				CardNo:       cards[i].CardNo,
+				CardCode:     cards[i].CardCode,
+				ErrorCode:    call.domainErr.ErrorCode,
+				ErrorMessage: call.domainErr.ErrorMessage,
+			}}}
+			if err := encryptCardNumbers(c, &failed); err != nil && encryptErr == nil {
+				encryptErr = err
 			}
+			mobileFullPanResponse.CardListRs = append(mobileFullPanResponse.CardListRs, failed.CardListRs...)
+			continue
 		}
+		mobileFullPanResponse.CardListRs = append(mobileFullPanResponse.CardListRs, call.cards...)
+	}
+	mobileFullPanResponse.TotalCard = len(mobileFullPanResponse.CardListRs)
 
+	// When no card could be read the request fails as a whole with the error of the first card.
+	if firstFailure != nil && allFailed(calls) {
 		return domain.MobileFullPanResult{
 			Response:    nil,
 			AppError:    nil,
 			GinCtx:      c,
 			Timestamp:   timestamp,
-			ReqBody:     formatReq,
-			RespBody:    domainErr,
-			DomainError: domainErr,
+			ReqBody:     mobileFullPanReq,
+			RespBody:    firstFailure.response,
+			DomainError: firstFailure.domainErr,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    logLine1,
+			LogLines:    logLines,
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
-	mobileFullPanResponse, err := format.FormatMobileFullPanResponse(responseStr)
-	if err != nil {
-		s.logger.Errorw("Error map mobileFullPanResponse:", err)
-
-		formatErr = map[string]string{"data": err.Error()}
-		logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatErr, nil, "", destination.IP+":"+port, serviceName, "MobileFullPan", "", mobileFullPanReq.IDCardNo)
-		if logLine1 == "" {
-			s.logger.Errorw("Error generating log: %v", logLine1)
-			return domain.MobileFullPanResult{
-				Response:    nil,
-				AppError:    appError.ErrInternalServer,
-				GinCtx:      nil,
-				Timestamp:   timestamp,
-				ReqBody:     nil,
-				RespBody:    nil,
-				DomainError: nil,
-				ServiceName: serviceName,
-				UserRef:     mobileFullPanReq.IDCardNo,
-				LogLine1:    "",
-			}
-		}
-	}
-
-	logLine1 = elkLog.GenerateELKLogLine(c, timestamp, formatReq, formatResp, nil, "", destination.IP+":"+port, serviceName, "MobileFullpan", "", mobileFullPanReq.IDCardNo)
-	if logLine1 == "" {
-		s.logger.Errorw("Error generating log: %v", logLine1)
+	if encryptErr != nil {
+		s.logger.Errorw("Error encrypting card numbers", "error", encryptErr)
 		return domain.MobileFullPanResult{
 			Response:    nil,
-			AppError:    appError.ErrInternalServer,
-			GinCtx:      nil,
+			AppError:    nil,
+			GinCtx:      c,
 			Timestamp:   timestamp,
-			ReqBody:     nil,
+			ReqBody:     mobileFullPanReq,
 			RespBody:    nil,
-			DomainError: nil,
+			DomainError: appError.ErrInternalServer,
 			ServiceName: serviceName,
 			UserRef:     mobileFullPanReq.IDCardNo,
-			LogLine1:    "",
+			LogLines:    logLines,
 		}
 	}
 
-	if domainErr != nil && domainErr.ErrorCode == "" {
-		domainErr = nil
-	}
 	return domain.MobileFullPanResult{
 		Response:    &mobileFullPanResponse,
 		AppError:    nil,
 		GinCtx:      c,
 		Timestamp:   timestamp,
 		ReqBody:     mobileFullPanReq,
-		RespBody:    formatResp,
-		DomainError: domainErr,
+		RespBody:    &mobileFullPanResponse,
+		DomainError: nil,
 		ServiceName: serviceName,
 		UserRef:     mobileFullPanReq.IDCardNo,
-		LogLine1:    logLine1,
+		LogLines:    logLines,
+	}
+}
+
+// mobileFullPanCall is the outcome of the System I call for one card.
+type mobileFullPanCall struct {
+	cards     []domain.MobileCardListRs
+	response  interface{}
+	domainErr *appError.AppError
+	logLine   string
+	result    string // see utils.SystemIResultCode
+}
+
+// fullPanResult is the System I result of the request: the first call that is not a
+// business result, else the first response code other than OK.
+func fullPanResult(calls []mobileFullPanCall) string {
+	result := "OK"
+	for _, call := range calls {
+		if !utils.BusinessResult(call.result) {
+			return call.result
+		}
+		if result == "OK" {
+			result = call.result
+		}
+	}
+	return result
+}
+
+func allFailed(calls []mobileFullPanCall) bool {
+	for _, call := range calls {
+		if call.domainErr == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// mobileFullPanCard asks System I for one card and writes its line log with seqNo.
+// It runs concurrently with the other cards and only reads c.
+func (s *mobileService) mobileFullPanCard(c *gin.Context, routeKey string, route config.Route, ip, port, apiRequestID, idCardNo string, card domain.MobileCardListRq, seqNo int) mobileFullPanCall {
+	timestamp := time.Now()
+	serviceName := "MobileFullPan"
+	var call mobileFullPanCall
+
+	mobileFullPanFormatRq := domain.MobileFullPanFormatRequest{
+		IDCardNo:     idCardNo,
+		CreditCardNo: card.CardNo,
+		BusinessCode: card.CardCode,
+	}
+	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
+	fixedLengthData := format.FormatMobileFullPanRequest(mobileFullPanFormatRq)
+	formatSpan.End()
+
+	header := utils.BuildFixedLengthHeader(
+		route.System,
+		route.Service,
+		route.Format,
+		formattedRequestID,
+		route.RequestLength,
+	)
+
+	combinedPayloadString := header + fixedLengthData
+
+	tcpAddress := fmt.Sprintf("%s:%s", ip, port)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	call.result = utils.SystemIResultCode(responseStr, err)
+
+	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
+	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
+
+	formatReq := map[string]string{"data": combinedPayloadString}
+	call.response = map[string]string{"data": cleanRsponseStr}
+
+	switch {
+	case err != nil:
+		s.logger.Errorw("Downstream TCP service call failed", "error", err, "address", tcpAddress)
+
+		call.response = map[string]string{"data": err.Error()}
+		errMsg := err.Error()
+
+		switch {
+		case strings.Contains(errMsg, "ER040"), strings.Contains(errMsg, "ER060"):
+			temp := *appError.ErrTimeOut
+			temp.StatusCode = "504"
+			call.domainErr = &temp
+
+		case strings.Contains(errMsg, "ER099"):
+			temp := *appError.ErrInternalServer
+			temp.StatusCode = "500"
+			call.domainErr = &temp
+
+		default:
+			call.domainErr = appError.ErrService
+		}
+
+	case len(responseStr) < 123:
+		s.logger.Errorw("Error map mobileFullPanResponse:", fmt.Errorf("raw data too short for header, length=%d", len(responseStr)))
+		call.domainErr = appError.ErrSystemIUnexpect
+
+	case strings.TrimSpace(responseStr[67:73]) != "":
+		errorCode := strings.TrimSpace(responseStr[67:73])
+		errorMessage := strings.TrimSpace(responseStr[73:123])
+		switch errorCode {
+		case "SVC105", "SVC117":
+			call.domainErr = appError.ErrInvIDCardNo
+		case "SVC118":
+			call.domainErr = appError.ErrInvCreditCard
+		default:
+			s.logger.Info("Unknown error code from System I : ", "code", errorCode, "message", errorMessage)
+			call.domainErr = appError.ErrSystemIUnexpect
+		}
+		temp := *call.domainErr
+		temp.Code = errorCode
+		temp.Message = errorMessage
+		call.domainErr = &temp
+
+	default:
+		parseSpan := startFormatSpan(c, "format response")
+		mobileFullPanResponse, err := format.FormatMobileFullPanResponse(responseStr)
+		tracing.End(parseSpan, err)
+		if err != nil {
+			s.logger.Errorw("Error map mobileFullPanResponse:", err)
+			call.response = map[string]string{"data": err.Error()}
+			call.domainErr = appError.ErrSystemIUnexpect
+			break
+		}
+		if utils.FieldEncrypterFrom(c) != nil {
+			// The line log gets the encrypted card numbers instead of the System I message.
+			if err := encryptCardNumbers(c, &mobileFullPanResponse); err != nil {
+				s.logger.Errorw("Error encrypting card numbers", "error", err)
+				call.response = nil
+				call.domainErr = appError.ErrInternalServer
+				break
+			}
+			call.response = mobileFullPanResponse
+		}
+		call.cards = mobileFullPanResponse.CardListRs
+	}
+
+	call.logLine = elkLog.GenerateELKLogLineWithSeqNo(c, seqNo, timestamp, formatReq, call.response, call.domainErr, "", tcpAddress, serviceName, "MobileFullPan", "", idCardNo)
+	return call
+}
+
+// encryptCardNumbers replaces every card number with its ciphertext when the client has
+// field encryption, so that neither the handler nor the ELK log sees the clear text.
+func encryptCardNumbers(c *gin.Context, mobileFullPanResponse *domain.MobileFullPanResponse) error {
+	encrypter := utils.FieldEncrypterFrom(c)
+	if encrypter == nil {
+		return nil
+	}
+	for i := range mobileFullPanResponse.CardListRs {
+		if mobileFullPanResponse.CardListRs[i].CardNo == "" {
+			continue
+		}
+		ciphertext, err := encrypter.Encrypt(mobileFullPanResponse.CardListRs[i].CardNo)
+		if err != nil {
+			return err
+		}
+		mobileFullPanResponse.CardListRs[i].CardNo = ciphertext
 	}
+	return nil
 }
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/internal/core/domain"
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/fieldcrypt"
+	"connectorapi-go/pkg/mask"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const declinedCard = "4012888888881881"
+
+// fakeSystemI answers MobileFullPAN calls and records how many run at once.
+type fakeSystemI struct {
+	inFlight    int32
+	maxInFlight int32
+	mu          sync.Mutex
+	addresses   map[string]int
+}
+
+func (f *fakeSystemI) SendAndReceive(_ context.Context, address string, payload string) (string, error) {
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&f.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&f.maxInFlight, max, n) {
+			break
+		}
+	}
+	f.mu.Lock()
+	f.addresses[address]++
+	f.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+
+	header := payload[:67]
+	body := payload[123:]
+	idCardNo, cardNo := body[:20], body[20:36]
+	if cardNo == declinedCard {
+		return header + utils.PadOrTruncate("SVC118", 6) + utils.PadOrTruncate("Card not found", 50) + "\r\n", nil
+	}
+	block := cardNo + utils.PadOrTruncate("", 30) + "0102" + "00" + "20290131" + "N"
+	return header + utils.PadOrTruncate("", 56) + idCardNo + "0001" + block + "\r\n", nil
+}
+
+func runMobileFullPan(t *testing.T, fake *fakeSystemI, maxFanOut int, req domain.MobileFullPanRequest, encrypter fieldcrypt.Encrypter) (domain.MobileFullPanResult, []string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{TCP: config.TCPConfig{MaxFanOut: maxFanOut}}
+	routes := map[string]config.Route{
+		"POST:/Api/Mobile/MobileFullPAN": {System: "MOB_APP", Service: "INQ_CUST_CALIST", PortKey: "MobileFullPan", Format: "001", RequestLength: "00038"},
+	}
+	destinations := map[string]config.Destination{
+		"systemI": {Type: "tcp", IP: "10.0.0.1", Ports: map[string][]string{"MobileFullPan": {"40110", "40111", "40112", "40113"}}},
+	}
+	s := NewMobileService(cfg, zap.NewNop().Sugar(), fake, routes, destinations)
+
+	var result domain.MobileFullPanResult
+	router := gin.New()
+	router.POST("/Api/Mobile/MobileFullPAN", func(c *gin.Context) {
+		c.Set("Api-RequestID", "REQ-1")
+		if encrypter != nil {
+			c.Set(utils.FieldEncrypterKey, encrypter)
+		}
+		result = s.MobileFullPan(c, req)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/Api/Mobile/MobileFullPAN", nil))
+
+	var seqNos []string
+	for _, line := range result.LogLines {
+		var data struct{ SeqNo string }
+		if err := json.Unmarshal([]byte(line[strings.Index(line, "{"):]), &data); err != nil {
+			t.Fatalf("line log is not JSON: %v", err)
+		}
+		seqNos = append(seqNos, data.SeqNo)
+	}
+	return result, seqNos
+}
+
+func cardList(cardNos ...string) []domain.MobileCardListRq {
+	var cards []domain.MobileCardListRq
+	for _, cardNo := range cardNos {
+		cards = append(cards, domain.MobileCardListRq{CardNo: cardNo, CardCode: "01"})
+	}
+	return cards
+}
+
+func TestMobileFullPanEveryCard(t *testing.T) {
+	fake := &fakeSystemI{addresses: map[string]int{}}
+	cards := cardList("4111111111111111", declinedCard, "5500005555555559", "4000000000000002", "4000000000000010", "4000000000000028")
+	result, seqNos := runMobileFullPan(t, fake, 3, domain.MobileFullPanRequest{
+		IDCardNo: "1234567890123", Channel: "L", TotalCard: len(cards), CardListRq: cards,
+	}, nil)
+
+	if result.AppError != nil || result.DomainError != nil || result.Response == nil {
+		t.Fatalf("result = %+v", result)
+	}
+	resp := result.Response
+	if resp.TotalCard != len(cards) || len(resp.CardListRs) != len(cards) {
+		t.Fatalf("TotalCard = %d with %d cards, want %d", resp.TotalCard, len(resp.CardListRs), len(cards))
+	}
+	for i, card := range resp.CardListRs {
+		if card.CardNo != cards[i].CardNo {
+			t.Errorf("card %d = %s, want %s in request order", i, card.CardNo, cards[i].CardNo)
+		}
+		declined := cards[i].CardNo == declinedCard
+		if declined != (card.ErrorCode == "CRC001") {
+			t.Errorf("card %s has error %q", card.CardNo, card.ErrorCode)
+		}
+		if !declined && card.ExpireDate != 20290131 {
+			t.Errorf("card %s not parsed: %+v", card.CardNo, card)
+		}
+	}
+
+	if fake.maxInFlight > 3 {
+		t.Errorf("%d calls in flight, maxFanOut is 3", fake.maxInFlight)
+	}
+	if fake.maxInFlight < 2 {
+		t.Errorf("cards were not called concurrently")
+	}
+	if len(fake.addresses) != 3 {
+		t.Errorf("calls went to %v, want 3 distinct ports", fake.addresses)
+	}
+	if strings.Join(seqNos, ",") != "1,2,3,4,5,6" {
+		t.Errorf("line log SeqNos = %v", seqNos)
+	}
+}
+
+func TestMobileFullPanLogsCiphertext(t *testing.T) {
+	mask.SetDefault(mask.New(config.MaskingConfig{
+		Fields: map[string]string{"CardNo": "pan", "CreditCardNo": "pan", "IDCardNo": "last4"},
+		Layouts: map[string]config.MaskLayout{"POST:/Api/Mobile/MobileFullPAN": {
+			Request:  []config.MaskField{{Name: "IDCardNo", Offset: 0, Length: 20}, {Name: "CreditCardNo", Offset: 20, Length: 16}},
+			Response: []config.MaskField{{Name: "IDCardNo", Offset: 0, Length: 20}, {Name: "CardNo", Offset: 24, Length: 16, Every: 61}},
+		}},
+	}))
+	t.Cleanup(func() { mask.SetDefault(mask.New(config.MaskingConfig{})) })
+	encrypter, err := fieldcrypt.New(fieldcrypt.MethodAESGCM, "", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeSystemI{addresses: map[string]int{}}
+	cards := cardList("4111111111111111", "5500005555555559")
+	result, _ := runMobileFullPan(t, fake, 2, domain.MobileFullPanRequest{
+		IDCardNo: "1234567890123", Channel: "L", TotalCard: len(cards), CardListRq: cards,
+	}, encrypter)
+
+	if result.Response == nil || len(result.LogLines) != len(cards) {
+		t.Fatalf("result = %+v", result)
+	}
+	for i, line := range result.LogLines {
+		var data struct{ ResponseMessage json.RawMessage }
+		if err := json.Unmarshal([]byte(line[strings.Index(line, "{"):]), &data); err != nil {
+			t.Fatal(err)
+		}
+		response := string(data.ResponseMessage)
+		ciphertext := result.Response.CardListRs[i].CardNo
+		if !strings.Contains(response, ciphertext) {
+			t.Errorf("line %d response %s, want the ciphertext %s the client got", i+1, response, ciphertext)
+		}
+		if pan := cards[i].CardNo; strings.Contains(response, pan[:6]) || strings.Contains(response, pan[12:]) || strings.Contains(line, pan) {
+			t.Errorf("line %d shows digits of %s: %s", i+1, pan, line)
+		}
+	}
+}
+
+func TestMobileFullPanAllCardsFail(t *testing.T) {
+	fake := &fakeSystemI{addresses: map[string]int{}}
+	result, seqNos := runMobileFullPan(t, fake, 4, domain.MobileFullPanRequest{
+		IDCardNo: "1234567890123", Channel: "L", TotalCard: 1, CardListRq: cardList(declinedCard),
+	}, nil)
+	if result.Response != nil || result.DomainError == nil || result.DomainError.ErrorCode != "CRC001" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(seqNos) != 1 {
+		t.Errorf("line logs = %v", seqNos)
+	}
+}
+
+func TestMobileFullPanTotalCardMismatch(t *testing.T) {
+	fake := &fakeSystemI{addresses: map[string]int{}}
+	result, _ := runMobileFullPan(t, fake, 4, domain.MobileFullPanRequest{
+		IDCardNo: "1234567890123", Channel: "L", TotalCard: 3, CardListRq: cardList("4111111111111111"),
+	}, nil)
+	if result.AppError == nil || result.AppError.ErrorCode != "COM016" {
+		t.Fatalf("AppError = %v, want COM016", result.AppError)
+	}
+	if len(fake.addresses) != 0 {
+		t.Error("System I called despite the mismatch")
+	}
+}
+
+func TestFullPanResult(t *testing.T) {
+	tests := []struct {
+		results []string
+		want    string
+	}{
+		{nil, "OK"},
+		{[]string{"OK", "OK"}, "OK"},
+		{[]string{"OK", "SVC118", "SVC101"}, "SVC118"},
+		{[]string{"SVC118", "ER040", "SVC902"}, "ER040"},
+		{[]string{"OK", "SHORT"}, "SHORT"},
+	}
+	for _, tt := range tests {
+		calls := make([]mobileFullPanCall, len(tt.results))
+		for i, result := range tt.results {
+			calls[i].result = result
+		}
+		if got := fullPanResult(calls); got != tt.want {
+			t.Errorf("fullPanResult(%v) = %q, want %q", tt.results, got, tt.want)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -113,7 +114,7 @@ func (s *registerService) CheckRegister(c *gin.Context, checkRegisterReq domain.
 		}
 	}
 
-	portList, ok := destination.Ports["CheckRegister"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.CheckRegisterResult{
@@ -147,7 +148,9 @@ func (s *registerService) CheckRegister(c *gin.Context, checkRegisterReq domain.
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatCheckRegisterRequest(checkRegisterReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -158,10 +161,10 @@ func (s *registerService) CheckRegister(c *gin.Context, checkRegisterReq domain.
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -290,8 +293,9 @@ func (s *registerService) CheckRegister(c *gin.Context, checkRegisterReq domain.
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	checkRegisterResponse, err := format.FormatCheckRegisterResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map checkRegisterResponse:", err)
 
@@ -415,7 +419,7 @@ func (s *registerService) CheckRegisterSocial(c *gin.Context, checkRegisterSocia
 		}
 	}
 
-	portList, ok := destination.Ports["CheckRegisterSocial"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.CheckRegisterSocialResult{
@@ -449,7 +453,9 @@ func (s *registerService) CheckRegisterSocial(c *gin.Context, checkRegisterSocia
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatCheckRegisterSocialRequest(checkRegisterSocialReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -460,10 +466,10 @@ func (s *registerService) CheckRegisterSocial(c *gin.Context, checkRegisterSocia
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -576,8 +582,9 @@ func (s *registerService) CheckRegisterSocial(c *gin.Context, checkRegisterSocia
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	checkRegisterSocialResponse, err := format.FormatCheckRegisterSocialResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map checkRegisterSocialResponse:", err)
 
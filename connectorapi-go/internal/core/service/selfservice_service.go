@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -153,7 +154,7 @@ func (s *selfServiceService) MyCard(c *gin.Context, myCardReq domain.MyCardReque
 		}
 	}
 
-	portList, ok := destination.Ports["MyCard"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.MyCardResult{
@@ -206,6 +207,7 @@ func (s *selfServiceService) MyCard(c *gin.Context, myCardReq domain.MyCardReque
 	    }
     }
 
+	formatSpan := startFormatSpan(c, "format request")
 	switch {
     case myCardReq.Mode == "Normal":
 	Service, RequestLength = "INQ_CUST_CALIST", "00038"
@@ -214,6 +216,7 @@ func (s *selfServiceService) MyCard(c *gin.Context, myCardReq domain.MyCardReque
 	Service, RequestLength = "INQ_CUST_CARDLS", "00022"
 	fixedLengthData = format.FormatMyCardRequestAll(myCardReq)
     }
+	formatSpan.End()
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
 
@@ -226,10 +229,10 @@ func (s *selfServiceService) MyCard(c *gin.Context, myCardReq domain.MyCardReque
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -350,19 +353,17 @@ func (s *selfServiceService) MyCard(c *gin.Context, myCardReq domain.MyCardReque
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
-
 	var MyCardResponse interface{}
 	serviceFromSysi := strings.TrimSpace(responseStr[10:25])
 
-	s.logger.Info("Received downstream TCP response", "response", serviceFromSysi)
-
+	parseSpan := startFormatSpan(c, "format response")
 	switch serviceFromSysi {
     case "INQ_CUST_CALIST":
          MyCardResponse, err = format.FormatMyCardResponseNormal(responseStr)
     default:
          MyCardResponse, err = format.FormatMyCardResponseAll(responseStr)
     }
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map MyCardResponse:", err)
 
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"connectorapi-go/internal/adapter/utils"
+	"connectorapi-go/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startFormatSpan starts the span of turning a request into the fixed-length
+// payload of System I, or its response back into JSON.
+func startFormatSpan(c *gin.Context, name string) trace.Span {
+	_, span := tracing.Start(c.Request.Context(), name, attribute.String("connector.route", utils.GetRouteKey(c)))
+	return span
+}
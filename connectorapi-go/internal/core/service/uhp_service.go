@@ -11,6 +11,7 @@ import (
 	"connectorapi-go/pkg/config"
 	"connectorapi-go/internal/core/service/format"
 	appError "connectorapi-go/pkg/error"
+	"connectorapi-go/pkg/tracing"
 	elkLog "connectorapi-go/internal/adapter/client/elk"
 
 	"github.com/gin-gonic/gin"
@@ -113,7 +114,7 @@ func (s *uhpService) GetRedbookInfo(c *gin.Context, getRedbookInfoReq domain.Get
 		}
 	}
 
-	portList, ok := destination.Ports["GetRedbookInfo"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetRedbookInfoResult{
@@ -165,7 +166,9 @@ func (s *uhpService) GetRedbookInfo(c *gin.Context, getRedbookInfoReq domain.Get
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetRedbookInfoRequest(getRedbookInfoReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -176,10 +179,10 @@ func (s *uhpService) GetRedbookInfo(c *gin.Context, getRedbookInfoReq domain.Get
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -290,8 +293,9 @@ func (s *uhpService) GetRedbookInfo(c *gin.Context, getRedbookInfoReq domain.Get
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getRedbookInfoResponse, err := format.FormatGetRedbookInfoResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getRedbookInfoResponse:", err)
 
@@ -415,7 +419,7 @@ func (s *uhpService) GetDealerCommission(c *gin.Context, getDealerCommissionReq
 		}
 	}
 
-	portList, ok := destination.Ports["GetDealerCommission"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetDealerCommissionResult{
@@ -467,7 +471,9 @@ func (s *uhpService) GetDealerCommission(c *gin.Context, getDealerCommissionReq
 	}
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetDealerCommissionRequest(getDealerCommissionReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -478,10 +484,10 @@ func (s *uhpService) GetDealerCommission(c *gin.Context, getDealerCommissionReq
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -602,8 +608,9 @@ func (s *uhpService) GetDealerCommission(c *gin.Context, getDealerCommissionReq
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getDealerCommissionResponse, err := format.FormatGetDealerCommissionResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getDealerCommissionResponse:", err)
 
@@ -727,7 +734,7 @@ func (s *uhpService) GetDealerAgreement(c *gin.Context, getDealerAgreementReq do
 		}
 	}
 
-	portList, ok := destination.Ports["GetDealerAgreement"]
+	portList, ok := destination.Ports[route.PortKey]
 	if !ok || len(portList) == 0 {
 		s.logger.Errorw("Invalid port configuration", "port", portList)
 		return domain.GetDealerAgreementResult{
@@ -799,7 +806,9 @@ func (s *uhpService) GetDealerAgreement(c *gin.Context, getDealerAgreementReq do
 
 
 	formattedRequestID := utils.PadOrTruncate(apiRequestID, 20)
+	formatSpan := startFormatSpan(c, "format request")
 	fixedLengthData := format.FormatGetDealerAgreementRequest(getDealerAgreementReq)
+	formatSpan.End()
 
 	header := utils.BuildFixedLengthHeader(
 		route.System,
@@ -810,10 +819,10 @@ func (s *uhpService) GetDealerAgreement(c *gin.Context, getDealerAgreementReq do
 	)
 
 	combinedPayloadString := header + fixedLengthData
-	s.logger.Info("Sending TCP request payload : ", combinedPayloadString)
 
 	tcpAddress := fmt.Sprintf("%s:%s", destination.IP, port)
-	responseStr, err := s.tcpClient.SendAndReceive(tcpAddress, combinedPayloadString)
+	responseStr, err := s.tcpClient.SendAndReceive(c.Request.Context(), tcpAddress, combinedPayloadString)
+	utils.SetSystemIResult(c, responseStr, err)
 
 	cleanRsponseStr := strings.ReplaceAll(responseStr, "\r", "")
 	cleanRsponseStr = strings.ReplaceAll(cleanRsponseStr, "\n", "")
@@ -932,8 +941,9 @@ func (s *uhpService) GetDealerAgreement(c *gin.Context, getDealerAgreementReq do
 		}
 	}
 
-	s.logger.Info("Received downstream TCP response", "response", string(responseStr))
+	parseSpan := startFormatSpan(c, "format response")
 	getDealerAgreementResponse, err := format.FormatGetDealerAgreementResponse(responseStr)
+	tracing.End(parseSpan, err)
 	if err != nil {
 		s.logger.Errorw("Error map getDealerAgreementResponse:", err)
 
@@ -0,0 +1,125 @@
+package apikey
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// KeyIDLength is the length of a key ID, see KeyID.
+	KeyIDLength = 12
+	// MinKeyLength is the length below which Hash refuses a key as too easy to guess.
+	MinKeyLength = 24
+	// GeneratedKeyLength is the length of keys created by Generate.
+	GeneratedKeyLength = 32
+
+	algorithm  = "s256"
+	saltLength = 16
+	alphabet   = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	keyIDLabel = "connectorapi-go api key id"
+)
+
+// Credential is a parsed hash entry in the form <keyID>$s256$<salt>$<digest>.
+type Credential struct {
+	KeyID  string
+	salt   []byte
+	digest []byte
+}
+
+// KeyID returns the ID of a raw key: the first KeyIDLength characters of an HMAC of
+// the key, so that the ID finds the hash entry of a key without giving away any of it.
+func KeyID(key string) string {
+	mac := hmac.New(sha256.New, []byte(keyIDLabel))
+	mac.Write([]byte(key))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:KeyIDLength]
+}
+
+// DerivedKeyID tells whether a key ID comes from KeyID. Entries hashed before took
+// the first 8 characters of the key as its ID, or the whole key when it was shorter.
+func DerivedKeyID(keyID string) bool {
+	return len(keyID) == KeyIDLength
+}
+
+// MaskKeyID hides all but the first four characters of a key ID for logging.
+func MaskKeyID(keyID string) string {
+	if len(keyID) <= 4 {
+		return "****"
+	}
+	return keyID[:4] + "****"
+}
+
+// MaskKey returns the masked key ID of a raw key, the only form a key may be logged in.
+func MaskKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	return MaskKeyID(KeyID(key))
+}
+
+// Generate returns a new random key.
+func Generate() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < GeneratedKeyLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(alphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// Hash returns the salted hash entry of a raw key.
+func Hash(key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("apikey: empty key")
+	}
+	if len(key) < MinKeyLength {
+		return "", fmt.Errorf("apikey: key shorter than %d characters, issue a new one with mint-key", MinKeyLength)
+	}
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return format(KeyID(key), salt, digest(salt, key)), nil
+}
+
+// Parse reads a hash entry produced by Hash.
+func Parse(entry string) (Credential, error) {
+	parts := strings.Split(entry, "$")
+	if len(parts) != 4 || parts[0] == "" || parts[1] != algorithm {
+		return Credential{}, fmt.Errorf("apikey: malformed hash entry")
+	}
+	salt, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(salt) == 0 {
+		return Credential{}, fmt.Errorf("apikey: malformed salt")
+	}
+	sum, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil || len(sum) != sha256.Size {
+		return Credential{}, fmt.Errorf("apikey: malformed digest")
+	}
+	return Credential{KeyID: parts[0], salt: salt, digest: sum}, nil
+}
+
+// Matches compares a raw key against the credential in constant time.
+func (c Credential) Matches(key string) bool {
+	return subtle.ConstantTimeCompare(digest(c.salt, key), c.digest) == 1
+}
+
+func digest(salt []byte, key string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(key))
+	return h.Sum(nil)
+}
+
+func format(keyID string, salt, sum []byte) string {
+	return keyID + "$" + algorithm + "$" + base64.RawURLEncoding.EncodeToString(salt) + "$" + base64.RawURLEncoding.EncodeToString(sum)
+}
@@ -0,0 +1,68 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashAndMatch(t *testing.T) {
+	key, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != GeneratedKeyLength {
+		t.Fatalf("generated key length = %d, want %d", len(key), GeneratedKeyLength)
+	}
+
+	entry, err := Hash(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credential, err := Parse(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credential.KeyID != KeyID(key) || !DerivedKeyID(credential.KeyID) {
+		t.Errorf("KeyID = %q, want %q", credential.KeyID, KeyID(key))
+	}
+	if strings.HasPrefix(key, credential.KeyID[:4]) {
+		t.Errorf("key ID %q gives away the start of the key", credential.KeyID)
+	}
+	if !credential.Matches(key) {
+		t.Error("credential does not match its own key")
+	}
+	if credential.Matches(key + "x") {
+		t.Error("credential matches a different key")
+	}
+
+	again, _ := Hash(key)
+	if again == entry {
+		t.Error("hashing the same key twice should use a different salt")
+	}
+}
+
+func TestHashRejectsShortKeys(t *testing.T) {
+	for _, key := range []string{"", "Common", "7FFE4821B9B2D9BCACBC188"} {
+		if _, err := Hash(key); err == nil {
+			t.Errorf("Hash(%q) expected error", key)
+		}
+	}
+}
+
+func TestParseRejectsMalformed(t *testing.T) {
+	for _, entry := range []string{"", "plain-key", "id$md5$c2FsdA$ZGlnZXN0", "$s256$c2FsdA$ZGlnZXN0"} {
+		if _, err := Parse(entry); err == nil {
+			t.Errorf("Parse(%q) expected error", entry)
+		}
+	}
+}
+
+func TestMaskKey(t *testing.T) {
+	key := "IQARRf8ZhKChCN0ODn0BHQF4cBodzi7z"
+	if got, want := MaskKey(key), KeyID(key)[:4]+"****"; got != want || strings.HasPrefix(got, "IQAR") {
+		t.Errorf("MaskKey = %q, want %q", got, want)
+	}
+	if got := MaskKey(""); got != "" {
+		t.Errorf("MaskKey(\"\") = %q, want empty", got)
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,20 +17,237 @@ type Config struct {
 	Destinations map[string]Destination `yaml:"destinations" json:"destinations"`
 	Routes       map[string]Route       `yaml:"routes" json:"routes"`
 	ELKPath      string                 `yaml:"elkPath"`
+	ELK          ELKConfig              `yaml:"elk"`
+	TCP          TCPConfig              `yaml:"tcp"`
+	APIKeyPolicy APIKeyPolicyConfig     `yaml:"apiKeyPolicy"`
+	Admin        AdminConfig            `yaml:"admin"`
+	RateLimit    RateLimitConfig        `yaml:"rateLimit"`
+	Masking      MaskingConfig          `yaml:"masking"`
+	Audit        AuditConfig            `yaml:"audit"`
+	Idempotency  IdempotencyConfig      `yaml:"idempotency"`
+	Replay       ReplayConfig           `yaml:"replay"`
+	Tracing      TracingConfig          `yaml:"tracing"`
+	Health       HealthConfig           `yaml:"health"`
+	Cache        CacheConfig            `yaml:"cache"`
+	Coalesce     CoalesceConfig         `yaml:"coalesce"`
 }
 type ServerConfig struct {
-	Port string `yaml:"port"`
-	Mode string `yaml:"mode"`
+	Port           string   `yaml:"port"`
+	Mode           string   `yaml:"mode"`
+	TrustedProxies []string `yaml:"trustedProxies"` // proxies allowed to set X-Forwarded-For, none by default
+	RequestIDNode  int      `yaml:"requestIDNode"`  // node in generated request IDs, unique per instance; -1 derives it from the host name
+
+	ReadTimeout     time.Duration `yaml:"readTimeout"`     // reading a whole request, 0 = no limit
+	WriteTimeout    time.Duration `yaml:"writeTimeout"`    // from the end of the request to the end of the response, must cover the System I calls
+	IdleTimeout     time.Duration `yaml:"idleTimeout"`     // keep-alive connections between requests
+	DrainDelay      time.Duration `yaml:"drainDelay"`      // on SIGTERM, /readyz answers 503 this long before new connections are refused
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"` // then requests and System I calls in flight get this long to finish
+}
+// ELKConfig controls the background writer of the ELK log, to files in ELKPath or,
+// with Sink elasticsearch or logstash, straight to the ELK stack.
+type ELKConfig struct {
+	Sink          string        `yaml:"sink"`          // "file", "elasticsearch" or "logstash"
+	QueueSize     int           `yaml:"queueSize"`     // log entries waiting for the writer
+	OnFull        string        `yaml:"onFull"`        // "drop" the entry or "block" the request while the queue is full
+	BlockTimeout  time.Duration `yaml:"blockTimeout"`  // longest wait with onFull: block before dropping, 0 waits as long as needed
+	BatchSize     int           `yaml:"batchSize"`     // fsync after this many entries
+	FlushInterval time.Duration `yaml:"flushInterval"` // and at least this often
+	MaxSizeMB     int           `yaml:"maxSizeMB"`     // rotate a day's file at this size, 0 rotates by date only
+	MaxAge        time.Duration `yaml:"maxAge"`        // delete log files older than this, 0 keeps them
+	CompressAfter time.Duration `yaml:"compressAfter"` // gzip rotated files not written for this long, 0 never compresses
+
+	URL          string        `yaml:"url"`          // Elasticsearch base URL, or Logstash http(s):// or tcp:// input
+	Index        string        `yaml:"index"`        // index prefix, documents go to <index>-yyyy.mm.dd
+	Username     string        `yaml:"username"`     // basic auth for Elasticsearch or Logstash http
+	Password     string        `yaml:"password"`
+	Timeout      time.Duration `yaml:"timeout"`      // per bulk request
+	RetryBackoff time.Duration `yaml:"retryBackoff"` // first retry of a spooled batch, doubled while the sink stays down
+	SpoolPath    string        `yaml:"spoolPath"`    // batches wait here while the sink is unreachable
+	SpoolMaxMB   int           `yaml:"spoolMaxMB"`   // batches beyond this are dropped
+}
+type TCPConfig struct {
+	DialTimeout      time.Duration `yaml:"dialTimeout"`
+	ReadWriteTimeout time.Duration `yaml:"readWriteTimeout"`
+	MaxFanOut        int           `yaml:"maxFanOut"` // concurrent System I calls of one request, e.g. one per card
+	LogPayloads      bool          `yaml:"logPayloads"` // log the masked System I messages with each exchange, at debug level
+}
+type APIKeyPolicyConfig struct {
+	ExpiryWarning time.Duration `yaml:"expiryWarning"` // warn this long before a key expires
+	CheckInterval time.Duration `yaml:"checkInterval"`
+}
+type AdminConfig struct {
+	Keys           []AdminKey `yaml:"keys"`           // operators allowed to call /Admin
+	AuditPath      string     `yaml:"auditPath"`      // append-only record of API client changes
+	Port           string     `yaml:"port"`           // admin port with the introspection endpoints and pprof, empty disables it
+	RecentFailures int        `yaml:"recentFailures"` // failed System I exchanges kept for /Admin/Failures
+}
+// AdminKey is an operator key, hashed like client keys (see mint-key).
+type AdminKey struct {
+	Name string `yaml:"name"`
+	Hash string `yaml:"hash"`
+}
+// RateLimitConfig limits requests per API client (by clientName) and per client on a route.
+// Route limits are per client too, so one partner cannot use up a route for the others.
+type RateLimitConfig struct {
+	Enabled bool                       `yaml:"enabled"`
+	Default RateLimit                  `yaml:"default"` // for clients without an entry in Clients
+	Clients map[string]ClientRateLimit `yaml:"clients"`
+	Routes  map[string]RateLimit       `yaml:"routes"` // METHOD:/path -> limit per client
+}
+// RateLimit is a token bucket refilled at Rate requests per second holding up to Burst,
+// plus a quota of requests per calendar day. Zero values mean unlimited.
+type RateLimit struct {
+	Rate       float64 `yaml:"rate"`
+	Burst      int     `yaml:"burst"`
+	DailyQuota int     `yaml:"dailyQuota"`
+}
+type ClientRateLimit struct {
+	RateLimit `yaml:",inline"`
+	Routes    map[string]RateLimit `yaml:"routes"` // override Routes for this client
+}
+// MaskingConfig controls what personal data may appear in the ELK and application logs.
+type MaskingConfig struct {
+	PAN     PANMask                 `yaml:"pan"`
+	Fields  map[string]string       `yaml:"fields"`  // JSON field name (any case) -> strategy, see pkg/mask
+	Layouts map[string]MaskLayout   `yaml:"layouts"` // METHOD:/path -> fields of its System I messages
+}
+// PANMask is how many leading and trailing digits of a card number stay readable.
+type PANMask struct {
+	First int `yaml:"first"`
+	Last  int `yaml:"last"`
+}
+// MaskLayout locates personal data in the fixed-length messages of a route.
+type MaskLayout struct {
+	Request  []MaskField `yaml:"request"`
+	Response []MaskField `yaml:"response"`
+}
+// MaskField is a field at Offset runes after the 123-char header. Every > 0 repeats it
+// every that many runes until the message ends, for lists such as cards.
+// The strategy is the one of Name in MaskingConfig.Fields, "full" when it has none.
+type MaskField struct {
+	Name   string `yaml:"name"`
+	Offset int    `yaml:"offset"`
+	Length int    `yaml:"length"`
+	Every  int    `yaml:"every"`
+}
+// AuditConfig is the hash-chained journal of requests that change customer state
+// in System I, see pkg/journal.
+type AuditConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Path    string   `yaml:"path"`
+	Routes  []string `yaml:"routes"` // METHOD:/path of the journaled routes
+}
+// IdempotencyConfig stores the response to requests sent with an Idempotency-Key header,
+// so that retries are answered without calling System I again, see pkg/idempotency.
+type IdempotencyConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Store       string        `yaml:"store"` // "memory" or "file"
+	Path        string        `yaml:"path"`  // directory of the file store
+	Key         string        `yaml:"key"`   // base64 256-bit AES key encrypting the file store
+	TTL         time.Duration `yaml:"ttl"`
+	WaitTimeout time.Duration `yaml:"waitTimeout"` // how long a retry waits for the first request to finish
+	Routes      []string      `yaml:"routes"`      // METHOD:/path accepting the header
+}
+// CacheConfig caches the successful responses of read-only routes whose data changes
+// rarely, per request body and client permission scope, see pkg/respcache.
+type CacheConfig struct {
+	Enabled    bool                     `yaml:"enabled"`
+	MaxEntries int                      `yaml:"maxEntries"` // least recently used responses are evicted beyond this
+	Routes     map[string]time.Duration `yaml:"routes"`     // METHOD:/path -> how long its responses are served from the cache
+}
+// CoalesceConfig lets concurrent identical requests to routes flagged ReadOnly share
+// one System I call, see CoalesceMiddleware.
+type CoalesceConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+// ReplayConfig rejects requests that reuse an Api-RequestID of their client, or whose
+// timestamp header is too far from the server clock, see pkg/replay.
+type ReplayConfig struct {
+	Enabled bool                    `yaml:"enabled"`
+	Default ReplayPolicy            `yaml:"default"` // for clients without an entry in Clients
+	Clients map[string]ReplayPolicy `yaml:"clients"` // clientName -> policy
+}
+// ReplayPolicy is the check for one client. Zero values turn the checks off.
+type ReplayPolicy struct {
+	Window           time.Duration `yaml:"window"`           // how long a request ID stays taken
+	MaxSkew          time.Duration `yaml:"maxSkew"`          // allowed distance of Api-Timestamp from the server clock
+	RequireTimestamp bool          `yaml:"requireTimestamp"` // reject requests without Api-Timestamp
+}
+// TracingConfig exports OpenTelemetry spans of the API and its System I calls over
+// OTLP/HTTP, see pkg/tracing.
+type TracingConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	Endpoint    string            `yaml:"endpoint"`    // OTLP/HTTP traces URL of the collector
+	Headers     map[string]string `yaml:"headers"`     // sent with every export, e.g. a token of the trace backend
+	ServiceName string            `yaml:"serviceName"` // service.name of the spans
+	SampleRatio float64           `yaml:"sampleRatio"` // share of new traces kept; a sampled inbound traceparent is always kept
+	Timeout     time.Duration     `yaml:"timeout"`     // per export
+}
+// HealthConfig controls the background probes of the System I ports behind /readyz,
+// see pkg/health.
+type HealthConfig struct {
+	ProbeInterval  time.Duration `yaml:"probeInterval"`
+	ProbeTimeout   time.Duration `yaml:"probeTimeout"`
+	RequiredRoutes []string      `yaml:"requiredRoutes"` // METHOD:/path that need a healthy port to be ready, every route when empty
+	Echo           EchoProbe     `yaml:"echo"`
+}
+// EchoProbe sends a System I request to each port instead of only connecting to it.
+type EchoProbe struct {
+	Enabled bool     `yaml:"enabled"`
+	System  string   `yaml:"system"`  // header fields of the ping service
+	Service string   `yaml:"service"`
+	Format  string   `yaml:"format"`
+	Body    string   `yaml:"body"`    // fixed-length data after the header
+	OKCodes []string `yaml:"okCodes"` // response codes of a healthy port besides a blank one
 }
 type LoggerConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
 }
 type APIKey struct {
-	Key         []string   `yaml:"key"`
-	ClientName  string   `yaml:"clientName"`
-	Status      string   `yaml:"status"`
-	Permissions []string `yaml:"permissions"`
+	ID          string          `yaml:"id" json:"id"` // stable identifier used by the admin API
+	Keys        []KeyCredential `yaml:"keys" json:"keys"`
+	LegacyKey   []string        `yaml:"key" json:"key,omitempty"` // plain-text keys, rejected by Validate
+	ClientName  string   `yaml:"clientName" json:"clientName"`
+	Status      string   `yaml:"status" json:"status"`
+	Roles       []string     `yaml:"roles" json:"roles,omitempty"`           // roles defined in APIClients.Roles
+	Permissions []Permission `yaml:"permissions" json:"permissions,omitempty"` // granted on top of the roles
+	AllowedCIDRs []string `yaml:"allowedCIDRs" json:"allowedCIDRs,omitempty"` // caller IPs allowed to use the keys, any when empty
+	Contact     string   `yaml:"contact" json:"contact,omitempty"`            // partner contact named in expiry warnings
+	FieldEncryption *FieldEncryption `yaml:"fieldEncryption" json:"fieldEncryption,omitempty"` // encrypt sensitive response fields for this client
+}
+// FieldEncryption is how sensitive response fields, such as the full card number of
+// MobileFullPAN, are encrypted for a client, see pkg/fieldcrypt.
+type FieldEncryption struct {
+	Method    string `json:"method"`              // "jwe" or "aes-gcm"
+	PublicKey string `json:"publicKey,omitempty"` // PEM RSA public key of the client, for jwe
+	SharedKey string `json:"sharedKey,omitempty"` // base64 256-bit key shared with the client, for aes-gcm
+	KeyID     string `json:"keyId,omitempty"`     // identifies the key to the client
+}
+// APIClients is the content of apikeys.json: named roles and the clients using them.
+type APIClients struct {
+	Roles   map[string]Role `json:"roles"`
+	Clients []APIKey        `json:"clients"`
+}
+// Role is a named set of permissions shared by several clients.
+type Role struct {
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+}
+// Permission grants a METHOD:/path pattern, e.g. POST:/Api/Mobile/* (see pkg/permission).
+// Constraints optionally restrict top-level body fields to a set of values,
+// e.g. {"Channel": ["M"]}. Without constraints it is written as a plain string.
+type Permission struct {
+	Route       string              `json:"route"`
+	Constraints map[string][]string `json:"constraints,omitempty"`
+}
+// KeyCredential is one salted key hash of a client, see pkg/apikey.
+// NotBefore and ExpiresAt are optional; overlapping windows let an old and a new key
+// stay valid together during a rotation.
+type KeyCredential struct {
+	Hash      string     `yaml:"hash" json:"hash"`
+	NotBefore *time.Time `yaml:"notBefore" json:"notBefore,omitempty"`
+	ExpiresAt *time.Time `yaml:"expiresAt" json:"expiresAt,omitempty"`
 }
 type Destination struct {
 	Type   string              `json:"type"`
@@ -40,48 +260,269 @@ type Route struct {
 	SystemV1  		string `json:"SystemV1"`
 	SystemV2  		string `json:"SystemV2"`
 	Service 		string `json:"Service"`
+	PortKey 		string `json:"PortKey"`
 	Format  		string `json:"Format"`
 	FormatV1  		string `json:"FormatV1"`
 	FormatV2  		string `json:"FormatV2"`
 	RequestLength   string `json:"RequestLength"`
+	ReadOnly        bool   `json:"ReadOnly,omitempty"` // an inquiry that changes nothing in System I, so identical requests may share a call
 }
 type DestinationsAndRoutes struct {
 	Destinations map[string]Destination `json:"destinations"`
 	Routes       map[string]Route       `json:"routes"`
 }
 
-func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+// ReadOnlyRoutes returns the keys of the routes flagged ReadOnly, sorted.
+func (dr *DestinationsAndRoutes) ReadOnlyRoutes() []string {
+	var routes []string
+	for _, routeKey := range sortedKeys(dr.Routes) {
+		if dr.Routes[routeKey].ReadOnly {
+			routes = append(routes, routeKey)
+		}
+	}
+	return routes
+}
+
+// defaultConfig holds the values used when neither the files nor the environment set a key.
+func defaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			RequestIDNode:   -1,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			DrainDelay:      5 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		ELK: ELKConfig{
+			Sink:          "file",
+			QueueSize:     10000,
+			OnFull:        "drop",
+			BlockTimeout:  100 * time.Millisecond,
+			BatchSize:     256,
+			FlushInterval: time.Second,
+			MaxSizeMB:     512,
+			MaxAge:        30 * 24 * time.Hour,
+			CompressAfter: time.Hour,
+			Index:         "connectorapi",
+			Timeout:       10 * time.Second,
+			RetryBackoff:  500 * time.Millisecond,
+			SpoolPath:     "elk/spool",
+			SpoolMaxMB:    1024,
+		},
+		TCP: TCPConfig{
+			DialTimeout:      5 * time.Second,
+			ReadWriteTimeout: 10 * time.Second,
+			MaxFanOut:        4,
+		},
+		APIKeyPolicy: APIKeyPolicyConfig{
+			ExpiryWarning: 14 * 24 * time.Hour,
+			CheckInterval: time.Hour,
+		},
+		Admin: AdminConfig{
+			AuditPath:      "configs/apikeys.audit.log",
+			RecentFailures: 100,
+		},
+		Masking: MaskingConfig{
+			PAN: PANMask{First: 6, Last: 4},
+			Fields: map[string]string{
+				"CardNo":       "pan",
+				"CreditCardNo": "pan",
+			},
+		},
+		Audit: AuditConfig{
+			Enabled: true,
+			Path:    "audit/journal.log",
+			Routes: []string{
+				"POST:/Api/Consent/UpdateConsent",
+				"POST:/Api/Collection/CollectionLog",
+				"POST:/Api/Agreement/UpdateStatus",
+				"POST:/Api/Application/SubmitCardApplication",
+				"POST:/Api/application/submitloanapplication",
+			},
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:     true,
+			Store:       "memory",
+			Path:        "idempotency",
+			TTL:         24 * time.Hour,
+			WaitTimeout: 30 * time.Second,
+			Routes: []string{
+				"POST:/Api/Consent/UpdateConsent",
+				"POST:/Api/Collection/CollectionLog",
+				"POST:/Api/Agreement/UpdateStatus",
+				"POST:/Api/Application/SubmitCardApplication",
+				"POST:/Api/application/submitloanapplication",
+			},
+		},
+		Cache: CacheConfig{
+			MaxEntries: 10000,
+		},
+		Health: HealthConfig{
+			ProbeInterval: 10 * time.Second,
+			ProbeTimeout:  2 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Endpoint:    "http://localhost:4318/v1/traces",
+			ServiceName: "connectorapi-go",
+			SampleRatio: 1,
+			Timeout:     10 * time.Second,
+		},
+	}
+}
+
+// Load reads the base config, merges the overlay of env on top and applies CONNECTOR_* overrides.
+func Load(path string, env string) (*Config, error) {
+	config := defaultConfig()
+	doc, err := layeredDocument(path, env, &config, yaml.Unmarshal, yaml.Marshal)
 	if err != nil {
 		return nil, err
 	}
-	var config Config
-	if err = yaml.Unmarshal(data, &config); err != nil {
+	if err := remarshal(doc, &config, yaml.Marshal, yaml.Unmarshal); err != nil {
 		return nil, err
 	}
 	return &config, nil
 }
 
-func LoadAPIKeys(path string) ([]APIKey, error) {
-	data, err := os.ReadFile(path)
+// APIKeysFile returns the API key file in effect for env: its overlay when one exists,
+// otherwise path itself. The admin API writes changes back to this file.
+func APIKeysFile(path string, env string) string {
+	if overlay := OverlayPath(path, env); overlay != "" {
+		if _, err := os.Stat(overlay); err == nil {
+			return overlay
+		}
+	}
+	return path
+}
+
+// LoadAPIKeys reads the API key file. An overlay for env replaces the base file entirely.
+func LoadAPIKeys(path string, env string) (*APIClients, error) {
+	file := APIKeysFile(path, env)
+	data, err := os.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
-	var apiKeys []APIKey
-	if err := json.Unmarshal(data, &apiKeys); err != nil {
+	var apiClients APIClients
+	if err := json.Unmarshal(data, &apiClients); err != nil {
 		return nil, err
 	}
-	return apiKeys, nil
+	return &apiClients, nil
 }
 
-func LoadDestinationsAndRoutes(path string) (*DestinationsAndRoutes, error) {
-	data, err := os.ReadFile(path)
+// UnmarshalJSON also accepts the former layout, a plain array of clients without roles.
+func (a *APIClients) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*a = APIClients{}
+		return json.Unmarshal(trimmed, &a.Clients)
+	}
+	type plain APIClients
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// Permissions returns every permission of client, its roles' followed by its own.
+// Unknown roles are skipped, Validate reports them.
+func (a *APIClients) Permissions(client APIKey) []Permission {
+	var permissions []Permission
+	for _, name := range client.Roles {
+		if role, ok := a.Roles[name]; ok {
+			permissions = append(permissions, role.Permissions...)
+		}
+	}
+	return append(permissions, client.Permissions...)
+}
+
+func (p Permission) MarshalJSON() ([]byte, error) {
+	if len(p.Constraints) == 0 {
+		return json.Marshal(p.Route)
+	}
+	type plain Permission
+	return json.Marshal(plain(p))
+}
+
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	var route string
+	if err := json.Unmarshal(data, &route); err == nil {
+		*p = Permission{Route: route}
+		return nil
+	}
+	type plain Permission
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// LoadDestinationsAndRoutes reads destinations and routes with the same layering as Load.
+func LoadDestinationsAndRoutes(path string, env string) (*DestinationsAndRoutes, error) {
+	var dr DestinationsAndRoutes
+	doc, err := layeredDocument(path, env, &dr, json.Unmarshal, json.Marshal)
 	if err != nil {
 		return nil, err
 	}
-	var dr DestinationsAndRoutes
-	if err := json.Unmarshal(data, &dr); err != nil {
+	if err := remarshal(doc, &dr, json.Marshal, json.Unmarshal); err != nil {
 		return nil, err
 	}
 	return &dr, nil
 }
+
+// Effective returns a configuration value as a generic document with secrets redacted, for printing.
+// Config is rendered with its yaml keys, everything else with its json keys.
+func Effective(v interface{}) interface{} {
+	return Redact(genericDocument(v))
+}
+
+// genericDocument converts a configuration value to maps and slices, nil when it cannot.
+func genericDocument(v interface{}) interface{} {
+	marshal, unmarshal := marshalFunc(json.Marshal), unmarshalFunc(json.Unmarshal)
+	if _, ok := v.(*Config); ok {
+		marshal, unmarshal = yaml.Marshal, yaml.Unmarshal
+	}
+	var doc interface{}
+	if err := remarshal(v, &doc, marshal, unmarshal); err != nil {
+		return nil
+	}
+	return doc
+}
+
+type unmarshalFunc func([]byte, interface{}) error
+type marshalFunc func(interface{}) ([]byte, error)
+
+// layeredDocument builds defaults <- base file <- env overlay <- environment variables as a generic document.
+func layeredDocument(path string, env string, defaults interface{}, unmarshal unmarshalFunc, marshal marshalFunc) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	if err := remarshal(defaults, &doc, marshal, unmarshal); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	base := map[string]interface{}{}
+	if err := unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	mergeMaps(doc, base)
+
+	overlayData, err := readOverlay(path, env)
+	if err != nil {
+		return nil, err
+	}
+	if overlayData != nil {
+		overlay := map[string]interface{}{}
+		if err := unmarshal(overlayData, &overlay); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", OverlayPath(path, env), err)
+		}
+		mergeMaps(doc, overlay)
+	}
+
+	if err := applyEnvOverrides(doc, os.Environ()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func remarshal(in interface{}, out interface{}, marshal marshalFunc, unmarshal unmarshalFunc) error {
+	data, err := marshal(in)
+	if err != nil {
+		return err
+	}
+	return unmarshal(data, out)
+}
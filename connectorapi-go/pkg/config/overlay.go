@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables that override configuration keys,
+// e.g. CONNECTOR_SERVER_PORT or CONNECTOR_DESTINATIONS_SYSTEMI_IP.
+const EnvPrefix = "CONNECTOR_"
+
+const redactedValue = "******"
+
+// secretKeys are configuration keys whose values are never printed. headers are the
+// tracing export headers, which usually carry a token of the trace backend.
+var secretKeys = []string{"apikey", "key", "keys", "secret", "password", "token", "sharedkey", "headers"}
+
+// envSelector chooses the environment overlay and addresses no configuration key.
+const envSelector = EnvPrefix + "ENV"
+
+// OverlayPath returns the environment overlay of a configuration file,
+// e.g. configs/config.yaml with env "uat" becomes configs/config.uat.yaml.
+func OverlayPath(path, env string) string {
+	if env == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// readOverlay reads the overlay of path for env, returning nil when there is none.
+func readOverlay(path, env string) ([]byte, error) {
+	overlay := OverlayPath(path, env)
+	if overlay == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(overlay)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read overlay %s: %w", overlay, err)
+	}
+	return data, nil
+}
+
+// mergeMaps deep-merges src into dst. Maps are merged key by key, any other value replaces.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// applyEnvOverrides sets every CONNECTOR_* variable that addresses an existing key of doc.
+// Path segments are matched case-insensitively, ignoring underscores inside key names.
+func applyEnvOverrides(doc map[string]interface{}, environ []string) error {
+	sort.Strings(environ)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		parts := strings.Split(strings.ToLower(strings.TrimPrefix(name, EnvPrefix)), "_")
+		// Variables that match no key may belong to another configuration document,
+		// UnmatchedEnvOverrides reports those matching none once all are loaded.
+		if _, err := setPath(doc, parts, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// UnmatchedEnvOverrides returns the CONNECTOR_* variables of environ that address no key
+// of any of docs, e.g. a misspelled CONNECTOR_SERVER_PROT, which would otherwise be
+// ignored silently. docs are the loaded Config and DestinationsAndRoutes.
+func UnmatchedEnvOverrides(environ []string, docs ...interface{}) []string {
+	var nodes []map[string]interface{}
+	for _, v := range docs {
+		if node, ok := genericDocument(v).(map[string]interface{}); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	var unmatched []string
+	for _, kv := range environ {
+		name, _, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) || name == envSelector {
+			continue
+		}
+		parts := strings.Split(strings.ToLower(strings.TrimPrefix(name, EnvPrefix)), "_")
+		matched := false
+		for _, node := range nodes {
+			if hasPath(node, parts) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, name)
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// hasPath reports whether setPath would find a key for parts.
+func hasPath(node map[string]interface{}, parts []string) bool {
+	for i := 1; i <= len(parts); i++ {
+		candidate := strings.Join(parts[:i], "")
+		for key, current := range node {
+			if normalizeKey(key) != candidate {
+				continue
+			}
+			if i == len(parts) {
+				return true
+			}
+			if child, ok := current.(map[string]interface{}); ok && hasPath(child, parts[i:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func setPath(node map[string]interface{}, parts []string, value string) (bool, error) {
+	for i := 1; i <= len(parts); i++ {
+		candidate := strings.Join(parts[:i], "")
+		for key, current := range node {
+			if normalizeKey(key) != candidate {
+				continue
+			}
+			if i == len(parts) {
+				coerced, err := coerceValue(current, value)
+				if err != nil {
+					return false, err
+				}
+				node[key] = coerced
+				return true, nil
+			}
+			if child, ok := current.(map[string]interface{}); ok {
+				if found, err := setPath(child, parts[i:], value); found || err != nil {
+					return found, err
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// coerceValue converts an environment string to the type of the value it replaces.
+func coerceValue(current interface{}, value string) (interface{}, error) {
+	switch current.(type) {
+	case bool:
+		return strconv.ParseBool(value)
+	case int:
+		return strconv.Atoi(value)
+	case int64:
+		return strconv.ParseInt(value, 10, 64)
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	case []interface{}:
+		var list []interface{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	case map[string]interface{}:
+		return nil, fmt.Errorf("cannot override a section with a single value")
+	default:
+		return value, nil
+	}
+}
+
+// Redact returns a copy of a decoded configuration document with secret values masked.
+func Redact(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			if isSecretKey(k) {
+				out[k] = redactedValue
+				continue
+			}
+			out[k] = Redact(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = Redact(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func isSecretKey(key string) bool {
+	normalized := normalizeKey(key)
+	for _, secret := range secretKeys {
+		if normalized == secret {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAppliesOverlayAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "server:\n  port: \"8082\"\n  mode: \"debug\"\nelkPath: \"elk/log/\"\n")
+	writeFile(t, dir, "config.uat.yaml", "server:\n  mode: \"release\"\n")
+	t.Setenv("CONNECTOR_SERVER_PORT", "9090")
+	t.Setenv("CONNECTOR_TCP_READ_WRITE_TIMEOUT", "30s")
+
+	cfg, err := Load(path, "uat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Mode != "release" {
+		t.Errorf("Server.Mode = %q, want overlay value release", cfg.Server.Mode)
+	}
+	if cfg.Server.Port != "9090" {
+		t.Errorf("Server.Port = %q, want env value 9090", cfg.Server.Port)
+	}
+	if cfg.ELKPath != "elk/log/" {
+		t.Errorf("ELKPath = %q, want base value", cfg.ELKPath)
+	}
+	if cfg.TCP.DialTimeout != 5*time.Second || cfg.TCP.ReadWriteTimeout != 30*time.Second {
+		t.Errorf("TCP = %+v, want default dial timeout and env read/write timeout", cfg.TCP)
+	}
+}
+
+func TestLoadDestinationsAndRoutesEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "destinations_routes.json", `{
+		"destinations": {"systemI": {"type": "tcp", "ip": "192.168.129.2", "apiKey": "secret", "ports": {"MyCard": ["40110"]}}},
+		"routes": {"POST:/Api/SelfService/MyCard": {"System": "MOB_APP", "PortKey": "MyCard"}}
+	}`)
+	t.Setenv("CONNECTOR_DESTINATIONS_SYSTEMI_IP", "10.1.1.1")
+	t.Setenv("CONNECTOR_DESTINATIONS_SYSTEMI_PORTS_MYCARD", "40111, 40112")
+
+	dr, err := LoadDestinationsAndRoutes(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemI := dr.Destinations[SystemIDestination]
+	if systemI.IP != "10.1.1.1" {
+		t.Errorf("IP = %q, want 10.1.1.1", systemI.IP)
+	}
+	if ports := systemI.Ports["MyCard"]; len(ports) != 2 || ports[0] != "40111" || ports[1] != "40112" {
+		t.Errorf("Ports = %v, want [40111 40112]", ports)
+	}
+
+	redacted := Effective(dr).(map[string]interface{})
+	destination := redacted["destinations"].(map[string]interface{})[SystemIDestination].(map[string]interface{})
+	if destination["apiKey"] != redactedValue {
+		t.Errorf("apiKey = %v, want redacted", destination["apiKey"])
+	}
+}
+
+func TestUnmatchedEnvOverrides(t *testing.T) {
+	cfg := defaultConfig()
+	dr := &DestinationsAndRoutes{Destinations: map[string]Destination{SystemIDestination: {IP: "192.168.129.2", Ports: map[string][]string{"MyCard": {"40110"}}}}}
+	environ := []string{
+		"CONNECTOR_SERVER_PORT=9090",
+		"CONNECTOR_SERVER_PROT=9090",
+		"CONNECTOR_ENV=uat",
+		"CONNECTOR_IDEMPOTENCY_KEY=c2VjcmV0",
+		"CONNECTOR_DESTINATIONS_SYSTEMI_PORTS_MYCARD=40111",
+		"CONNECTOR_DESTINATIONS_SYSTEMI_PORTS_YOURCARD=40111",
+		"CONNECTOR_TRACING_ENDPOINT_URL=http://otel:4318",
+		"PATH=/usr/bin",
+	}
+
+	got := UnmatchedEnvOverrides(environ, &cfg, dr)
+
+	want := []string{"CONNECTOR_DESTINATIONS_SYSTEMI_PORTS_YOURCARD", "CONNECTOR_SERVER_PROT", "CONNECTOR_TRACING_ENDPOINT_URL"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatched = %v, want %v", got, want)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	doc := map[string]interface{}{
+		"server":  map[string]interface{}{"port": "8082"},
+		"tracing": map[string]interface{}{"endpoint": "http://otel:4318", "headers": map[string]interface{}{"Authorization": "Bearer abc"}},
+		"clients": []interface{}{
+			map[string]interface{}{"clientName": "Partner", "fieldEncryption": map[string]interface{}{"method": "aes-gcm", "sharedKey": "c2VjcmV0", "keyId": "k1"}},
+		},
+		"destinations": map[string]interface{}{"systemI": map[string]interface{}{"apiKey": "secret", "ip": "192.168.129.2"}},
+	}
+
+	got := Redact(doc)
+
+	want := map[string]interface{}{
+		"server":  map[string]interface{}{"port": "8082"},
+		"tracing": map[string]interface{}{"endpoint": "http://otel:4318", "headers": redactedValue},
+		"clients": []interface{}{
+			map[string]interface{}{"clientName": "Partner", "fieldEncryption": map[string]interface{}{"method": "aes-gcm", "sharedKey": redactedValue, "keyId": "k1"}},
+		},
+		"destinations": map[string]interface{}{"systemI": map[string]interface{}{"apiKey": redactedValue, "ip": "192.168.129.2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Redact = %v, want %v", got, want)
+	}
+}
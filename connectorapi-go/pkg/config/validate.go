@@ -0,0 +1,399 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectorapi-go/pkg/apikey"
+	"connectorapi-go/pkg/fieldcrypt"
+	"connectorapi-go/pkg/permission"
+	"connectorapi-go/pkg/reqid"
+)
+
+// SystemIDestination is the destination every TCP service dials.
+const SystemIDestination = "systemI"
+
+type Severity string
+
+const (
+	SeverityError   Severity = "ERROR"
+	SeverityWarning Severity = "WARN"
+)
+
+// Issue is a single finding of the configuration linter.
+type Issue struct {
+	Severity Severity
+	Check    string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("[%s] %s: %s", i.Severity, i.Check, i.Message)
+}
+
+// ValidationReport collects every issue found while cross-checking the configuration.
+type ValidationReport struct {
+	Issues []Issue
+}
+
+// Add records an issue found by check.
+func (r *ValidationReport) Add(severity Severity, check string, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// HasErrors reports whether any fatal issue was found.
+func (r *ValidationReport) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns only the fatal issues.
+func (r *ValidationReport) Errors() []Issue {
+	var errs []Issue
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+// ValidationScope is what the settings of a feature are checked against.
+type ValidationScope struct {
+	Routes   *DestinationsAndRoutes
+	Clients  *APIClients
+	Handlers map[string]bool // served endpoints, METHOD:/path
+}
+
+// ClientNames returns the clientName of every API client.
+func (s ValidationScope) ClientNames() map[string]bool {
+	names := make(map[string]bool, len(s.Clients.Clients))
+	for _, client := range s.Clients.Clients {
+		names[client.ClientName] = true
+	}
+	return names
+}
+
+// Check validates the settings of one feature. The package of the feature owns its
+// check, Validate runs them after its own.
+type Check func(report *ValidationReport, cfg *Config, scope ValidationScope)
+
+// Validate cross-checks handlers, routes, port pools, API key permissions and the
+// server and admin settings, then runs checks for the settings of the features.
+// handlerRoutes are the served endpoints in the METHOD:/path form used as route keys.
+func Validate(cfg *Config, dr *DestinationsAndRoutes, apiClients *APIClients, handlerRoutes []string, checks ...Check) *ValidationReport {
+	report := &ValidationReport{}
+	if dr == nil {
+		report.Add(SeverityError, "routes", "destinations and routes are not loaded")
+		return report
+	}
+
+	handlers := make(map[string]bool, len(handlerRoutes))
+	for _, h := range handlerRoutes {
+		handlers[h] = true
+	}
+
+	validateDestinations(report, dr.Destinations)
+	usedPorts := validateRoutes(report, dr, handlers)
+
+	for _, h := range sortedKeys(handlers) {
+		if _, ok := dr.Routes[h]; !ok {
+			report.Add(SeverityError, "handlers", "handler %q has no route entry", h)
+		}
+	}
+
+	if systemI, ok := dr.Destinations[SystemIDestination]; ok {
+		for _, portKey := range sortedKeys(systemI.Ports) {
+			if !usedPorts[portKey] {
+				report.Add(SeverityWarning, "ports", "port pool %q is not referenced by any route", portKey)
+			}
+		}
+	}
+
+	if apiClients == nil {
+		apiClients = &APIClients{}
+	}
+	validateRoles(report, apiClients, handlers)
+	validateAPIKeys(report, apiClients.Clients, apiClients.Roles, handlers)
+	if cfg != nil {
+		if cfg.Server.RequestIDNode < -1 || cfg.Server.RequestIDNode > reqid.MaxNode {
+			report.Add(SeverityError, "server", "requestIDNode %d out of range, use 0-%d or -1", cfg.Server.RequestIDNode, reqid.MaxNode)
+		}
+		validateServer(report, cfg.Server, cfg.TCP)
+		validateAdmin(report, cfg.Admin, cfg.Server)
+		validateCoalesce(report, cfg.Coalesce, dr, cfg.Audit, cfg.Idempotency)
+		scope := ValidationScope{Routes: dr, Clients: apiClients, Handlers: handlers}
+		for _, check := range checks {
+			check(report, cfg, scope)
+		}
+	}
+
+	return report
+}
+
+func validateDestinations(report *ValidationReport, destinations map[string]Destination) {
+	if _, ok := destinations[SystemIDestination]; !ok {
+		report.Add(SeverityError, "destinations", "destination %q is not configured", SystemIDestination)
+	}
+
+	for _, name := range sortedKeys(destinations) {
+		destination := destinations[name]
+		if destination.Type != "tcp" {
+			report.Add(SeverityWarning, "destinations", "destination %q has type %q, only tcp is served", name, destination.Type)
+			continue
+		}
+		if strings.TrimSpace(destination.IP) == "" {
+			report.Add(SeverityError, "destinations", "destination %q has no ip", name)
+		}
+		for _, portKey := range sortedKeys(destination.Ports) {
+			ports := destination.Ports[portKey]
+			if len(ports) == 0 {
+				report.Add(SeverityError, "ports", "port pool %q of destination %q is empty", portKey, name)
+			}
+			for _, port := range ports {
+				if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+					report.Add(SeverityError, "ports", "port pool %q of destination %q has invalid port %q", portKey, name, port)
+				}
+			}
+		}
+	}
+}
+
+func validateRoutes(report *ValidationReport, dr *DestinationsAndRoutes, handlers map[string]bool) map[string]bool {
+	usedPorts := make(map[string]bool)
+	systemI, hasSystemI := dr.Destinations[SystemIDestination]
+
+	for _, routeKey := range sortedKeys(dr.Routes) {
+		route := dr.Routes[routeKey]
+		if _, _, ok := splitRouteKey(routeKey); !ok {
+			report.Add(SeverityError, "routes", "route key %q is not in METHOD:/path form", routeKey)
+		}
+		if !handlers[routeKey] {
+			report.Add(SeverityWarning, "routes", "route %q is not served by any handler", routeKey)
+		}
+		if route.PortKey == "" {
+			report.Add(SeverityError, "routes", "route %q has no PortKey", routeKey)
+			continue
+		}
+		usedPorts[route.PortKey] = true
+		if hasSystemI {
+			if _, ok := systemI.Ports[route.PortKey]; !ok {
+				report.Add(SeverityError, "routes", "route %q references unknown port pool %q", routeKey, route.PortKey)
+			}
+		}
+	}
+	return usedPorts
+}
+
+func validateRoles(report *ValidationReport, apiClients *APIClients, handlers map[string]bool) {
+	used := make(map[string]bool)
+	for _, client := range apiClients.Clients {
+		for _, role := range client.Roles {
+			used[role] = true
+		}
+	}
+	for _, name := range sortedKeys(apiClients.Roles) {
+		if !used[name] {
+			report.Add(SeverityWarning, "roles", "role %q is not assigned to any client", name)
+		}
+		if len(apiClients.Roles[name].Permissions) == 0 {
+			report.Add(SeverityWarning, "roles", "role %q grants no permission", name)
+		}
+		validatePermissions(report, "role "+strconv.Quote(name), apiClients.Roles[name].Permissions, handlers)
+	}
+}
+
+func validatePermissions(report *ValidationReport, owner string, permissions []Permission, handlers map[string]bool) {
+	for _, p := range permissions {
+		pattern, err := permission.ParsePattern(p.Route)
+		if err != nil {
+			report.Add(SeverityError, "permissions", "%s has %v", owner, err)
+			continue
+		}
+		for field, values := range p.Constraints {
+			if len(values) == 0 {
+				report.Add(SeverityError, "permissions", "%s constrains %s on %q to no value", owner, field, p.Route)
+			}
+		}
+		if !servesAny(pattern, handlers) {
+			report.Add(SeverityWarning, "permissions", "%s is granted %q which no handler serves", owner, p.Route)
+		}
+	}
+}
+
+func servesAny(pattern permission.Pattern, handlers map[string]bool) bool {
+	for h := range handlers {
+		if method, path, ok := splitRouteKey(h); ok && pattern.Matches(method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAPIKeys(report *ValidationReport, apiKeys []APIKey, roles map[string]Role, handlers map[string]bool) {
+	hashOwners := make(map[string]string)
+	now := time.Now()
+	keyIDOwners := make(map[string]string)
+	ids := make(map[string]bool)
+
+	for i, client := range apiKeys {
+		name := fmt.Sprintf("%s (entry %d)", client.ClientName, i+1)
+
+		if client.ClientName == "" {
+			report.Add(SeverityWarning, "apikeys", "entry %d has no clientName", i+1)
+		}
+		if client.ID != "" {
+			if ids[client.ID] {
+				report.Add(SeverityError, "apikeys", "client %s reuses id %q", name, client.ID)
+			}
+			ids[client.ID] = true
+		}
+		if client.Status != "active" && client.Status != "inactive" {
+			report.Add(SeverityWarning, "apikeys", "client %s has unknown status %q", name, client.Status)
+		}
+		if len(client.LegacyKey) > 0 {
+			report.Add(SeverityError, "apikeys", "client %s has plain-text keys, store them as hashes (see mint-key -stdin)", name)
+		}
+		if len(client.Keys) == 0 && client.Status == "active" {
+			report.Add(SeverityWarning, "apikeys", "active client %s has no keys", name)
+		}
+		for _, cidr := range client.AllowedCIDRs {
+			if !validCIDR(cidr) {
+				report.Add(SeverityError, "apikeys", "client %s has invalid allowedCIDRs entry %q", name, cidr)
+			}
+		}
+		if client.Status == "active" && len(client.Keys) > 0 && allExpired(client.Keys, now) {
+			report.Add(SeverityWarning, "apikeys", "every key of active client %s has expired", name)
+		}
+
+		for _, key := range client.Keys {
+			credential, err := apikey.Parse(key.Hash)
+			if err != nil {
+				report.Add(SeverityError, "apikeys", "client %s has an invalid key hash: %v", name, err)
+				continue
+			}
+			if !apikey.DerivedKeyID(credential.KeyID) {
+				// the old key IDs were the first 8 characters of the key, or all of it
+				report.Add(SeverityError, "apikeys", "client %s has a key whose ID gives away the key, issue a new key of at least %d characters with mint-key", name, apikey.MinKeyLength)
+				continue
+			}
+			if owner, ok := hashOwners[key.Hash]; ok {
+				report.Add(SeverityError, "apikeys", "client %s reuses a key already assigned to %s", name, owner)
+				continue
+			}
+			hashOwners[key.Hash] = name
+			if key.NotBefore != nil && key.ExpiresAt != nil && !key.ExpiresAt.After(*key.NotBefore) {
+				report.Add(SeverityError, "apikeys", "client %s has key %s that expires before it becomes valid", name, apikey.MaskKeyID(credential.KeyID))
+			}
+			if owner, ok := keyIDOwners[credential.KeyID]; ok {
+				report.Add(SeverityWarning, "apikeys", "client %s has key ID %s also used by %s, it may be the same key", name, apikey.MaskKeyID(credential.KeyID), owner)
+				continue
+			}
+			keyIDOwners[credential.KeyID] = name
+		}
+
+		for _, role := range client.Roles {
+			if _, ok := roles[role]; !ok {
+				report.Add(SeverityError, "roles", "client %s has unknown role %q", name, role)
+			}
+		}
+		if client.Status == "active" && len(client.Roles) == 0 && len(client.Permissions) == 0 {
+			report.Add(SeverityWarning, "permissions", "active client %s has no role or permission", name)
+		}
+		validatePermissions(report, "client "+name, client.Permissions, handlers)
+
+		if fe := client.FieldEncryption; fe != nil {
+			if _, err := fieldcrypt.New(fe.Method, fe.PublicKey, fe.SharedKey, fe.KeyID); err != nil {
+				report.Add(SeverityError, "apikeys", "client %s has invalid fieldEncryption: %v", name, err)
+			}
+		}
+	}
+}
+
+func validateServer(report *ValidationReport, s ServerConfig, tcp TCPConfig) {
+	if s.ReadTimeout < 0 || s.WriteTimeout < 0 || s.IdleTimeout < 0 || s.DrainDelay < 0 {
+		report.Add(SeverityError, "server", "readTimeout, writeTimeout, idleTimeout and drainDelay must not be negative")
+	}
+	if s.ShutdownTimeout <= 0 {
+		report.Add(SeverityError, "server", "shutdownTimeout must be positive")
+	}
+	if call := tcp.DialTimeout + tcp.ReadWriteTimeout; s.WriteTimeout > 0 && s.WriteTimeout <= call {
+		report.Add(SeverityWarning, "server", "writeTimeout %s does not cover a System I call (dialTimeout + readWriteTimeout = %s)", s.WriteTimeout, call)
+	}
+}
+
+func validateAdmin(report *ValidationReport, a AdminConfig, s ServerConfig) {
+	if a.Port != "" {
+		if port, err := strconv.Atoi(a.Port); err != nil || port < 1 || port > 65535 {
+			report.Add(SeverityError, "admin", "admin port %q is not a port number", a.Port)
+		} else if a.Port == s.Port {
+			report.Add(SeverityError, "admin", "admin port %s is the server port", a.Port)
+		}
+		if len(a.Keys) == 0 {
+			report.Add(SeverityWarning, "admin", "admin port is enabled without admin keys, it rejects every request")
+		}
+	} else if len(a.Keys) > 0 {
+		report.Add(SeverityWarning, "admin", "admin keys are set but admin port is empty, the Admin API is not served")
+	}
+	if a.RecentFailures < 0 {
+		report.Add(SeverityError, "admin", "recentFailures must not be negative")
+	}
+}
+
+func validateCoalesce(report *ValidationReport, c CoalesceConfig, dr *DestinationsAndRoutes, a AuditConfig, i IdempotencyConfig) {
+	changesState := make(map[string]bool, len(a.Routes)+len(i.Routes))
+	for _, route := range append(append([]string{}, a.Routes...), i.Routes...) {
+		changesState[route] = true
+	}
+	readOnly := dr.ReadOnlyRoutes()
+	for _, routeKey := range readOnly {
+		if changesState[routeKey] {
+			report.Add(SeverityError, "routes", "route %q is flagged ReadOnly but audited or idempotent", routeKey)
+		}
+	}
+	if c.Enabled && len(readOnly) == 0 {
+		report.Add(SeverityWarning, "coalesce", "coalescing is enabled but no route is flagged ReadOnly")
+	}
+}
+
+func validCIDR(cidr string) bool {
+	if strings.Contains(cidr, "/") {
+		_, _, err := net.ParseCIDR(cidr)
+		return err == nil
+	}
+	return net.ParseIP(cidr) != nil
+}
+
+func allExpired(keys []KeyCredential, now time.Time) bool {
+	for _, key := range keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitRouteKey(routeKey string) (string, string, bool) {
+	method, path, ok := strings.Cut(routeKey, ":")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") || strings.ToUpper(method) != method {
+		return "", "", false
+	}
+	return method, path, true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/apikey"
+)
+
+func hashedKeys(t *testing.T, keys ...string) []KeyCredential {
+	t.Helper()
+	var credentials []KeyCredential
+	for _, key := range keys {
+		hash, err := apikey.Hash(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		credentials = append(credentials, KeyCredential{Hash: hash})
+	}
+	return credentials
+}
+
+func testDestinationsAndRoutes() *DestinationsAndRoutes {
+	return &DestinationsAndRoutes{
+		Destinations: map[string]Destination{
+			SystemIDestination: {
+				Type:  "tcp",
+				IP:    "127.0.0.1",
+				Ports: map[string][]string{"MyCard": {"40110"}},
+			},
+		},
+		Routes: map[string]Route{
+			"POST:/Api/SelfService/MyCard": {System: "MOB_APP", PortKey: "MyCard"},
+		},
+	}
+}
+
+func hasIssue(report *ValidationReport, severity Severity, fragment string) bool {
+	for _, issue := range report.Issues {
+		if issue.Severity == severity && strings.Contains(issue.Message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateCleanConfig(t *testing.T) {
+	apiClients := &APIClients{
+		Roles: map[string]Role{"self-service": {Permissions: []Permission{{Route: "POST:/Api/SelfService/*"}}}},
+		Clients: []APIKey{
+			{Keys: hashedKeys(t, "k1-000000000000000000000"), ClientName: "MobileApp", Status: "active", Roles: []string{"self-service"}},
+			{Keys: hashedKeys(t, "k2-000000000000000000000"), ClientName: "Partner", Status: "active", Permissions: []Permission{{Route: "POST:/Api/SelfService/MyCard", Constraints: map[string][]string{"Channel": {"M"}}}}},
+		},
+	}
+
+	report := Validate(nil, testDestinationsAndRoutes(), apiClients, []string{"POST:/Api/SelfService/MyCard"})
+	if len(report.Issues) != 0 {
+		t.Fatalf("expected no issues, got %v", report.Issues)
+	}
+}
+
+func TestValidateFindsIssues(t *testing.T) {
+	dr := testDestinationsAndRoutes()
+	dr.Routes["POST:/Api/Consent/UpdateConsent"] = Route{PortKey: "UpdateConsent", ReadOnly: true}
+	dup := hashedKeys(t, "duplicated-key-000000000")
+	apiClients := &APIClients{
+		Roles: map[string]Role{
+			"unused": {Permissions: []Permission{{Route: "POST:/Api/SelfService/MyCard"}}},
+			"broken": {Permissions: []Permission{{Route: "POST:/Api/[Mobile/*"}, {Route: "POST:/Api/Consent/UpdateConsent", Constraints: map[string][]string{"Channel": {}}}}},
+		},
+		Clients: []APIKey{
+			{Keys: []KeyCredential{{Hash: ""}}, ClientName: "Anonymous", Status: "active"},
+			{LegacyKey: []string{"plain"}, ClientName: "Legacy", Status: "active", FieldEncryption: &FieldEncryption{Method: "aes-gcm", SharedKey: "short"}},
+			{Keys: dup, ClientName: "A", Status: "active", Permissions: []Permission{{Route: "POST:/Api/Nowhere"}}},
+			{Keys: dup, ClientName: "B", Status: "active", Roles: []string{"broken", "missing"}},
+			{Keys: []KeyCredential{{Hash: "Common$s256$fAY9pY55fUjavILKItMt_g$EXrs6zvCy4ewDRJ6gttz9t-a6BlqoU3Sw91khvFdhy4"}}, ClientName: "Short", Status: "active"},
+		},
+	}
+	handlers := []string{"POST:/Api/SelfService/MyCard", "POST:/Api/Consent/UpdateConsent", "POST:/Api/Mobile/MobileFullPAN"}
+
+	cfg := &Config{Server: ServerConfig{Port: "8082", RequestIDNode: 40000, WriteTimeout: 10 * time.Second}, TCP: TCPConfig{DialTimeout: 5 * time.Second, ReadWriteTimeout: 10 * time.Second}, Admin: AdminConfig{Port: "8082", RecentFailures: -1},
+		Audit: AuditConfig{Enabled: true, Routes: []string{"POST:/Api/Consent/UpdateConsent"}},
+	}
+
+	report := Validate(cfg, dr, apiClients, handlers)
+
+	tests := []struct {
+		severity Severity
+		fragment string
+	}{
+		{SeverityError, `unknown port pool "UpdateConsent"`},
+		{SeverityError, `handler "POST:/Api/Mobile/MobileFullPAN" has no route entry`},
+		{SeverityError, "invalid key hash"},
+		{SeverityError, "plain-text keys"},
+		{SeverityError, "client Short (entry 5) has a key whose ID gives away the key"},
+		{SeverityError, "client Legacy (entry 2) has invalid fieldEncryption"},
+		{SeverityError, "reuses a key"},
+		{SeverityWarning, `"POST:/Api/Nowhere" which no handler serves`},
+		{SeverityWarning, `role "unused" is not assigned`},
+		{SeverityError, `unknown role "missing"`},
+		{SeverityError, `malformed permission "POST:/Api/[Mobile/*"`},
+		{SeverityError, `constrains Channel on "POST:/Api/Consent/UpdateConsent" to no value`},
+		{SeverityWarning, "active client Anonymous (entry 1) has no role or permission"},
+		{SeverityError, "requestIDNode 40000 out of range"},
+		{SeverityError, "shutdownTimeout must be positive"},
+		{SeverityWarning, "writeTimeout 10s does not cover a System I call (dialTimeout + readWriteTimeout = 15s)"},
+		{SeverityError, "admin port 8082 is the server port"},
+		{SeverityWarning, "admin port is enabled without admin keys"},
+		{SeverityError, "recentFailures must not be negative"},
+		{SeverityError, `route "POST:/Api/Consent/UpdateConsent" is flagged ReadOnly but audited or idempotent`},
+	}
+	for _, tt := range tests {
+		if !hasIssue(report, tt.severity, tt.fragment) {
+			t.Errorf("expected %s issue containing %q, got %v", tt.severity, tt.fragment, report.Issues)
+		}
+	}
+	if !report.HasErrors() {
+		t.Error("expected report to have errors")
+	}
+}
+
+func TestValidateRunsFeatureChecks(t *testing.T) {
+	apiClients := &APIClients{Clients: []APIKey{{Keys: hashedKeys(t, "k1-000000000000000000000"), ClientName: "MobileApp", Status: "active", Permissions: []Permission{{Route: "POST:/Api/SelfService/MyCard"}}}}}
+	cfg := &Config{Server: ServerConfig{Port: "8082", ShutdownTimeout: 30 * time.Second}}
+	var got ValidationScope
+	check := func(report *ValidationReport, checked *Config, scope ValidationScope) {
+		got = scope
+		if checked != cfg {
+			t.Errorf("check got config %p, want %p", checked, cfg)
+		}
+		report.Add(SeverityWarning, "feature", "feature check ran")
+	}
+
+	report := Validate(cfg, testDestinationsAndRoutes(), apiClients, []string{"POST:/Api/SelfService/MyCard"}, check)
+
+	want := []Issue{{Severity: SeverityWarning, Check: "feature", Message: "feature check ran"}}
+	if !reflect.DeepEqual(report.Issues, want) {
+		t.Fatalf("issues = %v, want %v", report.Issues, want)
+	}
+	if !got.Handlers["POST:/Api/SelfService/MyCard"] || !got.ClientNames()["MobileApp"] || got.Routes == nil {
+		t.Fatalf("check got scope %+v", got)
+	}
+}
+
+func TestLoadAPIKeysLayouts(t *testing.T) {
+	dir := t.TempDir()
+	legacy := writeFile(t, dir, "legacy.json", `[{"clientName": "A", "status": "active", "permissions": ["POST:/Api/Mobile/DashboardDetail"]}]`)
+	current := writeFile(t, dir, "current.json", `{
+		"roles": {"mobile-app": {"permissions": [{"route": "POST:/Api/Mobile/*", "constraints": {"Channel": ["M"]}}]}},
+		"clients": [{"clientName": "A", "status": "active", "roles": ["mobile-app"], "permissions": ["POST:/Api/Consent/UpdateConsent"]}]
+	}`)
+
+	apiClients, err := LoadAPIKeys(legacy, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apiClients.Clients) != 1 || apiClients.Clients[0].Permissions[0].Route != "POST:/Api/Mobile/DashboardDetail" {
+		t.Errorf("legacy layout = %+v", apiClients)
+	}
+
+	apiClients, err = LoadAPIKeys(current, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	permissions := apiClients.Permissions(apiClients.Clients[0])
+	if len(permissions) != 2 || permissions[0].Route != "POST:/Api/Mobile/*" || permissions[0].Constraints["Channel"][0] != "M" || permissions[1].Route != "POST:/Api/Consent/UpdateConsent" {
+		t.Errorf("Permissions() = %+v", permissions)
+	}
+}
@@ -28,6 +28,17 @@ var (
 	ErrService          = &AppError{ErrorCode: "SYS001", ErrorMessage: "System unavailable"}
 	ErrUnauthorized     = &AppError{ErrorCode: "SYS002", ErrorMessage: "Unauthorized"}
 	ErrTimeOut          = &AppError{ErrorCode: "SYS003", ErrorMessage: "System Time out"}
+	ErrKeyExpired       = &AppError{ErrorCode: "SYS004", ErrorMessage: "API key expired"}
+	ErrKeyNotYetValid   = &AppError{ErrorCode: "SYS006", ErrorMessage: "API key not yet valid"}
+	ErrIPNotAllowed     = &AppError{ErrorCode: "SYS007", ErrorMessage: "Client IP not allowed"}
+	ErrValueNotAllowed  = &AppError{ErrorCode: "SYS010", ErrorMessage: "Request value not allowed for this client"}
+	ErrTooManyRequests  = &AppError{ErrorCode: "SYS011", ErrorMessage: "Too many requests"}
+	ErrQuotaExceeded    = &AppError{ErrorCode: "SYS013", ErrorMessage: "Daily quota exceeded"}
+	ErrIdempotencyConflict   = &AppError{ErrorCode: "SYS014", ErrorMessage: "Idempotency-Key already used for a different request"}
+	ErrIdempotencyInProgress = &AppError{ErrorCode: "SYS015", ErrorMessage: "Request with this Idempotency-Key is still in progress"}
+	ErrInvIdempotencyKey     = &AppError{ErrorCode: "SYS016", ErrorMessage: "Invalid Idempotency-Key"}
+	ErrDuplicateRequestID    = &AppError{ErrorCode: "SYS017", ErrorMessage: "Duplicate Api-RequestID"}
+	ErrInvTimestamp          = &AppError{ErrorCode: "SYS018", ErrorMessage: "Api-Timestamp missing or outside the allowed time skew"}
 	ErrMember           = &AppError{ErrorCode: "SYS005", ErrorMessage: "Member Service System Unavailable"}
 	ErrSystemI  		= &AppError{ErrorCode: "SYS008", ErrorMessage: "System-I Unavailable"}
 	ErrSystemIUnexpect	= &AppError{ErrorCode: "SYS009", ErrorMessage: "System-I Unexpected error occurred"}
@@ -113,6 +124,10 @@ var (
 	ErrAgreeNotFound    = &AppError{ErrorCode: "MSG113", ErrorMessage: "Agreement not found"}
 	ErrAgentNotFound    = &AppError{ErrorCode: "MSG975", ErrorMessage: "Agent Code not found"}
 	ErrInvDate          = &AppError{ErrorCode: "MSG902", ErrorMessage: "Invalid Date"}
+
+	ErrClientNotFound   = &AppError{ErrorCode: "ADM001", ErrorMessage: "API client not found"}
+	ErrInvalidClient    = &AppError{ErrorCode: "ADM002", ErrorMessage: "Invalid API client"}
+	ErrInvLogLevel      = &AppError{ErrorCode: "ADM003", ErrorMessage: "Invalid log level"}
 )
 
 type ErrorResponse struct {
@@ -0,0 +1,91 @@
+// Package failures keeps the last failed System I exchanges in memory, for the
+// admin port to show what went wrong without searching the ELK logs. Payloads are
+// stored masked, the way the ELK log would show them.
+package failures
+
+import (
+	"sync"
+	"time"
+)
+
+// Exchange is one failed System I call: a transport error (ER0xx) or a response
+// with an error code.
+type Exchange struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"requestId"`
+	Route      string    `json:"route"`
+	Address    string    `json:"address"`
+	Result     string    `json:"result"` // see utils.SystemIResultCode
+	Error      string    `json:"error,omitempty"`
+	DurationMs float64   `json:"durationMs"`
+	Request    string    `json:"request"`
+	Response   string    `json:"response,omitempty"`
+}
+
+// Ring holds the last exchanges added to it. It is safe for concurrent use.
+type Ring struct {
+	mu    sync.Mutex
+	items []Exchange
+	next  int
+	full  bool
+	total uint64
+}
+
+// NewRing returns a ring keeping size exchanges, none when size is 0.
+func NewRing(size int) *Ring {
+	return &Ring{items: make([]Exchange, size)}
+}
+
+// Add records e, replacing the oldest exchange when the ring is full.
+func (r *Ring) Add(e Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total++
+	if len(r.items) == 0 {
+		return
+	}
+	r.items[r.next] = e
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// List returns the kept exchanges, newest first, and how many were added in total.
+func (r *Ring) List() ([]Exchange, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.items)
+	}
+	list := make([]Exchange, 0, n)
+	for i := 1; i <= n; i++ {
+		list = append(list, r.items[(r.next-i+len(r.items))%len(r.items)])
+	}
+	return list, r.total
+}
+
+var (
+	defaultMu   sync.RWMutex
+	defaultRing = NewRing(0)
+)
+
+// SetDefault replaces the ring used by the package-level functions.
+func SetDefault(r *Ring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRing = r
+}
+
+// Default returns the ring used by the package-level functions.
+func Default() *Ring {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRing
+}
+
+// Add records e in the default ring, see Ring.Add.
+func Add(e Exchange) {
+	Default().Add(e)
+}
@@ -0,0 +1,36 @@
+package failures
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRingKeepsNewestFirst(t *testing.T) {
+	r := NewRing(3)
+	if list, total := r.List(); len(list) != 0 || total != 0 {
+		t.Fatalf("empty ring = %v, %d", list, total)
+	}
+	for i := 1; i <= 5; i++ {
+		r.Add(Exchange{RequestID: fmt.Sprint(i)})
+		list, total := r.List()
+		if total != uint64(i) {
+			t.Errorf("total = %d, want %d", total, i)
+		}
+		if want := min(i, 3); len(list) != want {
+			t.Fatalf("after %d adds: %d kept, want %d", i, len(list), want)
+		}
+		for j, e := range list {
+			if e.RequestID != fmt.Sprint(i-j) {
+				t.Errorf("after %d adds: list[%d] = %s, want %d", i, j, e.RequestID, i-j)
+			}
+		}
+	}
+}
+
+func TestZeroSizeRingCountsOnly(t *testing.T) {
+	r := NewRing(0)
+	r.Add(Exchange{RequestID: "1"})
+	if list, total := r.List(); len(list) != 0 || total != 1 {
+		t.Errorf("List() = %v, %d", list, total)
+	}
+}
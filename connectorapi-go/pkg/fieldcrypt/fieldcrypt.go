@@ -0,0 +1,171 @@
+// Package fieldcrypt encrypts single response fields, such as a full card number,
+// for one API client so that only that client can read them.
+//
+// Two methods are supported:
+//   - "jwe": compact JWE with RSA-OAEP-256 key wrapping and A256GCM content
+//     encryption, for a public key registered by the client.
+//   - "aes-gcm": AES-256-GCM with a key shared with the client, encoded as
+//     base64(nonce || ciphertext || tag).
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	MethodJWE    = "jwe"
+	MethodAESGCM = "aes-gcm"
+)
+
+var ErrInvalidKey = errors.New("invalid field encryption key")
+
+// Encrypter encrypts one field value. Implementations are safe for concurrent use.
+type Encrypter interface {
+	Encrypt(plaintext string) (string, error)
+}
+
+// New returns the Encrypter for method. publicKeyPEM is used by "jwe", sharedKey
+// (base64, 32 bytes) by "aes-gcm". keyID is sent as the JWE "kid" header.
+func New(method, publicKeyPEM, sharedKey, keyID string) (Encrypter, error) {
+	switch method {
+	case MethodJWE:
+		pub, err := parsePublicKey(publicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		return newJWE(pub, keyID)
+	case MethodAESGCM:
+		key, err := base64.StdEncoding.DecodeString(sharedKey)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("%w: sharedKey must be 32 bytes in base64", ErrInvalidKey)
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		return &aesGCM{aead: aead}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidKey, method)
+	}
+}
+
+func parsePublicKey(publicKeyPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("%w: publicKey is not PEM", ErrInvalidKey)
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok || pub.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("%w: publicKey must be RSA with at least 2048 bits", ErrInvalidKey)
+	}
+	return pub, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+func (e *aesGCM) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := e.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+type jwe struct {
+	pub *rsa.PublicKey
+	// protected is the encoded protected header, also the additional authenticated data.
+	protected string
+}
+
+func newJWE(pub *rsa.PublicKey, keyID string) (*jwe, error) {
+	header, err := json.Marshal(struct {
+		Alg string `json:"alg"`
+		Enc string `json:"enc"`
+		Kid string `json:"kid,omitempty"`
+	}{"RSA-OAEP-256", "A256GCM", keyID})
+	if err != nil {
+		return nil, err
+	}
+	return &jwe{pub: pub, protected: base64.RawURLEncoding.EncodeToString(header)}, nil
+}
+
+// Encrypt returns the compact serialization
+// header.encryptedKey.iv.ciphertext.tag with a new content key per value.
+func (e *jwe) Encrypt(plaintext string) (string, error) {
+	cek := make([]byte, 32)
+	if _, err := rand.Read(cek); err != nil {
+		return "", err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, e.pub, cek, nil)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, iv, []byte(plaintext), []byte(e.protected))
+	ciphertext, tag := sealed[:len(sealed)-aead.Overhead()], sealed[len(sealed)-aead.Overhead():]
+
+	enc := base64.RawURLEncoding.EncodeToString
+	return e.protected + "." + enc(encryptedKey) + "." + enc(iv) + "." + enc(ciphertext) + "." + enc(tag), nil
+}
+
+// IsCiphertext reports whether s has the form of a value Encrypt returns: a compact
+// JWE of this package, or base64 of a nonce, at least one byte and a tag. It lets
+// the log masking keep ciphertext, which only the client can read, as it is.
+func IsCiphertext(s string) bool {
+	if parts := strings.Split(s, "."); len(parts) == 5 {
+		var header struct{ Enc string }
+		data, err := base64.RawURLEncoding.DecodeString(parts[0])
+		if err != nil || json.Unmarshal(data, &header) != nil || header.Enc != "A256GCM" {
+			return false
+		}
+		for _, part := range parts[1:] {
+			if _, err := base64.RawURLEncoding.DecodeString(part); err != nil || part == "" {
+				return false
+			}
+		}
+		return true
+	}
+	const nonceAndTag = 12 + 16
+	sealed, err := base64.StdEncoding.DecodeString(s)
+	return err == nil && len(sealed) > nonceAndTag
+}
@@ -0,0 +1,152 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const pan = "4111111111111111"
+
+func rsaKey(t *testing.T, bits int) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// decryptJWE is what a client does with the compact JWE.
+func decryptJWE(t *testing.T, key *rsa.PrivateKey, token string) (map[string]string, string) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		t.Fatalf("JWE has %d parts", len(parts))
+	}
+	dec := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	var header map[string]string
+	if err := json.Unmarshal(dec(parts[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	cek, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, dec(parts[1]), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := newGCM(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := aead.Open(nil, dec(parts[2]), append(dec(parts[3]), dec(parts[4])...), []byte(parts[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header, string(plaintext)
+}
+
+func TestJWE(t *testing.T) {
+	key, publicKey := rsaKey(t, 2048)
+	e, err := New(MethodJWE, publicKey, "", "partner-2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := e.Encrypt(pan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := e.Encrypt(pan)
+	if first == second {
+		t.Error("same ciphertext twice, content key or IV reused")
+	}
+	if strings.Contains(first, pan) {
+		t.Error("ciphertext contains the plaintext")
+	}
+
+	header, plaintext := decryptJWE(t, key, first)
+	if plaintext != pan {
+		t.Errorf("decrypted %q", plaintext)
+	}
+	if header["alg"] != "RSA-OAEP-256" || header["enc"] != "A256GCM" || header["kid"] != "partner-2026" {
+		t.Errorf("header = %v", header)
+	}
+}
+
+func TestAESGCM(t *testing.T) {
+	shared := make([]byte, 32)
+	rand.Read(shared)
+	e, err := New(MethodAESGCM, "", base64.StdEncoding.EncodeToString(shared), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := e.Encrypt(pan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, _ := newGCM(shared)
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil || string(plaintext) != pan {
+		t.Fatalf("decrypted %q, %v", plaintext, err)
+	}
+}
+
+func TestNewRejectsInvalidKeys(t *testing.T) {
+	_, weak := rsaKey(t, 1024)
+	tests := []struct {
+		name                      string
+		method, publicKey, shared string
+	}{
+		{"unknown method", "rot13", "", ""},
+		{"jwe without key", MethodJWE, "", ""},
+		{"jwe not PEM", MethodJWE, "MIIBIjANBg", ""},
+		{"jwe weak key", MethodJWE, weak, ""},
+		{"aes-gcm short key", MethodAESGCM, "", base64.StdEncoding.EncodeToString(make([]byte, 16))},
+		{"aes-gcm not base64", MethodAESGCM, "", "not base64!"},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.method, tt.publicKey, tt.shared, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("%s: err = %v, want ErrInvalidKey", tt.name, err)
+		}
+	}
+}
+
+func TestIsCiphertext(t *testing.T) {
+	_, publicKey := rsaKey(t, 2048)
+	shared := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	for _, method := range []struct{ name, publicKey, shared string }{{MethodJWE, publicKey, ""}, {MethodAESGCM, "", shared}} {
+		e, err := New(method.name, method.publicKey, method.shared, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, _ := e.Encrypt(pan)
+		if !IsCiphertext(token) {
+			t.Errorf("%s ciphertext %q not recognized", method.name, token)
+		}
+	}
+	for _, value := range []string{pan, "411111******1111", "1234567890123", "Somchai Jaidee", "somchai@example.com", "a.b.c.d.e", "QUJD", ""} {
+		if IsCiphertext(value) {
+			t.Errorf("%q taken for ciphertext", value)
+		}
+	}
+}
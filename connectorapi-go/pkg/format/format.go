@@ -1,7 +0,0 @@
-package format
-
-import (
-
-)
-
-var _ = "placeholder to prevent empty package error"
\ No newline at end of file
@@ -0,0 +1,282 @@
+// Package health tells whether the API can serve. Each System I port is probed in
+// the background and the results are cached, so /readyz answers at once and never
+// adds load on System I. The API is ready while every required route has at least
+// one healthy port; other components, such as the log sink, are reported without
+// changing readiness.
+package health
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// Probe checks one System I address, e.g. by connecting to it.
+type Probe func(ctx context.Context, address string) error
+
+// Component reports the health of a part of the API other than System I.
+type Component func() error
+
+// PortStatus is the last probe of one port.
+type PortStatus struct {
+	Address   string    `json:"address"`
+	PortKeys  []string  `json:"portKeys"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs float64   `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt,omitempty"`
+	Since     time.Time `json:"since,omitempty"` // when Healthy last changed
+}
+
+// RouteStatus counts the healthy ports of a route's port pool.
+type RouteStatus struct {
+	Route        string `json:"route"`
+	PortKey      string `json:"portKey"`
+	Required     bool   `json:"required"`
+	HealthyPorts int    `json:"healthyPorts"`
+	Ports        int    `json:"ports"`
+}
+
+// ComponentStatus is the health of a Component.
+type ComponentStatus struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the body of /readyz. Status is "ready", "degraded" (ready, but a port or
+// component is unhealthy), "unready" or "starting" before the first probes finish.
+type Report struct {
+	Status     string                     `json:"status"`
+	Ready      bool                       `json:"ready"`
+	Reasons    []string                   `json:"reasons,omitempty"`
+	Ports      []PortStatus               `json:"ports"`
+	Routes     []RouteStatus              `json:"routes"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+type route struct {
+	name      string
+	portKey   string
+	addresses []string
+	required  bool
+}
+
+// Checker probes the ports of the System I destination. It is safe for concurrent use.
+type Checker struct {
+	config config.HealthConfig
+	probe  Probe
+	routes []route
+
+	mu         sync.RWMutex
+	ports      map[string]*PortStatus // by address
+	probed     bool
+	components map[string]Component
+	notReady   string // set by SetNotReady
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	now      func() time.Time
+}
+
+// NewChecker returns a checker for the routes and ports of dr. Routes in
+// cfg.RequiredRoutes, or every route when it is empty, need a healthy port.
+func NewChecker(cfg config.HealthConfig, dr *config.DestinationsAndRoutes, probe Probe) *Checker {
+	c := &Checker{
+		config:     cfg,
+		probe:      probe,
+		ports:      make(map[string]*PortStatus),
+		components: make(map[string]Component),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		now:        time.Now,
+	}
+	required := make(map[string]bool, len(cfg.RequiredRoutes))
+	for _, r := range cfg.RequiredRoutes {
+		required[r] = true
+	}
+
+	destination := dr.Destinations[config.SystemIDestination]
+	for portKey, ports := range destination.Ports {
+		for _, port := range ports {
+			address := net.JoinHostPort(destination.IP, port)
+			status, ok := c.ports[address]
+			if !ok {
+				status = &PortStatus{Address: address}
+				c.ports[address] = status
+			}
+			status.PortKeys = append(status.PortKeys, portKey)
+		}
+	}
+	for _, status := range c.ports {
+		sort.Strings(status.PortKeys)
+	}
+	for name, r := range dr.Routes {
+		var addresses []string
+		for _, port := range destination.Ports[r.PortKey] {
+			addresses = append(addresses, net.JoinHostPort(destination.IP, port))
+		}
+		c.routes = append(c.routes, route{
+			name:      name,
+			portKey:   r.PortKey,
+			addresses: addresses,
+			required:  len(required) == 0 || required[name],
+		})
+	}
+	sort.Slice(c.routes, func(i, j int) bool { return c.routes[i].name < c.routes[j].name })
+	return c
+}
+
+// AddComponent adds a component to the report, e.g. the log sink.
+func (c *Checker) AddComponent(name string, check Component) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components[name] = check
+}
+
+// SetNotReady turns readiness off for reason, e.g. while shutting down. An empty
+// reason turns it back on.
+func (c *Checker) SetNotReady(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notReady = reason
+}
+
+// Start probes every port now and then every ProbeInterval until Stop.
+func (c *Checker) Start() {
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.config.ProbeInterval)
+		defer ticker.Stop()
+		for {
+			c.ProbeAll(context.Background())
+			select {
+			case <-ticker.C:
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background probes of Start.
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		<-c.done
+	})
+}
+
+// ProbeAll probes every port at once, each within ProbeTimeout.
+func (c *Checker) ProbeAll(ctx context.Context) {
+	c.mu.RLock()
+	addresses := make([]string, 0, len(c.ports))
+	for address := range c.ports {
+		addresses = append(addresses, address)
+	}
+	c.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, address := range addresses {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, c.config.ProbeTimeout)
+			defer cancel()
+			start := c.now()
+			err := c.probe(probeCtx, address)
+			c.record(address, start, err)
+		}(address)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	c.probed = true
+	c.mu.Unlock()
+}
+
+func (c *Checker) record(address string, start time.Time, err error) {
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := c.ports[address]
+	healthy := err == nil
+	if healthy != status.Healthy || status.Since.IsZero() {
+		status.Since = now
+	}
+	status.Healthy = healthy
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	status.LatencyMs = float64(now.Sub(start).Microseconds()) / 1000
+	status.CheckedAt = now
+}
+
+// Ready tells whether every required route has a healthy port.
+func (c *Checker) Ready() bool {
+	return c.Report().Ready
+}
+
+// Report returns the cached probe results and checks the components.
+func (c *Checker) Report() Report {
+	c.mu.RLock()
+	components := make(map[string]Component, len(c.components))
+	for name, check := range c.components {
+		components[name] = check
+	}
+	probed := c.probed
+	report := Report{Ready: probed && c.notReady == "", Ports: make([]PortStatus, 0, len(c.ports))}
+	if !probed {
+		report.Reasons = append(report.Reasons, "System I ports not probed yet")
+	}
+	if c.notReady != "" {
+		report.Reasons = append(report.Reasons, c.notReady)
+	}
+	degraded := false
+	for _, status := range c.ports {
+		report.Ports = append(report.Ports, *status)
+		degraded = degraded || (probed && !status.Healthy)
+	}
+	for _, r := range c.routes {
+		rs := RouteStatus{Route: r.name, PortKey: r.portKey, Required: r.required, Ports: len(r.addresses)}
+		for _, address := range r.addresses {
+			if status := c.ports[address]; status != nil && status.Healthy {
+				rs.HealthyPorts++
+			}
+		}
+		if probed && r.required && rs.HealthyPorts == 0 {
+			report.Ready = false
+			report.Reasons = append(report.Reasons, "no healthy port for "+r.name)
+		}
+		report.Routes = append(report.Routes, rs)
+	}
+	c.mu.RUnlock()
+	sort.Slice(report.Ports, func(i, j int) bool { return report.Ports[i].Address < report.Ports[j].Address })
+
+	report.Components = make(map[string]ComponentStatus, len(components))
+	for name, check := range components {
+		status := ComponentStatus{Healthy: true}
+		if err := check(); err != nil {
+			status = ComponentStatus{Error: err.Error()}
+			degraded = true
+		}
+		report.Components[name] = status
+	}
+
+	switch {
+	case !probed:
+		report.Status = "starting"
+	case !report.Ready:
+		report.Status = "unready"
+	case degraded:
+		report.Status = "degraded"
+	default:
+		report.Status = "ready"
+	}
+	return report
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func testRoutes() *config.DestinationsAndRoutes {
+	return &config.DestinationsAndRoutes{
+		Destinations: map[string]config.Destination{
+			config.SystemIDestination: {Type: "tcp", IP: "10.0.0.1", Ports: map[string][]string{
+				"MyCard":    {"40101", "40102"},
+				"Dashboard": {"40201"},
+				"Shared":    {"40101"},
+			}},
+		},
+		Routes: map[string]config.Route{
+			"POST:/Api/SelfService/MyCard":      {PortKey: "MyCard"},
+			"POST:/Api/Mobile/DashboardSummary": {PortKey: "Dashboard"},
+		},
+	}
+}
+
+// downProbe fails for the listed addresses.
+func downProbe(down ...string) Probe {
+	return func(ctx context.Context, address string) error {
+		for _, d := range down {
+			if address == d {
+				return errors.New("connection refused")
+			}
+		}
+		return nil
+	}
+}
+
+func testConfig(required ...string) config.HealthConfig {
+	return config.HealthConfig{ProbeInterval: time.Hour, ProbeTimeout: time.Second, RequiredRoutes: required}
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		required []string
+		down     []string
+		status   string
+		reason   string
+	}{
+		{"all ports up", nil, nil, "ready", ""},
+		{"one of two ports down", nil, []string{"10.0.0.1:40102"}, "degraded", ""},
+		{"required route without a port", nil, []string{"10.0.0.1:40201"}, "unready", "no healthy port for POST:/Api/Mobile/DashboardSummary"},
+		{"optional route without a port", []string{"POST:/Api/SelfService/MyCard"}, []string{"10.0.0.1:40201"}, "degraded", ""},
+	}
+	for _, tt := range tests {
+		c := NewChecker(testConfig(tt.required...), testRoutes(), downProbe(tt.down...))
+		c.ProbeAll(context.Background())
+		report := c.Report()
+		if report.Status != tt.status || report.Ready != (tt.status != "unready") {
+			t.Errorf("%s: status %s, ready %v, want %s (%v)", tt.name, report.Status, report.Ready, tt.status, report.Reasons)
+		}
+		if tt.reason != "" && (len(report.Reasons) != 1 || report.Reasons[0] != tt.reason) {
+			t.Errorf("%s: reasons %v, want %q", tt.name, report.Reasons, tt.reason)
+		}
+	}
+}
+
+func TestReportPerPort(t *testing.T) {
+	c := NewChecker(testConfig(), testRoutes(), downProbe("10.0.0.1:40102"))
+	c.ProbeAll(context.Background())
+	report := c.Report()
+
+	if len(report.Ports) != 3 {
+		t.Fatalf("%d ports reported, want 3 distinct addresses", len(report.Ports))
+	}
+	shared := report.Ports[0]
+	if shared.Address != "10.0.0.1:40101" || len(shared.PortKeys) != 2 || !shared.Healthy || shared.CheckedAt.IsZero() {
+		t.Errorf("shared port = %+v", shared)
+	}
+	if down := report.Ports[1]; down.Healthy || down.Error != "connection refused" {
+		t.Errorf("down port = %+v", down)
+	}
+	for _, r := range report.Routes {
+		if r.Route == "POST:/Api/SelfService/MyCard" && (r.HealthyPorts != 1 || r.Ports != 2) {
+			t.Errorf("MyCard route = %+v", r)
+		}
+	}
+}
+
+func TestStartingComponentsAndShutdown(t *testing.T) {
+	c := NewChecker(testConfig(), testRoutes(), downProbe())
+	if report := c.Report(); report.Ready || report.Status != "starting" {
+		t.Errorf("before the first probe: %+v", report)
+	}
+
+	c.Start()
+	defer c.Stop()
+	deadline := time.Now().Add(time.Second)
+	for !c.Ready() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	c.AddComponent("logSink", func() error { return errors.New("sink unreachable") })
+	report := c.Report()
+	if !report.Ready || report.Status != "degraded" || report.Components["logSink"].Error != "sink unreachable" {
+		t.Errorf("with a failing component: %+v", report)
+	}
+
+	c.SetNotReady("shutting down")
+	if report := c.Report(); report.Ready || report.Status != "unready" {
+		t.Errorf("after SetNotReady: %+v", report)
+	}
+}
@@ -0,0 +1,23 @@
+package health
+
+import "connectorapi-go/pkg/config"
+
+// ValidateConfig checks cfg.Health, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	h := cfg.Health
+	if h.ProbeInterval <= 0 || h.ProbeTimeout <= 0 {
+		report.Add(config.SeverityError, "health", "probeInterval and probeTimeout must be positive")
+	} else if h.ProbeTimeout >= h.ProbeInterval {
+		report.Add(config.SeverityWarning, "health", "probeTimeout %s is not below probeInterval %s", h.ProbeTimeout, h.ProbeInterval)
+	}
+	for _, route := range h.RequiredRoutes {
+		if _, ok := scope.Routes.Routes[route]; !ok {
+			report.Add(config.SeverityError, "health", "required route %q has no route entry", route)
+		} else if !scope.Handlers[route] {
+			report.Add(config.SeverityWarning, "health", "required route %q is not served by any handler", route)
+		}
+	}
+	if h.Echo.Enabled && (h.Echo.System == "" || h.Echo.Service == "") {
+		report.Add(config.SeverityError, "health", "echo probe needs a system and a service")
+	}
+}
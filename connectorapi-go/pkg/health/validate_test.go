@@ -0,0 +1,53 @@
+package health
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	scope := config.ValidationScope{Routes: testRoutes(), Handlers: map[string]bool{"POST:/Api/SelfService/MyCard": true}}
+	valid := func() config.HealthConfig {
+		return config.HealthConfig{
+			ProbeInterval:  10 * time.Second,
+			ProbeTimeout:   2 * time.Second,
+			RequiredRoutes: []string{"POST:/Api/SelfService/MyCard"},
+			Echo:           config.EchoProbe{Enabled: true, System: "SYS", Service: "PING"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(h *config.HealthConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(h *config.HealthConfig) {}, nil},
+		{"no probe interval", func(h *config.HealthConfig) { h.ProbeInterval = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "health", Message: "probeInterval and probeTimeout must be positive"}}},
+		{"timeout not below interval", func(h *config.HealthConfig) { h.ProbeInterval, h.ProbeTimeout = time.Second, 2*time.Second },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "health", Message: "probeTimeout 2s is not below probeInterval 1s"}}},
+		{"required route without entry", func(h *config.HealthConfig) { h.RequiredRoutes = []string{"POST:/Api/Unknown"} },
+			[]config.Issue{{Severity: config.SeverityError, Check: "health", Message: `required route "POST:/Api/Unknown" has no route entry`}}},
+		{"required route not served", func(h *config.HealthConfig) { h.RequiredRoutes = []string{"POST:/Api/Mobile/DashboardSummary"} },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "health", Message: `required route "POST:/Api/Mobile/DashboardSummary" is not served by any handler`}}},
+		{"echo without service", func(h *config.HealthConfig) { h.Echo.Service = "" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "health", Message: "echo probe needs a system and a service"}}},
+		{"disabled echo is not checked", func(h *config.HealthConfig) { h.Echo = config.EchoProbe{} }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Health: valid()}
+			tt.mutate(&cfg.Health)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package idempotency remembers the response to a request sent with an Idempotency-Key,
+// so that a retry with the same key gets that response instead of a second System I call.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrConflict is returned when a key is reused for a request with a different body.
+	ErrConflict = errors.New("idempotency key reused with a different request")
+	// ErrInProgress is returned when the first request with a key did not finish in time.
+	ErrInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// Record is a stored response.
+type Record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Status      int       `json:"status"`
+	ContentType string    `json:"contentType"`
+	Body        []byte    `json:"body"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Store keeps records until they expire. Get does not return expired records.
+type Store interface {
+	Get(key string) (*Record, error)
+	Put(key string, record Record) error
+}
+
+// Fingerprint identifies the request a key was first used for.
+func Fingerprint(route string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(route))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Manager stores responses in a Store and makes concurrent requests with the same key
+// wait for the first one.
+type Manager struct {
+	store    Store
+	ttl      time.Duration
+	mu       sync.Mutex
+	inFlight map[string]*call
+	now      func() time.Time
+}
+
+type call struct {
+	fingerprint string
+	// checking is set while the holder reads the store and may still find a record,
+	// so a request with another fingerprint waits for that instead of conflicting.
+	checking bool
+	done     chan struct{}
+}
+
+func NewManager(store Store, ttl time.Duration) *Manager {
+	return &Manager{store: store, ttl: ttl, inFlight: make(map[string]*call), now: time.Now}
+}
+
+// Begin returns the stored response for key, or a Ticket when the caller is the first
+// with this key and must run the request. A request with the same key already in flight
+// is waited for until ctx is done, which then gives ErrInProgress.
+//
+// The key is claimed before the store is read, so m.mu is never held while a FileStore
+// reads and requests with the same key read the store one at a time.
+func (m *Manager) Begin(ctx context.Context, key, fingerprint string) (*Record, *Ticket, error) {
+	for {
+		m.mu.Lock()
+		running, ok := m.inFlight[key]
+		if !ok {
+			c := &call{fingerprint: fingerprint, checking: true, done: make(chan struct{})}
+			m.inFlight[key] = c
+			m.mu.Unlock()
+			return m.check(key, c)
+		}
+		conflict := !running.checking && running.fingerprint != fingerprint
+		m.mu.Unlock()
+		if conflict {
+			return nil, nil, ErrConflict
+		}
+
+		select {
+		case <-running.done:
+		case <-ctx.Done():
+			return nil, nil, ErrInProgress
+		}
+	}
+}
+
+// check reads the stored record for the key claimed by c. Without a record the
+// caller runs the request with the returned Ticket, else the key is released.
+func (m *Manager) check(key string, c *call) (*Record, *Ticket, error) {
+	ticket := &Ticket{m: m, key: key, call: c}
+	record, err := m.store.Get(key)
+	if err != nil {
+		ticket.Abandon()
+		return nil, nil, err
+	}
+	if record != nil {
+		ticket.Abandon()
+		if record.Fingerprint != c.fingerprint {
+			return nil, nil, ErrConflict
+		}
+		return record, nil, nil
+	}
+	m.mu.Lock()
+	c.checking = false
+	m.mu.Unlock()
+	return nil, ticket, nil
+}
+
+// Ticket is held by the request that runs for a key. Exactly one of Complete or
+// Abandon must be called.
+type Ticket struct {
+	m    *Manager
+	key  string
+	call *call
+}
+
+// Complete stores the response and releases the requests waiting for it.
+func (t *Ticket) Complete(status int, contentType string, body []byte) error {
+	record := Record{
+		Fingerprint: t.call.fingerprint,
+		Status:      status,
+		ContentType: contentType,
+		Body:        body,
+		ExpiresAt:   t.m.now().Add(t.m.ttl),
+	}
+	// Requests with this key keep waiting until the record is stored, but m.mu is not
+	// held while a FileStore writes, so requests with other keys are not held up.
+	err := t.m.store.Put(t.key, record)
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	t.release()
+	return err
+}
+
+// Abandon releases the key without storing a response, so the next request runs again.
+func (t *Ticket) Abandon() {
+	t.m.mu.Lock()
+	defer t.m.mu.Unlock()
+	t.release()
+}
+
+func (t *Ticket) release() {
+	delete(t.m.inFlight, t.key)
+	close(t.call.done)
+}
@@ -0,0 +1,279 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testKey  = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	otherKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func stores(t *testing.T) map[string]func() Store {
+	return map[string]func() Store{
+		"memory": func() Store { return NewMemoryStore() },
+		"file": func() Store {
+			s, err := NewFileStore(t.TempDir(), testKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+}
+
+func TestReplayAndConflict(t *testing.T) {
+	for name, newStore := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			m := NewManager(newStore(), time.Hour)
+			ctx := context.Background()
+			fp := Fingerprint("POST:/Api/Consent/UpdateConsent", []byte(`{"IDCardNo":"1"}`))
+
+			record, ticket, err := m.Begin(ctx, "client:k1", fp)
+			if record != nil || ticket == nil || err != nil {
+				t.Fatalf("first Begin = %v, %v, %v", record, ticket, err)
+			}
+			if err := ticket.Complete(200, "application/json", []byte(`{"ok":true}`)); err != nil {
+				t.Fatal(err)
+			}
+
+			record, ticket, err = m.Begin(ctx, "client:k1", fp)
+			if err != nil || ticket != nil || record == nil || record.Status != 200 || string(record.Body) != `{"ok":true}` {
+				t.Fatalf("retry Begin = %+v, %v, %v", record, ticket, err)
+			}
+
+			other := Fingerprint("POST:/Api/Consent/UpdateConsent", []byte(`{"IDCardNo":"2"}`))
+			if _, _, err := m.Begin(ctx, "client:k1", other); !errors.Is(err, ErrConflict) {
+				t.Errorf("different body: err = %v, want ErrConflict", err)
+			}
+		})
+	}
+}
+
+func TestExpiredRecordRunsAgain(t *testing.T) {
+	store := NewMemoryStore()
+	m := NewManager(store, time.Minute)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	store.now = m.now
+
+	_, ticket, _ := m.Begin(context.Background(), "k", "fp")
+	ticket.Complete(200, "application/json", nil)
+	now = now.Add(time.Minute)
+	if _, ticket, _ := m.Begin(context.Background(), "k", "other"); ticket == nil {
+		t.Error("expired key was not released")
+	}
+}
+
+func TestConcurrentDuplicatesWait(t *testing.T) {
+	m := NewManager(NewMemoryStore(), time.Hour)
+	var runs int32
+	var wg sync.WaitGroup
+	bodies := make([]string, 10)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			record, ticket, err := m.Begin(context.Background(), "k", "fp")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if ticket != nil {
+				atomic.AddInt32(&runs, 1)
+				time.Sleep(20 * time.Millisecond)
+				ticket.Complete(200, "application/json", []byte("first"))
+				bodies[i] = "first"
+				return
+			}
+			bodies[i] = string(record.Body)
+		}(i)
+	}
+	wg.Wait()
+	if runs != 1 {
+		t.Errorf("request ran %d times", runs)
+	}
+	for i, body := range bodies {
+		if body != "first" {
+			t.Errorf("caller %d got %q", i, body)
+		}
+	}
+}
+
+func TestWaitTimesOutAndAbandonReleases(t *testing.T) {
+	m := NewManager(NewMemoryStore(), time.Hour)
+	_, ticket, _ := m.Begin(context.Background(), "k", "fp")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := m.Begin(ctx, "k", "fp"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("err = %v, want ErrInProgress", err)
+	}
+	if _, _, err := m.Begin(context.Background(), "k", "other"); !errors.Is(err, ErrConflict) {
+		t.Errorf("different body while in flight: err = %v, want ErrConflict", err)
+	}
+
+	ticket.Abandon()
+	if _, next, _ := m.Begin(context.Background(), "k", "fp"); next == nil {
+		t.Error("abandoned key was not released")
+	}
+}
+
+// slowStore blocks Put until release is closed.
+type slowStore struct {
+	*MemoryStore
+	release chan struct{}
+}
+
+func (s slowStore) Put(key string, record Record) error {
+	<-s.release
+	return s.MemoryStore.Put(key, record)
+}
+
+func TestCompleteDoesNotBlockOtherKeys(t *testing.T) {
+	store := slowStore{MemoryStore: NewMemoryStore(), release: make(chan struct{})}
+	m := NewManager(store, time.Hour)
+	_, ticket, _ := m.Begin(context.Background(), "slow", "fp")
+	completed := make(chan struct{})
+	go func() {
+		defer close(completed)
+		ticket.Complete(200, "application/json", []byte("slow"))
+	}()
+
+	other := make(chan *Ticket)
+	go func() {
+		_, next, _ := m.Begin(context.Background(), "other", "fp")
+		other <- next
+	}()
+	select {
+	case next := <-other:
+		if next == nil {
+			t.Fatal("Begin of another key while a record is written returned no ticket")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Begin of another key waits for the record being written")
+	}
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, _, err := m.Begin(short, "slow", "fp"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("same key while its record is written: err = %v, want ErrInProgress", err)
+	}
+
+	close(store.release)
+	<-completed
+	if record, _, err := m.Begin(context.Background(), "slow", "fp"); err != nil || record == nil || string(record.Body) != "slow" {
+		t.Errorf("after Complete: record %v, err %v", record, err)
+	}
+}
+
+// slowGetStore blocks Get of key "slow" until release is closed.
+type slowGetStore struct {
+	*MemoryStore
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (s slowGetStore) Get(key string) (*Record, error) {
+	if key == "slow" {
+		close(s.reading)
+		<-s.release
+	}
+	return s.MemoryStore.Get(key)
+}
+
+func TestStoreReadDoesNotBlockOtherKeys(t *testing.T) {
+	store := slowGetStore{MemoryStore: NewMemoryStore(), reading: make(chan struct{}), release: make(chan struct{})}
+	m := NewManager(store, time.Hour)
+	first := make(chan *Ticket)
+	go func() {
+		_, ticket, _ := m.Begin(context.Background(), "slow", "fp")
+		first <- ticket
+	}()
+	<-store.reading
+
+	other := make(chan *Ticket)
+	go func() {
+		_, next, _ := m.Begin(context.Background(), "other", "fp")
+		other <- next
+	}()
+	select {
+	case next := <-other:
+		if next == nil {
+			t.Fatal("Begin of another key while the store is read returned no ticket")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Begin of another key waits for the store read of a different key")
+	}
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, _, err := m.Begin(short, "slow", "other"); !errors.Is(err, ErrInProgress) {
+		t.Errorf("different body while the store is read: err = %v, want ErrInProgress", err)
+	}
+
+	close(store.release)
+	if ticket := <-first; ticket == nil {
+		t.Fatal("first Begin returned no ticket")
+	}
+}
+
+func TestFileStoreEncryptsRecords(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(`{"IDCardNo":"1234567890123","CustomerName":"Somchai"}`)
+	if err := s.Put("client:k1", Record{Fingerprint: "fp", Status: 200, Body: body, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 {
+		t.Fatalf("files = %v, want one record", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{"1234567890123", "Somchai", "fingerprint"} {
+		if bytes.Contains(data, []byte(plain)) {
+			t.Errorf("record file contains %q in clear text", plain)
+		}
+	}
+	if record, err := s.Get("client:k1"); err != nil || record == nil || !bytes.Equal(record.Body, body) {
+		t.Errorf("Get = %v, %v", record, err)
+	}
+
+	// A record copied to the file of another key does not open.
+	other := s.path("client:k2")
+	if err := os.WriteFile(other, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("client:k2"); err == nil {
+		t.Error("record moved to another key was read")
+	}
+
+	rekeyed, err := NewFileStore(dir, otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rekeyed.Get("client:k1"); err == nil {
+		t.Error("record written under another key was read")
+	}
+}
+
+func TestNewFileStoreRejectsInvalidKeys(t *testing.T) {
+	for _, key := range []string{"", "not base64", "c2hvcnQ="} {
+		if _, err := NewFileStore(t.TempDir(), key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("key %q: err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package idempotency
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the stores drop expired records while writing.
+const sweepInterval = time.Minute
+
+// ErrInvalidKey is returned by NewFileStore for a key that is not 32 bytes in base64.
+var ErrInvalidKey = errors.New("idempotency file store key must be 32 bytes in base64")
+
+// MemoryStore keeps records in memory; they are lost on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record), now: time.Now}
+}
+
+func (s *MemoryStore) Get(key string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok || !s.now().Before(record.ExpiresAt) {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (s *MemoryStore) Put(key string, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, r := range s.records {
+			if !now.Before(r.ExpiresAt) {
+				delete(s.records, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.records[key] = record
+	return nil
+}
+
+// FileStore keeps one file per key in a directory, so stored responses survive
+// a restart. File names are hashes of the keys.
+//
+// The responses carry customer data, so each record is encrypted with AES-256-GCM
+// under the store key, bound to its file name. A record written under another key
+// fails to read and is removed by the next sweep.
+type FileStore struct {
+	dir       string
+	aead      cipher.AEAD
+	mu        sync.Mutex
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewFileStore opens the store in dir. key is the base64 encoded 256-bit AES key.
+func NewFileStore(dir, key string) (*FileStore, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, aead: aead, now: time.Now}, nil
+}
+
+func newAEAD(key string) (cipher.AEAD, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".rec")
+}
+
+// seal encrypts a record as nonce || ciphertext || tag. The file name is the
+// additional data, so a record copied to the file of another key does not open.
+func (s *FileStore) seal(name string, record Record) ([]byte, error) {
+	plaintext, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plaintext, []byte(name)), nil
+}
+
+func (s *FileStore) open(name string, data []byte) (*Record, error) {
+	if len(data) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("idempotency record %s is truncated", name)
+	}
+	nonce, sealed := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, sealed, []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("idempotency record %s: %w", name, err)
+	}
+	var record Record
+	if err := json.Unmarshal(plaintext, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *FileStore) Get(key string) (*Record, error) {
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.open(filepath.Base(path), data)
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(record.ExpiresAt) {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// Put writes the record to a temporary file first, so a crash never leaves half a record.
+func (s *FileStore) Put(key string, record Record) error {
+	path := s.path(key)
+	data, err := s.seal(filepath.Base(path), record)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, "put-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.sweep()
+	return nil
+}
+
+func (s *FileStore) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".rec") {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		record, err := s.open(entry.Name(), data)
+		if err != nil || !now.Before(record.ExpiresAt) {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package idempotency
+
+import "connectorapi-go/pkg/config"
+
+// ValidateConfig checks cfg.Idempotency, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	i := cfg.Idempotency
+	if !i.Enabled {
+		return
+	}
+	switch i.Store {
+	case "memory":
+	case "file":
+		if i.Path == "" {
+			report.Add(config.SeverityError, "idempotency", "file store without a path")
+		}
+		if _, err := newAEAD(i.Key); err != nil {
+			report.Add(config.SeverityError, "idempotency", "file store key must be 32 bytes in base64, it encrypts the stored responses")
+		}
+	default:
+		report.Add(config.SeverityError, "idempotency", "unknown store %q, use memory or file", i.Store)
+	}
+	if i.TTL <= 0 || i.WaitTimeout <= 0 {
+		report.Add(config.SeverityError, "idempotency", "ttl and waitTimeout must be positive")
+	}
+	for _, route := range i.Routes {
+		if !scope.Handlers[route] {
+			report.Add(config.SeverityWarning, "idempotency", "idempotency route %q is not served by any handler", route)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	const updateConsent = "POST:/Api/Consent/UpdateConsent"
+	scope := config.ValidationScope{Handlers: map[string]bool{updateConsent: true}}
+	valid := func() config.IdempotencyConfig {
+		return config.IdempotencyConfig{Enabled: true, Store: "file", Path: "./idempotency", Key: testKey, TTL: 24 * time.Hour, WaitTimeout: 10 * time.Second, Routes: []string{updateConsent}}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(i *config.IdempotencyConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(i *config.IdempotencyConfig) {}, nil},
+		{"memory store needs no path or key", func(i *config.IdempotencyConfig) { i.Store, i.Path, i.Key = "memory", "", "" }, nil},
+		{"disabled is not checked", func(i *config.IdempotencyConfig) { *i = config.IdempotencyConfig{Store: "redis"} }, nil},
+		{"file store without path", func(i *config.IdempotencyConfig) { i.Path = "" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "idempotency", Message: "file store without a path"}}},
+		{"file store without key", func(i *config.IdempotencyConfig) { i.Key = "" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "idempotency", Message: "file store key must be 32 bytes in base64, it encrypts the stored responses"}}},
+		{"file store with short key", func(i *config.IdempotencyConfig) { i.Key = "c2hvcnQ=" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "idempotency", Message: "file store key must be 32 bytes in base64, it encrypts the stored responses"}}},
+		{"unknown store", func(i *config.IdempotencyConfig) { i.Store = "redis" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "idempotency", Message: `unknown store "redis", use memory or file`}}},
+		{"no ttl", func(i *config.IdempotencyConfig) { i.TTL = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "idempotency", Message: "ttl and waitTimeout must be positive"}}},
+		{"negative wait timeout", func(i *config.IdempotencyConfig) { i.WaitTimeout = -time.Second },
+			[]config.Issue{{Severity: config.SeverityError, Check: "idempotency", Message: "ttl and waitTimeout must be positive"}}},
+		{"unserved route", func(i *config.IdempotencyConfig) { i.Routes = append(i.Routes, "POST:/Api/Unknown") },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "idempotency", Message: `idempotency route "POST:/Api/Unknown" is not served by any handler`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Idempotency: valid()}
+			tt.mutate(&cfg.Idempotency)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
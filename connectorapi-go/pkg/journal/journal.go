@@ -0,0 +1,172 @@
+// Package journal is an append-only, hash-chained audit journal kept as JSON lines.
+//
+// Every entry carries the hash of the entry before it, so editing, removing or
+// reordering a line breaks the chain from that line on. Verify reports such breaks.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// GenesisHash is the PrevHash of the first entry.
+var GenesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+var ErrCorrupt = errors.New("audit journal is corrupt")
+
+// Entry is one state-changing request. Subject is already masked by the caller.
+type Entry struct {
+	Seq           uint64    `json:"seq"`
+	PrevHash      string    `json:"prevHash"`
+	Route         string    `json:"route"`
+	Client        string    `json:"client"`
+	RequestID     string    `json:"requestId"`
+	Subject       string    `json:"subject"`
+	SubjectField  string    `json:"subjectField,omitempty"`
+	Status        int       `json:"status"`
+	ErrorCode     string    `json:"errorCode,omitempty"`
+	SystemIResult string    `json:"systemIResult,omitempty"` // response code of System I, OK when blank, ER0xx when the call failed
+	RequestTime   time.Time `json:"requestTime"`
+	ResponseTime  time.Time `json:"responseTime"`
+	Hash          string    `json:"hash"`
+}
+
+// computeHash is the SHA-256 of the entry without its own hash.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Journal appends entries to one file. It is safe for concurrent use.
+type Journal struct {
+	mu       sync.Mutex
+	f        *os.File
+	seq      uint64
+	lastHash string
+}
+
+// Open opens or creates the journal at path and continues its chain.
+// It refuses a journal whose chain is broken, so new entries never hide an edit.
+func Open(path string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{f: f, lastHash: GenesisHash}
+	problems, err := verify(f, func(e Entry) { j.seq, j.lastHash = e.Seq, e.Hash })
+	if err == nil && len(problems) > 0 {
+		err = fmt.Errorf("%w: %s", ErrCorrupt, problems[0])
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// Append fills in Seq, PrevHash and Hash of e, writes it and syncs the file.
+func (j *Journal) Append(e Entry) (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e.Seq = j.seq + 1
+	e.PrevHash = j.lastHash
+	hash, err := e.computeHash()
+	if err != nil {
+		return e, err
+	}
+	e.Hash = hash
+	line, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return e, err
+	}
+	if err := j.f.Sync(); err != nil {
+		return e, err
+	}
+	j.seq, j.lastHash = e.Seq, e.Hash
+	return e, nil
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.f.Close()
+}
+
+// Problem is a break in the chain found by Verify.
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// Verify reads the journal at path and reports every line whose sequence number,
+// link to the previous entry or own hash does not match. It returns the number of
+// entries read.
+func Verify(path string) (int, []Problem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+	entries := 0
+	problems, err := verify(f, func(Entry) { entries++ })
+	return entries, problems, err
+}
+
+func verify(r io.Reader, each func(Entry)) ([]Problem, error) {
+	var problems []Problem
+	expectSeq, prevHash := uint64(1), GenesisHash
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			problems = append(problems, Problem{line, "empty line"})
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			problems = append(problems, Problem{line, "not a journal entry: " + err.Error()})
+			continue
+		}
+		if e.Seq != expectSeq {
+			problems = append(problems, Problem{line, fmt.Sprintf("seq %d, expected %d", e.Seq, expectSeq)})
+		}
+		if e.PrevHash != prevHash {
+			problems = append(problems, Problem{line, "prevHash does not match the previous entry"})
+		}
+		if hash, err := e.computeHash(); err != nil || hash != e.Hash {
+			problems = append(problems, Problem{line, "hash does not match the content"})
+		}
+		each(e)
+		expectSeq, prevHash = e.Seq+1, e.Hash
+	}
+	return problems, scanner.Err()
+}
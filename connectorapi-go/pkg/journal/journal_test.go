@@ -0,0 +1,89 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeEntries(t *testing.T, path string, n int) {
+	t.Helper()
+	j, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			now := time.Now()
+			if _, err := j.Append(Entry{Route: "POST:/Api/Consent/UpdateConsent", Client: "partner", RequestID: "REQ", Subject: "*********0123", Status: 200, RequestTime: now, ResponseTime: now}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestChainVerifies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "journal.log")
+	writeEntries(t, path, 20)
+	// Reopening continues the chain.
+	writeEntries(t, path, 5)
+
+	entries, problems, err := Verify(path)
+	if err != nil || len(problems) > 0 || entries != 25 {
+		t.Fatalf("entries = %d, problems = %v, err = %v", entries, problems, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v", info.Mode().Perm())
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		want   string
+	}{
+		{"edit", func(l []string) []string {
+			l[2] = strings.Replace(l[2], `"status":200`, `"status":400`, 1)
+			return l
+		}, "line 3: hash does not match"},
+		{"gap", func(l []string) []string { return append(l[:2], l[3:]...) }, "line 3: seq 4, expected 3"},
+		{"reorder", func(l []string) []string {
+			l[1], l[2] = l[2], l[1]
+			return l
+		}, "line 2: seq 3, expected 2"},
+		{"truncated line", func(l []string) []string {
+			l[4] = l[4][:20]
+			return l
+		}, "line 5: not a journal entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "journal.log")
+			writeEntries(t, path, 5)
+			raw, _ := os.ReadFile(path)
+			lines := tt.tamper(strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n"))
+			os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+
+			_, problems, err := Verify(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) == 0 || !strings.HasPrefix(problems[0].String(), tt.want) {
+				t.Errorf("problems = %v, want %q first", problems, tt.want)
+			}
+			if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Open on a tampered journal: err = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package journal
+
+import "connectorapi-go/pkg/config"
+
+// ValidateConfig checks cfg.Audit, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	a := cfg.Audit
+	if !a.Enabled {
+		return
+	}
+	if a.Path == "" {
+		report.Add(config.SeverityError, "audit", "audit is enabled without a path")
+	}
+	for _, route := range a.Routes {
+		if !scope.Handlers[route] {
+			report.Add(config.SeverityWarning, "audit", "audit route %q is not served by any handler", route)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package journal
+
+import (
+	"reflect"
+	"testing"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	const updateConsent = "POST:/Api/Consent/UpdateConsent"
+	scope := config.ValidationScope{Handlers: map[string]bool{updateConsent: true}}
+
+	tests := []struct {
+		name  string
+		audit config.AuditConfig
+		want  []config.Issue
+	}{
+		{"valid", config.AuditConfig{Enabled: true, Path: "./audit/journal.log", Routes: []string{updateConsent}}, nil},
+		{"disabled is not checked", config.AuditConfig{Routes: []string{"POST:/Api/Unknown"}}, nil},
+		{"no path", config.AuditConfig{Enabled: true, Routes: []string{updateConsent}},
+			[]config.Issue{{Severity: config.SeverityError, Check: "audit", Message: "audit is enabled without a path"}}},
+		{"unserved route", config.AuditConfig{Enabled: true, Path: "./audit/journal.log", Routes: []string{updateConsent, "POST:/Api/Unknown"}},
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "audit", Message: `audit route "POST:/Api/Unknown" is not served by any handler`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, &config.Config{Audit: tt.audit}, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,13 @@ import (
 
 // New creates a new Zap SugaredLogger with a pre-defined enterprise-friendly configuration.
 func New(level string) *zap.SugaredLogger {
+	logger, _ := NewWithLevel(level)
+	return logger
+}
+
+// NewWithLevel is New, also returning the level of the logger so it can be
+// changed while the logger is in use, e.g. to debug from the admin API.
+func NewWithLevel(level string) (*zap.SugaredLogger, zap.AtomicLevel) {
 	logLevel, err := zapcore.ParseLevel(level)
 	if err != nil {
 		// Default to InfoLevel if the provided level is invalid.
@@ -17,9 +24,10 @@ func New(level string) *zap.SugaredLogger {
 	}
 
 	// A manually crafted config gives us more control over the final log structure.
+	atomicLevel := zap.NewAtomicLevelAt(logLevel)
 	config := zap.Config{
 		Encoding: "json",
-		Level:    zap.NewAtomicLevelAt(logLevel),
+		Level:    atomicLevel,
 		// Corrected syntax: removed extra curly braces
 		OutputPaths:      []string{"stdout"},
 		ErrorOutputPaths: []string{"stderr"},
@@ -35,11 +43,11 @@ func New(level string) *zap.SugaredLogger {
 	}
 
 	logger, _ := config.Build()
-	return logger.Sugar()
+	return logger.Sugar(), atomicLevel
 }
 
 // GinLogger is a Gin middleware that logs requests using our configured SugaredLogger.
-func GinLogger(logger *zap.SugaredLogger, apiRequestID string, apiLanguage string, apiDeviceOS string, apiChannel string,) gin.HandlerFunc {
+func GinLogger(logger *zap.SugaredLogger, apiRequestID string, apiKeyID string, apiLanguage string, apiDeviceOS string, apiChannel string,) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
@@ -54,6 +62,7 @@ func GinLogger(logger *zap.SugaredLogger, apiRequestID string, apiLanguage strin
 				logger.Errorw("Request error",
 					"error", e.Err,
 					"apiRequestID", c.GetString(apiRequestID),
+					"apiKeyID", c.GetString(apiKeyID),
 					"apiLanguage", c.GetString(apiLanguage),
 					"apiDeviceOS", c.GetString(apiDeviceOS),
 					"apiChannel", c.GetString(apiChannel),
@@ -70,6 +79,7 @@ func GinLogger(logger *zap.SugaredLogger, apiRequestID string, apiLanguage strin
 				"latency", latency.String(),
 				"user_agent", c.Request.UserAgent(),
 				"apiRequestID", c.GetString(apiRequestID),
+				"apiKeyID", c.GetString(apiKeyID),
 				"apiLanguage", c.GetString(apiLanguage),
 				"apiDeviceOS", c.GetString(apiDeviceOS),
 				"apiChannel", c.GetString(apiChannel),
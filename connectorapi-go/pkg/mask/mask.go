@@ -0,0 +1,353 @@
+// Package mask removes personal data from what is written to the ELK and application logs.
+//
+// JSON messages are masked by field name, System I fixed-length messages by the offsets
+// of their route's layout; the body of a System I message without a layout is masked
+// whole. Whatever is left is scanned for card numbers, so a PAN is truncated even in a
+// field nobody configured. Values encrypted for a client by pkg/fieldcrypt are kept.
+package mask
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/fieldcrypt"
+)
+
+// Strategies of config.MaskingConfig.Fields.
+const (
+	StrategyPAN     = "pan"     // first and last digits of config.PANMask, e.g. 411111******1111
+	StrategyLast4   = "last4"   // everything but the last 4 characters
+	StrategyInitial = "initial" // the first character of every word, for names
+	StrategyFull    = "full"    // every character
+	StrategyNone    = "none"    // left as is, the card number scan still applies
+)
+
+// Direction tells which layout of a route a fixed-length message follows.
+type Direction int
+
+const (
+	Request Direction = iota
+	Response
+)
+
+// headerLen is the length of the System I header in front of every fixed-length message.
+const headerLen = 123
+
+// The request date, time, length and result code of the header sit between these offsets.
+// Together they are a run of digits that may pass the Luhn check, but never a card number.
+const (
+	headerNumbersStart = 48
+	headerNumbersEnd   = 73
+)
+
+const maskChar = '*'
+
+// Masker applies a config.MaskingConfig. It is safe for concurrent use.
+type Masker struct {
+	panFirst int
+	panLast  int
+	fields   map[string]string // lower-case field name -> strategy
+	layouts  map[string]config.MaskLayout
+}
+
+func New(cfg config.MaskingConfig) *Masker {
+	m := &Masker{
+		panFirst: cfg.PAN.First,
+		panLast:  cfg.PAN.Last,
+		fields:   make(map[string]string, len(cfg.Fields)),
+		layouts:  cfg.Layouts,
+	}
+	if m.panFirst <= 0 && m.panLast <= 0 {
+		m.panFirst, m.panLast = 6, 4
+	}
+	for name, strategy := range cfg.Fields {
+		m.fields[strings.ToLower(name)] = strings.ToLower(strategy)
+	}
+	return m
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultMasker = New(config.MaskingConfig{})
+)
+
+// SetDefault replaces the masker used by the package-level functions.
+func SetDefault(m *Masker) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultMasker = m
+}
+
+// Default returns the masker used by the package-level functions.
+func Default() *Masker {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultMasker
+}
+
+// Value masks a log message of route with the default masker, see Masker.Value.
+func Value(route string, dir Direction, v interface{}) interface{} {
+	return Default().Value(route, dir, v)
+}
+
+// Payload masks a fixed-length message of route with the default masker, see Masker.Payload.
+func Payload(route string, dir Direction, payload string) string {
+	return Default().Payload(route, dir, payload)
+}
+
+// Field masks one named value with the default masker, see Masker.Field.
+func Field(name, value string) string {
+	return Default().Field(name, value)
+}
+
+// Value returns a masked copy of a log message: a struct, map or string.
+// Strings that carry a System I message, such as the {"data": payload} of the
+// ELK lines, are masked with the layout of route for dir.
+func (m *Masker) Value(route string, dir Direction, v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return m.Payload(route, dir, value)
+	case map[string]string:
+		masked := make(map[string]string, len(value))
+		for k, s := range value {
+			masked[k] = m.field(route, dir, k, s)
+		}
+		return masked
+	}
+
+	// Structs are masked through their JSON form, the form they are logged in.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil
+	}
+	return m.walk(route, dir, "", doc)
+}
+
+func (m *Masker) walk(route string, dir Direction, name string, v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, child := range value {
+			value[k] = m.walk(route, dir, k, child)
+		}
+		return value
+	case []interface{}:
+		for i, child := range value {
+			value[i] = m.walk(route, dir, name, child)
+		}
+		return value
+	case string:
+		return m.field(route, dir, name, value)
+	case json.Number:
+		// Card numbers sent as JSON numbers are still card numbers.
+		if strategy, ok := m.fields[strings.ToLower(name)]; ok && strategy != StrategyNone {
+			return m.apply(strategy, value.String())
+		}
+		if masked := m.PANs(value.String()); masked != value.String() {
+			return masked
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// Field masks value with the strategy configured for name, keeping only the
+// last four characters when name has none. Used where a value must always be masked,
+// such as the subject of an audit entry.
+func (m *Masker) Field(name, value string) string {
+	strategy, ok := m.fields[strings.ToLower(name)]
+	if !ok || strategy == StrategyNone {
+		strategy = StrategyLast4
+	}
+	return m.PANs(m.apply(strategy, value))
+}
+
+func (m *Masker) field(route string, dir Direction, name, value string) string {
+	if fieldcrypt.IsCiphertext(value) {
+		// Encrypted for the client, such as a card number of MobileFullPAN.
+		return value
+	}
+	if strategy, ok := m.fields[strings.ToLower(name)]; ok {
+		return m.PANs(m.apply(strategy, value))
+	}
+	return m.Payload(route, dir, value)
+}
+
+// Payload masks the layout fields of a System I message and every card number in it.
+// Without a layout for route and dir nobody knows where the personal data sits, so only
+// the header is kept. Strings that are not System I messages only get the card number scan.
+func (m *Masker) Payload(route string, dir Direction, payload string) string {
+	runes := []rune(payload)
+	if len(runes) <= headerLen {
+		return m.PANs(payload)
+	}
+	layout := m.layouts[route]
+	fields := layout.Request
+	if dir == Response {
+		fields = layout.Response
+	}
+	body := runes[headerLen:]
+	if len(fields) == 0 {
+		return m.headerPANs(runes[:headerLen]) + m.apply(StrategyFull, string(body))
+	}
+
+	for _, f := range fields {
+		strategy := m.fields[strings.ToLower(f.Name)]
+		if strategy == "" {
+			strategy = StrategyFull
+		}
+		for start := f.Offset; start >= 0 && start < len(body); start += f.Every {
+			end := start + f.Length
+			if end > len(body) {
+				end = len(body)
+			}
+			copy(body[start:end], []rune(m.apply(strategy, string(body[start:end]))))
+			if f.Every <= 0 {
+				break
+			}
+		}
+	}
+	return m.headerPANs(runes[:headerLen]) + m.PANs(string(body))
+}
+
+// headerPANs truncates the card numbers in a header, leaving its date, time, length
+// and result code alone.
+func (m *Masker) headerPANs(header []rune) string {
+	return m.PANs(string(header[:headerNumbersStart])) +
+		string(header[headerNumbersStart:headerNumbersEnd]) +
+		m.PANs(string(header[headerNumbersEnd:]))
+}
+
+// apply masks value with strategy, keeping the padding of fixed-length fields.
+func (m *Masker) apply(strategy, value string) string {
+	trimmed := strings.TrimRight(value, " ")
+	padding := value[len(trimmed):]
+	runes := []rune(trimmed)
+
+	switch strategy {
+	case StrategyNone:
+		return value
+	case StrategyPAN:
+		keepRunes(runes, m.panFirst, m.panLast)
+	case StrategyLast4:
+		keepRunes(runes, 0, 4)
+	case StrategyInitial:
+		start := true
+		for i, r := range runes {
+			if r == ' ' {
+				start = true
+				continue
+			}
+			if !start {
+				runes[i] = maskChar
+			}
+			start = false
+		}
+	default:
+		keepRunes(runes, 0, 0)
+	}
+	return string(runes) + padding
+}
+
+// keepRunes masks all but the first and last runes. Values too short to keep
+// anything hidden are masked completely.
+func keepRunes(runes []rune, first, last int) {
+	if first+last >= len(runes) {
+		first, last = 0, 0
+	}
+	for i := first; i < len(runes)-last; i++ {
+		if runes[i] != ' ' && runes[i] != '-' {
+			runes[i] = maskChar
+		}
+	}
+}
+
+// PANs truncates every card number in s: a run of 13 to 19 digits that passes
+// the Luhn check, also when its groups are separated by single spaces or dashes.
+func (m *Masker) PANs(s string) string {
+	var out []byte
+	last := 0
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) || (i > 0 && isDigit(s[i-1])) {
+			continue
+		}
+		end := -1
+		if grouped, digits := scanPAN(s, i); digits >= 13 && digits <= 19 && luhn(s[i:grouped]) {
+			end = grouped
+		} else if plain := digitsEnd(s, i); plain-i >= 13 && plain-i <= 19 && luhn(s[i:plain]) {
+			end = plain
+		}
+		if end < 0 {
+			continue
+		}
+		if out == nil {
+			out = make([]byte, 0, len(s))
+		}
+		out = append(out, s[last:i]...)
+		out = append(out, m.apply(StrategyPAN, s[i:end])...)
+		last = end
+		i = end - 1
+	}
+	if out == nil {
+		return s
+	}
+	return string(append(out, s[last:]...))
+}
+
+// scanPAN returns the end of the digit run starting at i and its number of digits.
+// Single separators between digit groups belong to the run.
+func scanPAN(s string, i int) (end, digits int) {
+	end = i
+	for j := i; j < len(s); j++ {
+		switch {
+		case isDigit(s[j]):
+			digits++
+			end = j + 1
+		case (s[j] == ' ' || s[j] == '-') && j+1 < len(s) && isDigit(s[j+1]) && isDigit(s[j-1]):
+		default:
+			return end, digits
+		}
+	}
+	return end, digits
+}
+
+func digitsEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if !isDigit(s[i]) {
+			continue
+		}
+		d := int(s[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
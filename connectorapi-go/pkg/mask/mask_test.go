@@ -0,0 +1,263 @@
+package mask
+
+import (
+	"strings"
+	"testing"
+
+	"connectorapi-go/pkg/config"
+	"connectorapi-go/pkg/fieldcrypt"
+)
+
+const fullPanRoute = "POST:/Api/Mobile/MobileFullPAN"
+
+// Luhn-valid test card numbers.
+var pans = []string{"4111111111111111", "5500005555555559", "4012888888881881"}
+
+func testMasker() *Masker {
+	return New(config.MaskingConfig{
+		PAN: config.PANMask{First: 6, Last: 4},
+		Fields: map[string]string{
+			"CardNo":         "pan",
+			"IDCardNo":       "last4",
+			"CustomerNameEN": "initial",
+			"Email":          "full",
+		},
+		Layouts: map[string]config.MaskLayout{
+			fullPanRoute: {
+				Request: []config.MaskField{
+					{Name: "IDCardNo", Offset: 0, Length: 20},
+					{Name: "CardNo", Offset: 20, Length: 16},
+				},
+				Response: []config.MaskField{
+					{Name: "IDCardNo", Offset: 0, Length: 20},
+					{Name: "CardNo", Offset: 24, Length: 16, Every: 61},
+				},
+			},
+		},
+	})
+}
+
+func assertNoPAN(t *testing.T, s string) {
+	t.Helper()
+	for _, pan := range pans {
+		if strings.Contains(s, pan) {
+			t.Errorf("unmasked card number %s in %q", pan, s)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	m := testMasker()
+	tests := []struct {
+		strategy, value, want string
+	}{
+		{StrategyPAN, "4111111111111111", "411111******1111"},
+		{StrategyPAN, "4111111111111111    ", "411111******1111    "},
+		{StrategyPAN, "12345", "*****"},
+		{StrategyLast4, "1234567890123", "*********0123"},
+		{StrategyInitial, "Somchai Jaidee", "S****** J*****"},
+		{StrategyInitial, "สมชาย ใจดี", "ส**** ใ***"},
+		{StrategyFull, "a@b.co", "******"},
+		{StrategyNone, "keep", "keep"},
+	}
+	for _, tt := range tests {
+		if got := m.apply(tt.strategy, tt.value); got != tt.want {
+			t.Errorf("apply(%s, %q) = %q, want %q", tt.strategy, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPANs(t *testing.T) {
+	m := testMasker()
+	tests := []struct {
+		in, want string
+	}{
+		{"card 4111111111111111 declined", "card 411111******1111 declined"},
+		{"4111 1111 1111 1111", "4111 1*** **** 1111"},
+		{"4111-1111-1111-1111", "4111-1***-****-1111"},
+		{"a4012888888881881b", "a401288******1881b"},
+		// Not a card number: fails Luhn, too short or too long.
+		{"4111111111111112", "4111111111111112"},
+		{"0812345678", "0812345678"},
+		{"41111111111111110000", "41111111111111110000"},
+		// Padded fields next to each other keep their own runs.
+		{"1234567890123       4111111111111111", "1234567890123       411111******1111"},
+	}
+	for _, tt := range tests {
+		if got := m.PANs(tt.in); got != tt.want {
+			t.Errorf("PANs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValueMasksJSONFields(t *testing.T) {
+	type card struct {
+		CardNo   string `json:"CardNo"`
+		CardCode string `json:"CardCode"`
+	}
+	req := struct {
+		IDCardNo  string `json:"IDCardNo"`
+		Name      string `json:"customerNameEn"`
+		Note      string `json:"Note"`
+		TotalCard int    `json:"TotalCard"`
+		Cards     []card `json:"CardList_rq"`
+	}{
+		IDCardNo:  "1234567890123",
+		Name:      "Somchai Jaidee",
+		Note:      "pay with " + pans[2],
+		TotalCard: 2,
+		Cards:     []card{{pans[0], "01"}, {pans[1], "02"}},
+	}
+
+	got := testMasker().Value(fullPanRoute, Request, req).(map[string]interface{})
+	if got["IDCardNo"] != "*********0123" {
+		t.Errorf("IDCardNo = %v", got["IDCardNo"])
+	}
+	if got["customerNameEn"] != "S****** J*****" {
+		t.Errorf("field names are matched in any case, got %v", got["customerNameEn"])
+	}
+	if got["TotalCard"].(interface{ String() string }).String() != "2" {
+		t.Errorf("TotalCard = %v", got["TotalCard"])
+	}
+	cards := got["CardList_rq"].([]interface{})
+	if c := cards[1].(map[string]interface{}); c["CardNo"] != "550000******5559" || c["CardCode"] != "02" {
+		t.Errorf("card = %v", c)
+	}
+	if got["Note"] != "pay with 401288******1881" {
+		t.Errorf("Note = %v", got["Note"])
+	}
+	if req.Cards[0].CardNo != pans[0] {
+		t.Error("Value changed its argument")
+	}
+}
+
+func TestPayloadUsesRouteLayout(t *testing.T) {
+	m := testMasker()
+	header := strings.Repeat("H", headerLen)
+
+	// The ID card number runs into the card number, only the layout finds the latter.
+	request := header + "12345678901234567890" + pans[0] + "01"
+	got := m.Payload(fullPanRoute, Request, request)
+	want := header + "****************7890" + "411111******1111" + "01"
+	if got != want {
+		t.Errorf("request\n got %q\nwant %q", got, want)
+	}
+
+	block := func(pan string) string {
+		return pan + "สมชาย ใจดี" + strings.Repeat(" ", 20) + "01" + "02" + "00" + "20290131" + "N"
+	}
+	response := header + "1234567890123       " + "0002" + block("4111111111111111") + block("5500005555555559")
+	got = m.Payload(fullPanRoute, Response, response)
+	assertNoPAN(t, got)
+	if !strings.Contains(got, "550000******5559สมชาย") || !strings.HasSuffix(got, "01020020290131N") {
+		t.Errorf("response masked outside the card numbers: %q", got)
+	}
+
+	other := header + "card " + pans[1] + " on a route without layout"
+	assertNoPAN(t, m.Payload("POST:/Api/Other", Response, other))
+}
+
+func TestPayloadKeepsHeaderNumbers(t *testing.T) {
+	m := testMasker()
+	// Date, time, length and result code happen to form a number passing the Luhn check.
+	numbers := pans[0] + "   " + "000000"
+	message := "card " + pans[1]
+	header := strings.Repeat("H", headerNumbersStart) + numbers + message + strings.Repeat(" ", headerLen-headerNumbersEnd-len(message))
+
+	for _, route := range []string{fullPanRoute, "POST:/Api/Other"} {
+		got := m.Payload(route, Response, header+"1234567890123       0000")
+		if got[headerNumbersStart:headerNumbersEnd] != numbers {
+			t.Errorf("%s: header numbers = %q, want %q", route, got[headerNumbersStart:headerNumbersEnd], numbers)
+		}
+		if !strings.Contains(got, "card 550000******5559") {
+			t.Errorf("%s: card number in the header message not masked: %q", route, got)
+		}
+	}
+}
+
+func TestFieldAlwaysMasks(t *testing.T) {
+	m := testMasker()
+	tests := []struct {
+		name, value, want string
+	}{
+		{"Email", "somchai@example.com", "*******************"},
+		{"idcardno", "1234567890123", "*********0123"},
+		{"AEONID", "AEON00012345", "********2345"},
+	}
+	for _, tt := range tests {
+		if got := m.Field(tt.name, tt.value); got != tt.want {
+			t.Errorf("Field(%q, %q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestPayloadHidesPersonalDataOnEveryRoute masks a message of every configured route with
+// the shipped masking config: routes with a layout must hide what sits in its fields, the
+// others must not show their body at all.
+func TestPayloadHidesPersonalDataOnEveryRoute(t *testing.T) {
+	cfg, err := config.Load("../../configs/config.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dr, err := config.LoadDestinationsAndRoutes("../../configs/destinations_routes.json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(cfg.Masking)
+	header := strings.Repeat("H", headerLen)
+	personal := []string{"3101234567890", "0812345678", "Somchai", "Jaidee", "somchai@example.com"}
+
+	for route := range dr.Routes {
+		for _, dir := range []Direction{Request, Response} {
+			layout := cfg.Masking.Layouts[route]
+			fields := layout.Request
+			if dir == Response {
+				fields = layout.Response
+			}
+
+			if len(fields) == 0 {
+				got := m.Payload(route, dir, header+"3101234567890 0812345678 Somchai Jaidee somchai@example.com 19800101")
+				if !strings.HasPrefix(got, header) {
+					t.Errorf("%s %d: header lost: %q", route, dir, got)
+				}
+				for _, value := range personal {
+					if strings.Contains(got, value) {
+						t.Errorf("%s %d: %s in clear in %q", route, dir, value, got)
+					}
+				}
+				continue
+			}
+
+			body := []rune(strings.Repeat(" ", 400))
+			var values []string
+			for _, f := range fields {
+				value := []rune(strings.Repeat("Somchai Jaidee 3101234567890 ", 3))[:f.Length]
+				copy(body[f.Offset:], value)
+				values = append(values, string(value))
+			}
+			got := m.Payload(route, dir, header+string(body))
+			for i, value := range values {
+				if strings.Contains(got, value) {
+					t.Errorf("%s %d: %s (%q) in clear in %q", route, dir, fields[i].Name, value, got)
+				}
+			}
+		}
+	}
+}
+
+func TestValueKeepsCiphertext(t *testing.T) {
+	e, err := fieldcrypt.New(fieldcrypt.MethodAESGCM, "", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, _ := e.Encrypt(pans[0])
+	resp := map[string]interface{}{"IDCardNo": "1234567890123", "CardListRs": []map[string]string{{"CardNo": ciphertext}}}
+
+	got := testMasker().Value(fullPanRoute, Response, resp).(map[string]interface{})
+	if c := got["CardListRs"].([]interface{})[0].(map[string]interface{}); c["CardNo"] != ciphertext {
+		t.Errorf("CardNo = %v, want the ciphertext %s", c["CardNo"], ciphertext)
+	}
+	if got["IDCardNo"] != "*********0123" {
+		t.Errorf("IDCardNo = %v", got["IDCardNo"])
+	}
+}
@@ -0,0 +1,36 @@
+package mask
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"connectorapi-go/pkg/config"
+)
+
+// strategies are the strategies Masker implements.
+var strategies = map[string]bool{StrategyPAN: true, StrategyLast4: true, StrategyInitial: true, StrategyFull: true, StrategyNone: true}
+
+// ValidateConfig checks cfg.Masking, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	m := cfg.Masking
+	if m.PAN.First < 0 || m.PAN.Last < 0 || m.PAN.First+m.PAN.Last > 10 {
+		report.Add(config.SeverityError, "masking", "pan keeps %d+%d digits, at most 6+4 are allowed", m.PAN.First, m.PAN.Last)
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.Fields)) {
+		if !strategies[strings.ToLower(m.Fields[name])] {
+			report.Add(config.SeverityError, "masking", "field %q has unknown strategy %q", name, m.Fields[name])
+		}
+	}
+	for _, route := range slices.Sorted(maps.Keys(m.Layouts)) {
+		if !scope.Handlers[route] {
+			report.Add(config.SeverityWarning, "masking", "layout for %q which no handler serves", route)
+		}
+		layout := m.Layouts[route]
+		for _, f := range append(append([]config.MaskField{}, layout.Request...), layout.Response...) {
+			if f.Offset < 0 || f.Length <= 0 || f.Every < 0 || (f.Every > 0 && f.Every < f.Length) {
+				report.Add(config.SeverityError, "masking", "layout for %q has an invalid field %q", route, f.Name)
+			}
+		}
+	}
+}
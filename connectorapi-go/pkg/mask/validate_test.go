@@ -0,0 +1,58 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	scope := config.ValidationScope{Handlers: map[string]bool{fullPanRoute: true}}
+	valid := func() config.MaskingConfig {
+		return config.MaskingConfig{
+			PAN:    config.PANMask{First: 6, Last: 4},
+			Fields: map[string]string{"CardNo": "PAN", "IDCardNo": "last4"},
+			Layouts: map[string]config.MaskLayout{fullPanRoute: {
+				Request:  []config.MaskField{{Name: "IDCardNo", Offset: 0, Length: 20}},
+				Response: []config.MaskField{{Name: "CardNo", Offset: 20, Length: 16, Every: 36}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(m *config.MaskingConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(m *config.MaskingConfig) {}, nil},
+		{"pan keeps too many digits", func(m *config.MaskingConfig) { m.PAN = config.PANMask{First: 8, Last: 4} },
+			[]config.Issue{{Severity: config.SeverityError, Check: "masking", Message: "pan keeps 8+4 digits, at most 6+4 are allowed"}}},
+		{"negative pan digits", func(m *config.MaskingConfig) { m.PAN.Last = -1 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "masking", Message: "pan keeps 6+-1 digits, at most 6+4 are allowed"}}},
+		{"unknown strategy", func(m *config.MaskingConfig) { m.Fields["CardNo"] = "hash" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "masking", Message: `field "CardNo" has unknown strategy "hash"`}}},
+		{"unserved layout", func(m *config.MaskingConfig) {
+			m.Layouts["POST:/Api/Unknown"] = config.MaskLayout{Request: []config.MaskField{{Name: "CardNo", Length: 16}}}
+		}, []config.Issue{{Severity: config.SeverityWarning, Check: "masking", Message: `layout for "POST:/Api/Unknown" which no handler serves`}}},
+		{"field without length", func(m *config.MaskingConfig) {
+			m.Layouts[fullPanRoute] = config.MaskLayout{Request: []config.MaskField{{Name: "IDCardNo"}}}
+		}, []config.Issue{{Severity: config.SeverityError, Check: "masking", Message: `layout for "POST:/Api/Mobile/MobileFullPAN" has an invalid field "IDCardNo"`}}},
+		{"repeat shorter than field", func(m *config.MaskingConfig) {
+			m.Layouts[fullPanRoute] = config.MaskLayout{Response: []config.MaskField{{Name: "CardNo", Offset: 20, Length: 16, Every: 8}}}
+		}, []config.Issue{{Severity: config.SeverityError, Check: "masking", Message: `layout for "POST:/Api/Mobile/MobileFullPAN" has an invalid field "CardNo"`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Masking: valid()}
+			tt.mutate(&cfg.Masking)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
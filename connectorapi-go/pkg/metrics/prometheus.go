@@ -6,6 +6,26 @@ import (
 var (
 	HttpRequestsTotal   *prometheus.CounterVec
 	HttpRequestDuration *prometheus.HistogramVec
+	APIKeyExpirySeconds *prometheus.GaugeVec
+	ThrottledRequestsTotal *prometheus.CounterVec
+	AuditWriteFailuresTotal *prometheus.CounterVec
+	ReplayRejectionsTotal *prometheus.CounterVec
+	ELKLogQueueDepth      prometheus.Gauge
+	ELKLogDroppedTotal    *prometheus.CounterVec
+	ELKLogErrorsTotal     *prometheus.CounterVec
+	ELKSinkDocumentsTotal *prometheus.CounterVec
+	ELKSinkSpoolBytes     prometheus.Gauge
+	SystemIDialDuration         *prometheus.HistogramVec
+	SystemIRequestDuration      *prometheus.HistogramVec
+	SystemISentBytesTotal       *prometheus.CounterVec
+	SystemIReceivedBytesTotal   *prometheus.CounterVec
+	SystemITransportErrorsTotal *prometheus.CounterVec
+	SystemIResponsesTotal       *prometheus.CounterVec
+	SystemIInFlight             *prometheus.GaugeVec
+	ResponseCacheRequestsTotal  *prometheus.CounterVec
+	ResponseCacheEntries        prometheus.Gauge
+	ResponseCacheEvictionsTotal prometheus.Counter
+	CoalescedRequestsTotal      *prometheus.CounterVec
 )
 func Init() {
 	HttpRequestsTotal = promauto.NewCounterVec(
@@ -23,4 +43,142 @@ func Init() {
 		},
 		[]string{"method", "path", "status"},
 	)
+	APIKeyExpirySeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "api_key_expiry_seconds",
+			Help: "Seconds until an API key expires, negative once expired.",
+		},
+		[]string{"client", "key_id"},
+	)
+	ThrottledRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_throttled_requests_total",
+			Help: "Requests rejected by rate limits or daily quotas.",
+		},
+		[]string{"client", "path", "reason"},
+	)
+	AuditWriteFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_journal_write_failures_total",
+			Help: "Audit journal entries that could not be written.",
+		},
+		[]string{"path"},
+	)
+	ReplayRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_replay_rejections_total",
+			Help: "Requests rejected for a reused Api-RequestID or an invalid Api-Timestamp.",
+		},
+		[]string{"client", "reason"},
+	)
+	ELKLogQueueDepth = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "elk_log_queue_depth",
+			Help: "ELK log entries waiting for the background writer.",
+		},
+	)
+	ELKLogDroppedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "elk_log_dropped_total",
+			Help: "ELK log entries dropped before they were written.",
+		},
+		[]string{"reason"},
+	)
+	ELKLogErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "elk_log_errors_total",
+			Help: "Failures generating, writing, rotating or compressing ELK log files.",
+		},
+		[]string{"op"},
+	)
+	ELKSinkDocumentsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "elk_sink_documents_total",
+			Help: "ELK documents by outcome at the Elasticsearch or Logstash sink: sent, spooled, rejected, dropped.",
+		},
+		[]string{"result"},
+	)
+	ELKSinkSpoolBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "elk_sink_spool_bytes",
+			Help: "Size of the ELK batches spooled on disk while the sink is unreachable.",
+		},
+	)
+	SystemIDialDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "systemi_dial_duration_seconds",
+			Help:    "Time to open the TCP connection to a System I port, failed attempts included.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"destination", "port"},
+	)
+	SystemIRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "systemi_request_duration_seconds",
+			Help:    "Round trip of a System I call from dial to decoded response.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"route", "port"},
+	)
+	SystemISentBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "systemi_sent_bytes_total",
+			Help: "Bytes written to System I.",
+		},
+		[]string{"route", "port"},
+	)
+	SystemIReceivedBytesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "systemi_received_bytes_total",
+			Help: "Bytes read from System I.",
+		},
+		[]string{"route", "port"},
+	)
+	SystemITransportErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "systemi_transport_errors_total",
+			Help: "Failed System I calls by class: ER040 connect, ER050 read timeout, ER060 write or read, ER099 encoding.",
+		},
+		[]string{"route", "port", "class"},
+	)
+	SystemIResponsesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "systemi_responses_total",
+			Help: "System I responses by response code of the header, OK when blank.",
+		},
+		[]string{"route", "code"},
+	)
+	SystemIInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "systemi_in_flight",
+			Help: "System I calls waiting for their response.",
+		},
+		[]string{"destination"},
+	)
+	ResponseCacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "response_cache_requests_total",
+			Help: "Requests to cached routes by result: hit, miss or bypass.",
+		},
+		[]string{"route", "result"},
+	)
+	ResponseCacheEntries = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "response_cache_entries",
+			Help: "Responses held in the response cache.",
+		},
+	)
+	ResponseCacheEvictionsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "response_cache_evictions_total",
+			Help: "Cached responses evicted to stay within maxEntries.",
+		},
+	)
+	CoalescedRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "coalesced_requests_total",
+			Help: "Requests answered with the response of an identical concurrent request, without a System I call of their own.",
+		},
+		[]string{"route"},
+	)
 }
\ No newline at end of file
@@ -0,0 +1,227 @@
+package permission
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNotPermitted     = errors.New("route not permitted")
+	ErrValueNotAllowed  = errors.New("request value not allowed")
+	ErrMalformedPattern = errors.New("malformed permission")
+)
+
+// Pattern is a parsed METHOD:/path permission. The method may be "*" and the path
+// may contain glob wildcards, where "*" matches within one path segment,
+// e.g. POST:/Api/Mobile/*.
+type Pattern struct {
+	Method string
+	Path   string
+	glob   bool
+}
+
+// ParsePattern parses and checks a permission pattern.
+func ParsePattern(s string) (Pattern, error) {
+	method, p, ok := strings.Cut(s, ":")
+	if !ok || method == "" || !strings.HasPrefix(p, "/") || strings.ToUpper(method) != method {
+		return Pattern{}, fmt.Errorf("%w %q, want METHOD:/path", ErrMalformedPattern, s)
+	}
+	glob := strings.ContainsAny(p, "*?[")
+	if glob {
+		if _, err := path.Match(p, ""); err != nil {
+			return Pattern{}, fmt.Errorf("%w %q: %v", ErrMalformedPattern, s, err)
+		}
+	}
+	return Pattern{Method: method, Path: p, glob: glob || method == "*"}, nil
+}
+
+// IsGlob reports whether the pattern contains wildcards.
+func (p Pattern) IsGlob() bool {
+	return p.glob
+}
+
+// Matches reports whether the pattern grants method on the given path.
+func (p Pattern) Matches(method, requestPath string) bool {
+	if p.Method != "*" && p.Method != method {
+		return false
+	}
+	if p.Path == requestPath {
+		return true
+	}
+	ok, _ := path.Match(p.Path, requestPath)
+	return ok
+}
+
+// rule is a compiled permission with its body constraints,
+// field name -> allowed values.
+type rule struct {
+	pattern     Pattern
+	constraints map[string]map[string]bool
+}
+
+// Matcher holds the precompiled permissions of one client.
+// Exact permissions are looked up by route key, only globs are scanned.
+type Matcher struct {
+	exact map[string][]*rule
+	globs []*rule
+}
+
+func NewMatcher() *Matcher {
+	return &Matcher{exact: make(map[string][]*rule)}
+}
+
+// Add compiles a permission. constraints restricts top-level body fields to the listed values.
+func (m *Matcher) Add(pattern string, constraints map[string][]string) error {
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		return err
+	}
+	r := &rule{pattern: p}
+	if len(constraints) > 0 {
+		r.constraints = make(map[string]map[string]bool, len(constraints))
+		for field, values := range constraints {
+			if len(values) == 0 {
+				return fmt.Errorf("%w %q: constraint on %q allows no value", ErrMalformedPattern, pattern, field)
+			}
+			allowed := make(map[string]bool, len(values))
+			for _, v := range values {
+				allowed[v] = true
+			}
+			r.constraints[field] = allowed
+		}
+	}
+	if p.IsGlob() {
+		m.globs = append(m.globs, r)
+	} else {
+		key := p.Method + ":" + p.Path
+		m.exact[key] = append(m.exact[key], r)
+	}
+	return nil
+}
+
+// Allow checks a request against the compiled permissions. It succeeds when any
+// permission matching the route accepts the body. The body is only decoded when
+// a matching permission carries constraints.
+func (m *Matcher) Allow(method, requestPath string, body []byte) error {
+	var fields map[string]interface{}
+	var decoded bool
+	var violation error
+	matched := false
+
+	check := func(r *rule) bool {
+		matched = true
+		if len(r.constraints) == 0 {
+			return true
+		}
+		if !decoded {
+			fields = decodeBody(body)
+			decoded = true
+		}
+		if err := r.allows(fields); err != nil {
+			violation = err
+			return false
+		}
+		return true
+	}
+
+	for _, r := range m.exact[method+":"+requestPath] {
+		if check(r) {
+			return nil
+		}
+	}
+	for _, r := range m.globs {
+		if r.pattern.Matches(method, requestPath) && check(r) {
+			return nil
+		}
+	}
+	if !matched {
+		return ErrNotPermitted
+	}
+	return violation
+}
+
+// Scope describes what the permissions matching a route let a client see: "*" when
+// one of them has no constraints, else the sorted constraints of each, e.g.
+// Channel=M,W. Clients with the same scope on a route are allowed the same requests.
+func (m *Matcher) Scope(method, requestPath string) string {
+	var scopes []string
+	add := func(r *rule) bool {
+		if len(r.constraints) == 0 {
+			return false
+		}
+		fields := make([]string, 0, len(r.constraints))
+		for field, allowed := range r.constraints {
+			values := make([]string, 0, len(allowed))
+			for v := range allowed {
+				values = append(values, v)
+			}
+			sort.Strings(values)
+			fields = append(fields, strings.ToLower(field)+"="+strings.Join(values, ","))
+		}
+		sort.Strings(fields)
+		scopes = append(scopes, strings.Join(fields, ";"))
+		return true
+	}
+	for _, r := range m.exact[method+":"+requestPath] {
+		if !add(r) {
+			return "*"
+		}
+	}
+	for _, r := range m.globs {
+		if r.pattern.Matches(method, requestPath) && !add(r) {
+			return "*"
+		}
+	}
+	sort.Strings(scopes)
+	return strings.Join(scopes, "|")
+}
+
+func (r *rule) allows(fields map[string]interface{}) error {
+	for field, allowed := range r.constraints {
+		found := false
+		// encoding/json binds keys case-insensitively, so every spelling of the field must pass.
+		for key, value := range fields {
+			if !strings.EqualFold(key, field) {
+				continue
+			}
+			found = true
+			s, ok := scalarString(value)
+			if !ok || !allowed[s] {
+				return fmt.Errorf("%w: %s", ErrValueNotAllowed, field)
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s is required", ErrValueNotAllowed, field)
+		}
+	}
+	return nil
+}
+
+func decodeBody(body []byte) map[string]interface{} {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,83 @@
+package permission
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	valid := []string{"POST:/Api/Mobile/MobileFullPAN", "POST:/Api/Mobile/*", "*:/Api/Common/*", "GET:/Api/uhp/Get?edbookInfo"}
+	for _, s := range valid {
+		if _, err := ParsePattern(s); err != nil {
+			t.Errorf("ParsePattern(%q) = %v", s, err)
+		}
+	}
+	invalid := []string{"", "/Api/Mobile", "post:/Api/Mobile", "POST:Api/Mobile", "POST:/Api/[Mobile"}
+	for _, s := range invalid {
+		if _, err := ParsePattern(s); !errors.Is(err, ErrMalformedPattern) {
+			t.Errorf("ParsePattern(%q) = %v, want ErrMalformedPattern", s, err)
+		}
+	}
+}
+
+func TestMatcherAllow(t *testing.T) {
+	m := NewMatcher()
+	if err := m.Add("POST:/Api/Mobile/MobileFullPAN", map[string][]string{"Channel": {"M"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add("*:/Api/Common/*", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add("POST:/Api/Consent/UpdateConsent", map[string][]string{"Channel": {}}); err == nil {
+		t.Error("expected an error for a constraint without values")
+	}
+
+	tests := []struct {
+		method, path, body string
+		wantErr            error
+	}{
+		{"POST", "/Api/Mobile/MobileFullPAN", `{"Channel":"M","CardList_rq":[]}`, nil},
+		{"POST", "/Api/Mobile/MobileFullPAN", `{"Channel":"B"}`, ErrValueNotAllowed},
+		{"POST", "/Api/Mobile/MobileFullPAN", `{"Channel":["M"]}`, ErrValueNotAllowed},
+		{"POST", "/Api/Mobile/MobileFullPAN", `not json`, ErrValueNotAllowed},
+		{"GET", "/Api/Common/GetCustomerInfo", ``, nil},
+		{"POST", "/Api/Mobile/DashboardDetail", ``, ErrNotPermitted},
+	}
+	for _, tt := range tests {
+		if err := m.Allow(tt.method, tt.path, []byte(tt.body)); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Allow(%s %s, %s) = %v, want %v", tt.method, tt.path, tt.body, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatcherScope(t *testing.T) {
+	m := NewMatcher()
+	m.Add("POST:/Api/uhp/GetRedbookInfo", map[string][]string{"Channel": {"W", "M"}, "Brand": {"TOYOTA"}})
+	m.Add("POST:/Api/uhp/*", map[string][]string{"channel": {"B"}})
+	m.Add("*:/Api/Common/*", nil)
+
+	tests := []struct {
+		method, path, want string
+	}{
+		{"POST", "/Api/uhp/GetRedbookInfo", "brand=TOYOTA;channel=M,W|channel=B"},
+		{"POST", "/Api/uhp/GetDealerCommission", "channel=B"},
+		{"POST", "/Api/Common/GetCustomerInfo", "*"},
+		{"POST", "/Api/Mobile/DashboardDetail", ""},
+	}
+	for _, tt := range tests {
+		if got := m.Scope(tt.method, tt.path); got != tt.want {
+			t.Errorf("Scope(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkMatcherAllow(b *testing.B) {
+	m := NewMatcher()
+	for _, p := range []string{"POST:/Api/Collection/CollectionDetail", "POST:/Api/Collection/CollectionLog", "POST:/Api/Mobile/*"} {
+		_ = m.Add(p, nil)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = m.Allow("POST", "/Api/Collection/CollectionLog", nil)
+	}
+}
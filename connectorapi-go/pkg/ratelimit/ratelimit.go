@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+// Reasons a request is rejected, used as metric label.
+const (
+	ReasonRate  = "rate"
+	ReasonQuota = "quota"
+)
+
+// Decision is the outcome of Limiter.Allow.
+type Decision struct {
+	Allowed    bool
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Limiter enforces the token buckets and daily quotas of config.RateLimitConfig.
+// Buckets are created on first use; their number is bounded by clients x routes.
+type Limiter struct {
+	mu      sync.Mutex
+	config  config.RateLimitConfig
+	buckets map[string]*bucket
+	quotas  map[string]*quota
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type quota struct {
+	day   string
+	count int
+}
+
+// scope is one limit that applies to a request, e.g. the client or the client on a route.
+type scope struct {
+	key   string
+	limit config.RateLimit
+}
+
+func New(cfg config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		config:  cfg,
+		buckets: make(map[string]*bucket),
+		quotas:  make(map[string]*quota),
+		now:     time.Now,
+	}
+}
+
+// Allow takes one request of client on route from every applicable limit.
+// Nothing is consumed when any limit rejects the request.
+func (l *Limiter) Allow(client, route string) Decision {
+	scopes := l.scopes(client, route)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	day := now.Format("20060102")
+
+	for _, s := range scopes {
+		if s.limit.DailyQuota > 0 {
+			if q := l.quotas[s.key]; q != nil && q.day == day && q.count >= s.limit.DailyQuota {
+				return Decision{Reason: ReasonQuota, RetryAfter: untilTomorrow(now)}
+			}
+		}
+	}
+	for _, s := range scopes {
+		if s.limit.Rate > 0 {
+			b := l.refill(s.key, s.limit, now)
+			if b.tokens < 1 {
+				wait := time.Duration((1 - b.tokens) / s.limit.Rate * float64(time.Second))
+				return Decision{Reason: ReasonRate, RetryAfter: wait}
+			}
+		}
+	}
+
+	for _, s := range scopes {
+		if s.limit.Rate > 0 {
+			l.buckets[s.key].tokens--
+		}
+		if s.limit.DailyQuota > 0 {
+			q := l.quotas[s.key]
+			if q == nil || q.day != day {
+				q = &quota{day: day}
+				l.quotas[s.key] = q
+			}
+			q.count++
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// scopes resolves the limits of client overall and of client on route.
+func (l *Limiter) scopes(client, route string) []scope {
+	clientLimit := l.config.Default
+	routeLimit, hasRoute := l.config.Routes[route]
+	if c, ok := l.config.Clients[client]; ok {
+		clientLimit = c.RateLimit
+		if r, ok := c.Routes[route]; ok {
+			routeLimit, hasRoute = r, true
+		}
+	}
+
+	scopes := []scope{{key: client, limit: clientLimit}}
+	if hasRoute {
+		scopes = append(scopes, scope{key: client + "|" + route, limit: routeLimit})
+	}
+	return scopes
+}
+
+func (l *Limiter) refill(key string, limit config.RateLimit, now time.Time) *bucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(limit.Rate))
+	}
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+		return b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+	return b
+}
+
+func untilTomorrow(now time.Time) time.Duration {
+	y, m, d := now.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Sub(now)
+}
+
+// RetryAfterSeconds formats a wait for the Retry-After header, rounded up to whole seconds.
+func RetryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
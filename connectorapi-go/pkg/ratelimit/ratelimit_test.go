@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+const customerInfo = "POST:/Api/Common/GetCustomerInfo"
+
+func newTestLimiter(cfg config.RateLimitConfig, now *time.Time) *Limiter {
+	l := New(cfg)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(config.RateLimitConfig{
+		Routes: map[string]config.RateLimit{customerInfo: {Rate: 2, Burst: 3}},
+	}, &now)
+
+	for i := 0; i < 3; i++ {
+		if d := l.Allow("DebtMediation", customerInfo); !d.Allowed {
+			t.Fatalf("request %d rejected within burst", i+1)
+		}
+	}
+	d := l.Allow("DebtMediation", customerInfo)
+	if d.Allowed || d.Reason != ReasonRate || d.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("over burst = %+v, want rate rejection with 500ms retry", d)
+	}
+
+	// Other clients and routes have their own buckets.
+	if d := l.Allow("AllClient", customerInfo); !d.Allowed {
+		t.Error("other client throttled by DebtMediation")
+	}
+	if d := l.Allow("DebtMediation", "POST:/Api/Collection/CollectionLog"); !d.Allowed {
+		t.Error("unlimited route throttled")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if d := l.Allow("DebtMediation", customerInfo); !d.Allowed {
+		t.Error("token not refilled after retry-after")
+	}
+}
+
+func TestDailyQuota(t *testing.T) {
+	now := time.Date(2026, 5, 1, 23, 0, 0, 0, time.UTC)
+	l := newTestLimiter(config.RateLimitConfig{
+		Clients: map[string]config.ClientRateLimit{
+			"FriendsAPI": {RateLimit: config.RateLimit{DailyQuota: 2}},
+		},
+	}, &now)
+
+	l.Allow("FriendsAPI", customerInfo)
+	l.Allow("FriendsAPI", "POST:/Api/Mobile/DashboardDetail")
+	d := l.Allow("FriendsAPI", customerInfo)
+	if d.Allowed || d.Reason != ReasonQuota || d.RetryAfter != time.Hour {
+		t.Fatalf("over quota = %+v, want quota rejection until midnight", d)
+	}
+
+	now = now.Add(time.Hour)
+	if d := l.Allow("FriendsAPI", customerInfo); !d.Allowed {
+		t.Error("quota not reset on the next day")
+	}
+}
+
+func TestRejectionConsumesNothing(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(config.RateLimitConfig{
+		Default: config.RateLimit{DailyQuota: 10},
+		Routes:  map[string]config.RateLimit{customerInfo: {Rate: 1, Burst: 1}},
+	}, &now)
+
+	l.Allow("AllClient", customerInfo)
+	for i := 0; i < 5; i++ {
+		if d := l.Allow("AllClient", customerInfo); d.Allowed {
+			t.Fatal("expected rate rejection")
+		}
+	}
+	if got := l.quotas["AllClient"].count; got != 1 {
+		t.Errorf("quota count = %d, want 1, rejected requests must not count", got)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	for d, want := range map[time.Duration]int{0: 1, 200 * time.Millisecond: 1, 1500 * time.Millisecond: 2, time.Hour: 3600} {
+		if got := RetryAfterSeconds(d); got != want {
+			t.Errorf("RetryAfterSeconds(%v) = %d, want %d", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"connectorapi-go/pkg/config"
+)
+
+// ValidateConfig checks cfg.RateLimit, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	rl := cfg.RateLimit
+	clientNames := scope.ClientNames()
+
+	checkLimit := func(owner string, limit config.RateLimit) {
+		if limit.Rate < 0 || limit.Burst < 0 || limit.DailyQuota < 0 {
+			report.Add(config.SeverityError, "rateLimit", "%s has a negative limit", owner)
+		}
+		if limit.Burst > 0 && limit.Rate == 0 {
+			report.Add(config.SeverityWarning, "rateLimit", "%s sets burst without rate, it has no effect", owner)
+		}
+	}
+	checkRoutes := func(owner string, routes map[string]config.RateLimit) {
+		for _, route := range slices.Sorted(maps.Keys(routes)) {
+			if !scope.Handlers[route] {
+				report.Add(config.SeverityWarning, "rateLimit", "%s limits %q which no handler serves", owner, route)
+			}
+			checkLimit(fmt.Sprintf("%s route %q", owner, route), routes[route])
+		}
+	}
+
+	checkLimit("default", rl.Default)
+	checkRoutes("rateLimit", rl.Routes)
+	for _, name := range slices.Sorted(maps.Keys(rl.Clients)) {
+		if !clientNames[name] {
+			report.Add(config.SeverityWarning, "rateLimit", "limit for %q matches no clientName", name)
+		}
+		checkLimit("client "+strconv.Quote(name), rl.Clients[name].RateLimit)
+		checkRoutes("client "+strconv.Quote(name), rl.Clients[name].Routes)
+	}
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"reflect"
+	"testing"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	scope := config.ValidationScope{
+		Clients:  &config.APIClients{Clients: []config.APIKey{{ClientName: "MobileApp"}}},
+		Handlers: map[string]bool{customerInfo: true},
+	}
+	valid := func() config.RateLimitConfig {
+		return config.RateLimitConfig{
+			Default: config.RateLimit{Rate: 10, Burst: 20, DailyQuota: 1000},
+			Routes:  map[string]config.RateLimit{customerInfo: {Rate: 2, Burst: 3}},
+			Clients: map[string]config.ClientRateLimit{"MobileApp": {
+				RateLimit: config.RateLimit{Rate: 5},
+				Routes:    map[string]config.RateLimit{customerInfo: {Rate: 1}},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(rl *config.RateLimitConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(rl *config.RateLimitConfig) {}, nil},
+		{"negative default rate", func(rl *config.RateLimitConfig) { rl.Default.Rate = -1 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "rateLimit", Message: "default has a negative limit"}}},
+		{"negative route daily quota", func(rl *config.RateLimitConfig) { rl.Routes[customerInfo] = config.RateLimit{Rate: 2, DailyQuota: -1} },
+			[]config.Issue{{Severity: config.SeverityError, Check: "rateLimit", Message: `rateLimit route "POST:/Api/Common/GetCustomerInfo" has a negative limit`}}},
+		{"negative client route burst", func(rl *config.RateLimitConfig) {
+			rl.Clients["MobileApp"] = config.ClientRateLimit{Routes: map[string]config.RateLimit{customerInfo: {Rate: 1, Burst: -1}}}
+		}, []config.Issue{{Severity: config.SeverityError, Check: "rateLimit", Message: `client "MobileApp" route "POST:/Api/Common/GetCustomerInfo" has a negative limit`}}},
+		{"burst without rate", func(rl *config.RateLimitConfig) { rl.Default = config.RateLimit{Burst: 5} },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "rateLimit", Message: "default sets burst without rate, it has no effect"}}},
+		{"unserved route", func(rl *config.RateLimitConfig) { rl.Routes["POST:/Api/Unknown"] = config.RateLimit{Rate: 1} },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "rateLimit", Message: `rateLimit limits "POST:/Api/Unknown" which no handler serves`}}},
+		{"unknown client", func(rl *config.RateLimitConfig) {
+			rl.Clients["Nobody"] = config.ClientRateLimit{RateLimit: config.RateLimit{Rate: 1}}
+		},
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "rateLimit", Message: `limit for "Nobody" matches no clientName`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{RateLimit: valid()}
+			tt.mutate(&cfg.RateLimit)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package replay rejects replayed requests: a request ID a client already used within
+// its window, or a timestamp too far from the server clock.
+package replay
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+var (
+	ErrDuplicateRequestID = errors.New("request ID already used")
+	ErrTimestamp          = errors.New("request timestamp missing, malformed or outside the allowed skew")
+)
+
+// sweepInterval is how often expired request IDs are dropped.
+const sweepInterval = time.Minute
+
+// Guard remembers the request IDs of each client for the window of its policy.
+// IDs are kept in memory, so the check covers one server instance.
+type Guard struct {
+	mu        sync.Mutex
+	config    config.ReplayConfig
+	seen      map[string]time.Time // client + "|" + request ID -> end of its window
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func New(cfg config.ReplayConfig) *Guard {
+	return &Guard{config: cfg, seen: make(map[string]time.Time), now: time.Now}
+}
+
+// Check accepts a request of client with requestID and the raw timestamp header, which
+// may be empty. An accepted request ID is taken for the window of the client's policy.
+func (g *Guard) Check(client, requestID, timestamp string) error {
+	policy := g.config.Default
+	if p, ok := g.config.Clients[client]; ok {
+		policy = p
+	}
+	now := g.now()
+
+	if timestamp == "" {
+		if policy.RequireTimestamp {
+			return ErrTimestamp
+		}
+	} else if policy.MaxSkew > 0 {
+		t, ok := ParseTimestamp(timestamp)
+		if !ok || t.Sub(now) > policy.MaxSkew || now.Sub(t) > policy.MaxSkew {
+			return ErrTimestamp
+		}
+	}
+
+	if policy.Window <= 0 || requestID == "" {
+		return nil
+	}
+	key := client + "|" + requestID
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Sub(g.lastSweep) >= sweepInterval {
+		for k, until := range g.seen {
+			if !now.Before(until) {
+				delete(g.seen, k)
+			}
+		}
+		g.lastSweep = now
+	}
+	if until, ok := g.seen[key]; ok && now.Before(until) {
+		return ErrDuplicateRequestID
+	}
+	g.seen[key] = now.Add(policy.Window)
+	return nil
+}
+
+// ParseTimestamp reads an RFC 3339 time or Unix seconds or milliseconds.
+func ParseTimestamp(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	if n > 1e12 {
+		return time.UnixMilli(n), true
+	}
+	return time.Unix(n, 0), true
+}
@@ -0,0 +1,76 @@
+package replay
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func testGuard(now *time.Time) *Guard {
+	g := New(config.ReplayConfig{
+		Enabled: true,
+		Default: config.ReplayPolicy{Window: 10 * time.Minute},
+		Clients: map[string]config.ReplayPolicy{
+			"Strict": {Window: time.Hour, MaxSkew: 5 * time.Minute, RequireTimestamp: true},
+			"Legacy": {},
+		},
+	})
+	g.now = func() time.Time { return *now }
+	return g
+}
+
+func TestDuplicateRequestIDWithinWindow(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	g := testGuard(&now)
+
+	if err := g.Check("MobileApp", "RQ1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Check("MobileApp", "RQ1", ""); !errors.Is(err, ErrDuplicateRequestID) {
+		t.Errorf("replay: err = %v", err)
+	}
+	if err := g.Check("Partner", "RQ1", ""); err != nil {
+		t.Errorf("same ID of another client: err = %v", err)
+	}
+	if err := g.Check("Legacy", "RQ1", ""); err != nil {
+		t.Errorf("client without window: err = %v", err)
+	}
+	if err := g.Check("Legacy", "RQ1", ""); err != nil {
+		t.Errorf("client without window, twice: err = %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if err := g.Check("MobileApp", "RQ1", ""); err != nil {
+		t.Errorf("after the window: err = %v", err)
+	}
+}
+
+func TestTimestampSkew(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	g := testGuard(&now)
+	tests := []struct {
+		id, timestamp string
+		want          error
+	}{
+		{"A", "", ErrTimestamp},
+		{"B", now.Format(time.RFC3339), nil},
+		{"C", now.Add(-4 * time.Minute).In(time.FixedZone("ICT", 7*3600)).Format(time.RFC3339), nil},
+		{"D", strconv.FormatInt(now.Add(4*time.Minute).Unix(), 10), nil},
+		{"E", strconv.FormatInt(now.UnixMilli(), 10), nil},
+		{"F", now.Add(-6 * time.Minute).Format(time.RFC3339), ErrTimestamp},
+		{"G", now.Add(6 * time.Minute).Format(time.RFC3339), ErrTimestamp},
+		{"H", "yesterday", ErrTimestamp},
+	}
+	for _, tt := range tests {
+		if err := g.Check("Strict", tt.id, tt.timestamp); !errors.Is(err, tt.want) {
+			t.Errorf("%s %q: err = %v, want %v", tt.id, tt.timestamp, err, tt.want)
+		}
+	}
+	// A rejected timestamp does not take the request ID.
+	if err := g.Check("Strict", "F", now.Format(time.RFC3339)); err != nil {
+		t.Errorf("retry with a valid timestamp: err = %v", err)
+	}
+}
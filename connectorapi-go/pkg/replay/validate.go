@@ -0,0 +1,30 @@
+package replay
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"connectorapi-go/pkg/config"
+)
+
+// ValidateConfig checks cfg.Replay, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	r := cfg.Replay
+	clientNames := scope.ClientNames()
+	checkPolicy := func(owner string, p config.ReplayPolicy) {
+		if p.Window < 0 || p.MaxSkew < 0 {
+			report.Add(config.SeverityError, "replay", "%s has a negative window or maxSkew", owner)
+		}
+		if p.RequireTimestamp && p.MaxSkew == 0 {
+			report.Add(config.SeverityWarning, "replay", "%s requires a timestamp without maxSkew, any time is accepted", owner)
+		}
+	}
+	checkPolicy("default", r.Default)
+	for _, name := range slices.Sorted(maps.Keys(r.Clients)) {
+		if !clientNames[name] {
+			report.Add(config.SeverityWarning, "replay", "policy for %q matches no clientName", name)
+		}
+		checkPolicy("client "+strconv.Quote(name), r.Clients[name])
+	}
+}
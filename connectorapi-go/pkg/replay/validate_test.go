@@ -0,0 +1,54 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	scope := config.ValidationScope{Clients: &config.APIClients{Clients: []config.APIKey{{ClientName: "Strict"}, {ClientName: "Legacy"}}}}
+	valid := func() config.ReplayConfig {
+		return config.ReplayConfig{
+			Enabled: true,
+			Default: config.ReplayPolicy{Window: 10 * time.Minute},
+			Clients: map[string]config.ReplayPolicy{
+				"Strict": {Window: time.Hour, MaxSkew: 5 * time.Minute, RequireTimestamp: true},
+				"Legacy": {},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(r *config.ReplayConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(r *config.ReplayConfig) {}, nil},
+		{"negative default window", func(r *config.ReplayConfig) { r.Default.Window = -time.Minute },
+			[]config.Issue{{Severity: config.SeverityError, Check: "replay", Message: "default has a negative window or maxSkew"}}},
+		{"negative client max skew", func(r *config.ReplayConfig) { r.Clients["Legacy"] = config.ReplayPolicy{MaxSkew: -time.Minute} },
+			[]config.Issue{{Severity: config.SeverityError, Check: "replay", Message: `client "Legacy" has a negative window or maxSkew`}}},
+		{"timestamp without max skew", func(r *config.ReplayConfig) {
+			r.Clients["Strict"] = config.ReplayPolicy{Window: time.Hour, RequireTimestamp: true}
+		},
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "replay", Message: `client "Strict" requires a timestamp without maxSkew, any time is accepted`}}},
+		{"unknown client", func(r *config.ReplayConfig) { r.Clients["Nobody"] = config.ReplayPolicy{Window: time.Minute} },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "replay", Message: `policy for "Nobody" matches no clientName`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Replay: valid()}
+			tt.mutate(&cfg.Replay)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
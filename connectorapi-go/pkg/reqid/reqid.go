@@ -0,0 +1,157 @@
+// Package reqid generates request IDs that fit the 20-character request ID field of
+// the System I header, sort by creation time and stay unique across instances.
+//
+// An ID is "RQ" followed by 18 Crockford base32 characters:
+//
+//	RQ TTTTTTTTT NNN SSSSSS
+//	   |         |   sequence, 30 bits
+//	   |         node, 15 bits
+//	   Unix milliseconds, 45 bits (until year 3084)
+//
+// The alphabet is in ASCII order, so IDs of one node compare like their creation order.
+package reqid
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	Prefix = "RQ"
+	Length = 20
+
+	alphabet  = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	timeChars = 9
+	nodeChars = 3
+	seqChars  = 6
+
+	MaxNode = 1<<(5*nodeChars) - 1
+	maxSeq  = 1<<(5*seqChars) - 1
+)
+
+var ErrInvalidID = errors.New("not a generated request ID")
+
+// Generator creates IDs for one node. It is safe for concurrent use.
+type Generator struct {
+	mu     sync.Mutex
+	node   int
+	lastMs int64
+	seq    uint32
+	now    func() time.Time
+	rand   *rand.Rand
+}
+
+// New returns a generator for node, 0 to MaxNode. Every instance sharing System I
+// needs its own node.
+func New(node int) (*Generator, error) {
+	if node < 0 || node > MaxNode {
+		return nil, fmt.Errorf("request ID node %d out of range 0-%d", node, MaxNode)
+	}
+	return &Generator{node: node, now: time.Now, rand: rand.New(rand.NewSource(time.Now().UnixNano()))}, nil
+}
+
+// NodeFromHostname derives a node from the host name, e.g. the pod name. Distinct
+// hosts may still share a node; set it explicitly where that matters.
+func NodeFromHostname() int {
+	host, _ := os.Hostname()
+	h := fnv.New32a()
+	h.Write([]byte(host))
+	return int(h.Sum32() % (MaxNode + 1))
+}
+
+// Next returns a new ID, greater than every ID this generator returned before.
+// The sequence starts at a random value in the lower half for each millisecond, so
+// a restarted instance does not repeat the IDs of the same millisecond. When the clock
+// goes back, or a millisecond runs out of sequence numbers, the last millisecond is
+// carried forward.
+func (g *Generator) Next() string {
+	g.mu.Lock()
+	ms := g.now().UnixMilli()
+	if ms > g.lastMs {
+		g.lastMs = ms
+		g.seq = uint32(g.rand.Int63n(maxSeq / 2))
+	} else if g.seq < maxSeq {
+		g.seq++
+	} else {
+		g.lastMs++
+		g.seq = 0
+	}
+	ms, seq := g.lastMs, g.seq
+	g.mu.Unlock()
+
+	var b [Length]byte
+	copy(b[:], Prefix)
+	encode(b[2:2+timeChars], uint64(ms))
+	encode(b[2+timeChars:2+timeChars+nodeChars], uint64(g.node))
+	encode(b[2+timeChars+nodeChars:], uint64(seq))
+	return string(b[:])
+}
+
+// ID is a request ID taken apart.
+type ID struct {
+	Time time.Time
+	Node int
+	Seq  int
+}
+
+// Parse takes apart an ID created by a Generator.
+func Parse(id string) (ID, error) {
+	if len(id) != Length || !strings.HasPrefix(id, Prefix) {
+		return ID{}, ErrInvalidID
+	}
+	ms, ok := decode(id[2 : 2+timeChars])
+	node, ok2 := decode(id[2+timeChars : 2+timeChars+nodeChars])
+	seq, ok3 := decode(id[2+timeChars+nodeChars:])
+	if !ok || !ok2 || !ok3 {
+		return ID{}, ErrInvalidID
+	}
+	return ID{Time: time.UnixMilli(int64(ms)), Node: int(node), Seq: int(seq)}, nil
+}
+
+func encode(dst []byte, v uint64) {
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = alphabet[v&31]
+		v >>= 5
+	}
+}
+
+func decode(s string) (uint64, bool) {
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(alphabet, s[i])
+		if d < 0 {
+			return 0, false
+		}
+		v = v<<5 | uint64(d)
+	}
+	return v, true
+}
+
+var (
+	defaultMu        sync.RWMutex
+	defaultGenerator *Generator
+)
+
+func init() {
+	defaultGenerator, _ = New(NodeFromHostname())
+}
+
+// SetDefault replaces the generator used by the package-level Next.
+func SetDefault(g *Generator) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultGenerator = g
+}
+
+// Next returns a new ID from the default generator.
+func Next() string {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultGenerator.Next()
+}
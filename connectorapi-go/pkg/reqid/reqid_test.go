@@ -0,0 +1,108 @@
+package reqid
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentIDsAreUniqueAndMonotonic(t *testing.T) {
+	g, err := New(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const workers, perWorker = 16, 5000
+	results := make([][]string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = g.Next()
+			}
+			results[w] = ids
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, workers*perWorker)
+	for _, ids := range results {
+		for i, id := range ids {
+			if len(id) != Length {
+				t.Fatalf("%q has %d bytes", id, len(id))
+			}
+			if seen[id] {
+				t.Fatalf("duplicate ID %s", id)
+			}
+			seen[id] = true
+			// Each goroutine sees its own IDs in increasing order.
+			if i > 0 && id <= ids[i-1] {
+				t.Fatalf("%s not after %s", id, ids[i-1])
+			}
+		}
+	}
+}
+
+func TestNodesNeverCollide(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	var all []string
+	for node := 0; node < 4; node++ {
+		g, _ := New(node)
+		g.now = func() time.Time { return now }
+		for i := 0; i < 1000; i++ {
+			all = append(all, g.Next())
+		}
+	}
+	sort.Strings(all)
+	for i := 1; i < len(all); i++ {
+		if all[i] == all[i-1] {
+			t.Fatalf("duplicate ID %s across nodes", all[i])
+		}
+	}
+}
+
+func TestClockGoingBackStaysMonotonic(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	g, _ := New(1)
+	g.now = func() time.Time { return now }
+	first := g.Next()
+	now = now.Add(-time.Second)
+	second := g.Next()
+	if second <= first {
+		t.Errorf("%s not after %s when the clock went back", second, first)
+	}
+
+	g.seq = maxSeq
+	third := g.Next()
+	parsed, _ := Parse(third)
+	if third <= second || !parsed.Time.Equal(time.UnixMilli(g.lastMs)) || parsed.Seq != 0 {
+		t.Errorf("sequence overflow gave %s (%+v)", third, parsed)
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 30, 15, 123e6, time.UTC)
+	g, _ := New(MaxNode)
+	g.now = func() time.Time { return now }
+	id := g.Next()
+
+	parsed, err := Parse(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Time.Equal(now) || parsed.Node != MaxNode || parsed.Seq != int(g.seq) {
+		t.Errorf("Parse(%s) = %+v", id, parsed)
+	}
+
+	for _, bad := range []string{"", id + "0", "XX" + id[2:], id[:19] + "U"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) accepted", bad)
+		}
+	}
+	if _, err := New(MaxNode + 1); err == nil {
+		t.Error("New accepted a node out of range")
+	}
+}
@@ -0,0 +1,127 @@
+// Package respcache keeps successful responses of read-only routes whose data changes
+// rarely, such as the Redbook price book, so repeated inquiries skip System I.
+// Entries expire after the TTL of their route; beyond the size limit the least
+// recently used entry is evicted.
+package respcache
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Status      int
+	ContentType string
+	Body        []byte
+	StoredAt    time.Time
+	ExpiresAt   time.Time
+}
+
+type item struct {
+	key   string
+	entry Entry
+}
+
+// Cache is an LRU cache of responses with a TTL per entry. It is safe for concurrent use.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is the most recently used
+	items      map[string]*list.Element
+	now        func() time.Time
+}
+
+// New returns a cache holding up to maxEntries responses.
+func New(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Key identifies a request: its route, the permission scope of its client and its
+// body. Bodies that only differ in field order or white space share a key.
+func Key(route, scope string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(route))
+	h.Write([]byte{0})
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write(Normalize(body))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Normalize re-encodes a JSON body with sorted keys and no white space. Bodies
+// that are not JSON are returned as they are.
+func Normalize(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil || decoder.More() {
+		return body
+	}
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return body
+	}
+	return normalized
+}
+
+// Get returns the unexpired entry of key and marks it as recently used.
+func (c *Cache) Get(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	it := element.Value.(*item)
+	if !c.now().Before(it.entry.ExpiresAt) {
+		c.remove(element)
+		return Entry{}, false
+	}
+	c.order.MoveToFront(element)
+	return it.entry, true
+}
+
+// Set stores a response for ttl and returns how many entries were evicted to make room.
+func (c *Cache) Set(key string, status int, contentType string, body []byte, ttl time.Duration) int {
+	now := c.now()
+	entry := Entry{Status: status, ContentType: contentType, Body: body, StoredAt: now, ExpiresAt: now.Add(ttl)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[key]; ok {
+		element.Value.(*item).entry = entry
+		c.order.MoveToFront(element)
+		return 0
+	}
+	c.items[key] = c.order.PushFront(&item{key: key, entry: entry})
+
+	evicted := 0
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		evicted++
+	}
+	return evicted
+}
+
+// Len returns the number of entries, including expired ones not evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*item).key)
+}
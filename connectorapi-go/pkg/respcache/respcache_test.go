@@ -0,0 +1,62 @@
+package respcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyNormalizesBody(t *testing.T) {
+	a := Key("POST:/Api/uhp/GetRedbookInfo", "scope", []byte(`{"Brand": "TOYOTA", "Year": 2020}`))
+	b := Key("POST:/Api/uhp/GetRedbookInfo", "scope", []byte("{\n\t\"Year\": 2020,\n\t\"Brand\": \"TOYOTA\"\n}"))
+	if a != b {
+		t.Error("field order or white space changed the key")
+	}
+	for name, other := range map[string]string{
+		"value": Key("POST:/Api/uhp/GetRedbookInfo", "scope", []byte(`{"Brand": "HONDA", "Year": 2020}`)),
+		"scope": Key("POST:/Api/uhp/GetRedbookInfo", "other", []byte(`{"Brand": "TOYOTA", "Year": 2020}`)),
+		"route": Key("POST:/Api/uhp/GetDealerCommission", "scope", []byte(`{"Brand": "TOYOTA", "Year": 2020}`)),
+	} {
+		if other == a {
+			t.Errorf("a different %s gave the same key", name)
+		}
+	}
+	if string(Normalize([]byte("not json"))) != "not json" {
+		t.Error("non-JSON body changed")
+	}
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	c := New(10)
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 200, "application/json", []byte(`{}`), time.Minute)
+	if entry, ok := c.Get("a"); !ok || entry.Status != 200 || string(entry.Body) != `{}` {
+		t.Fatalf("Get = %+v, %v", entry, ok)
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("entry served after its TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len = %d after expiry", c.Len())
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(2)
+	c.Set("a", 200, "", nil, time.Hour)
+	c.Set("b", 200, "", nil, time.Hour)
+	c.Get("a")
+	if evicted := c.Set("c", 200, "", nil, time.Hour); evicted != 1 {
+		t.Errorf("evicted %d, want 1", evicted)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Error("b kept although least recently used")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s evicted", key)
+		}
+	}
+}
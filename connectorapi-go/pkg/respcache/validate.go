@@ -0,0 +1,35 @@
+package respcache
+
+import (
+	"maps"
+	"slices"
+
+	"connectorapi-go/pkg/config"
+)
+
+// ValidateConfig checks cfg.Cache, see config.Check. Audited routes change state and
+// must never be answered from the cache.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	c := cfg.Cache
+	if !c.Enabled {
+		return
+	}
+	if c.MaxEntries <= 0 {
+		report.Add(config.SeverityError, "cache", "maxEntries must be positive")
+	}
+	audited := make(map[string]bool, len(cfg.Audit.Routes))
+	for _, route := range cfg.Audit.Routes {
+		audited[route] = true
+	}
+	for _, route := range slices.Sorted(maps.Keys(c.Routes)) {
+		if c.Routes[route] <= 0 {
+			report.Add(config.SeverityError, "cache", "ttl of %q must be positive", route)
+		}
+		if !scope.Handlers[route] {
+			report.Add(config.SeverityWarning, "cache", "cache route %q is not served by any handler", route)
+		}
+		if audited[route] {
+			report.Add(config.SeverityError, "cache", "route %q changes state and cannot be cached", route)
+		}
+	}
+}
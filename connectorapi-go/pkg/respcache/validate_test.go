@@ -0,0 +1,52 @@
+package respcache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	const redbook, updateConsent = "POST:/Api/uhp/GetRedbookInfo", "POST:/Api/Consent/UpdateConsent"
+	scope := config.ValidationScope{Handlers: map[string]bool{redbook: true, updateConsent: true}}
+	valid := func() *config.Config {
+		return &config.Config{
+			Cache: config.CacheConfig{Enabled: true, MaxEntries: 10000, Routes: map[string]time.Duration{redbook: 10 * time.Minute}},
+			Audit: config.AuditConfig{Enabled: true, Routes: []string{updateConsent}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(cfg *config.Config)
+		want   []config.Issue
+	}{
+		{"valid", func(cfg *config.Config) {}, nil},
+		{"disabled is not checked", func(cfg *config.Config) {
+			cfg.Cache = config.CacheConfig{Routes: map[string]time.Duration{updateConsent: 0}}
+		}, nil},
+		{"no max entries", func(cfg *config.Config) { cfg.Cache.MaxEntries = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "cache", Message: "maxEntries must be positive"}}},
+		{"no ttl", func(cfg *config.Config) { cfg.Cache.Routes[redbook] = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "cache", Message: `ttl of "POST:/Api/uhp/GetRedbookInfo" must be positive`}}},
+		{"unserved route", func(cfg *config.Config) { cfg.Cache.Routes["POST:/Api/Unknown"] = time.Minute },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "cache", Message: `cache route "POST:/Api/Unknown" is not served by any handler`}}},
+		{"audited route", func(cfg *config.Config) { cfg.Cache.Routes[updateConsent] = time.Minute },
+			[]config.Issue{{Severity: config.SeverityError, Check: "cache", Message: `route "POST:/Api/Consent/UpdateConsent" changes state and cannot be cached`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(cfg)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, scope)
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"connectorapi-go/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func recordSpans(t *testing.T, ratio float64) *tracetest.SpanRecorder {
+	t.Helper()
+	if _, err := Init(context.Background(), config.TracingConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := NewProvider(recorder, resource.Empty(), ratio)
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previous)
+	})
+	return recorder
+}
+
+func TestSpansContinueInboundTraceparent(t *testing.T) {
+	recorder := recordSpans(t, 0)
+	header := http.Header{}
+	header.Set("traceparent", traceparent)
+
+	ctx, server := StartKind(Extract(context.Background(), header), "POST /Api/Test", trace.SpanKindServer)
+	_, child := Start(ctx, "validate body", attribute.String("connector.route", "POST:/Api/Test"))
+	End(child, errors.New("IDCardNo is required"))
+	End(server, nil)
+
+	if got := TraceID(ctx); got != traceID {
+		t.Errorf("TraceID = %q, want %q", got, traceID)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans recorded, want 2 (a sampled caller overrides ratio 0)", len(spans))
+	}
+	validate, request := spans[0], spans[1]
+	if request.Parent().SpanID().String() != "00f067aa0ba902b7" || !request.Parent().IsRemote() {
+		t.Errorf("server span parent = %v, want the caller's span", request.Parent())
+	}
+	if validate.Parent().SpanID() != request.SpanContext().SpanID() || validate.SpanContext().TraceID().String() != traceID {
+		t.Error("validation span is not a child of the server span")
+	}
+	if validate.Status().Code != codes.Error || len(validate.Events()) != 1 {
+		t.Errorf("validation error not recorded: %+v", validate.Status())
+	}
+}
+
+func TestTraceIDWithoutTracing(t *testing.T) {
+	if _, err := Init(context.Background(), config.TracingConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := TraceID(context.Background()); got != "" {
+		t.Errorf("TraceID without a span = %q", got)
+	}
+
+	// Spans are not exported, but the caller's trace ID still reaches the logs.
+	header := http.Header{}
+	header.Set("traceparent", traceparent)
+	ctx, span := Start(Extract(context.Background(), header), "handler")
+	defer span.End()
+	if span.IsRecording() {
+		t.Error("span recorded with tracing disabled")
+	}
+	if got := TraceID(ctx); got != traceID {
+		t.Errorf("TraceID = %q, want %q", got, traceID)
+	}
+}
+
+func TestNewTracesFollowSampleRatio(t *testing.T) {
+	recorder := recordSpans(t, 0)
+	_, span := Start(context.Background(), "handler")
+	span.End()
+	if n := len(recorder.Ended()); n != 0 {
+		t.Errorf("ratio 0 recorded %d new traces", n)
+	}
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"net/url"
+
+	"connectorapi-go/pkg/config"
+)
+
+// ValidateConfig checks cfg.Tracing, see config.Check.
+func ValidateConfig(report *config.ValidationReport, cfg *config.Config, scope config.ValidationScope) {
+	t := cfg.Tracing
+	if !t.Enabled {
+		return
+	}
+	if u, err := url.Parse(t.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		report.Add(config.SeverityError, "tracing", "endpoint %q is not an http(s) URL", t.Endpoint)
+	}
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		report.Add(config.SeverityError, "tracing", "sampleRatio %v out of range 0-1", t.SampleRatio)
+	} else if t.SampleRatio == 0 {
+		report.Add(config.SeverityWarning, "tracing", "sampleRatio 0 keeps only traces sampled by the caller")
+	}
+	if t.Timeout <= 0 {
+		report.Add(config.SeverityError, "tracing", "export timeout must be positive")
+	}
+}
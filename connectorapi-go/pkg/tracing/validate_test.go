@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"connectorapi-go/pkg/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() config.TracingConfig {
+		return config.TracingConfig{Enabled: true, Endpoint: "http://otel-collector:4318/v1/traces", ServiceName: "connectorapi-go", SampleRatio: 0.1, Timeout: 5 * time.Second}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(tc *config.TracingConfig)
+		want   []config.Issue
+	}{
+		{"valid", func(tc *config.TracingConfig) {}, nil},
+		{"disabled is not checked", func(tc *config.TracingConfig) { *tc = config.TracingConfig{SampleRatio: 2} }, nil},
+		{"endpoint without scheme", func(tc *config.TracingConfig) { tc.Endpoint = "otel-collector:4318" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "tracing", Message: `endpoint "otel-collector:4318" is not an http(s) URL`}}},
+		{"grpc endpoint", func(tc *config.TracingConfig) { tc.Endpoint = "grpc://otel-collector:4317" },
+			[]config.Issue{{Severity: config.SeverityError, Check: "tracing", Message: `endpoint "grpc://otel-collector:4317" is not an http(s) URL`}}},
+		{"sample ratio above 1", func(tc *config.TracingConfig) { tc.SampleRatio = 1.5 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "tracing", Message: "sampleRatio 1.5 out of range 0-1"}}},
+		{"negative sample ratio", func(tc *config.TracingConfig) { tc.SampleRatio = -0.1 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "tracing", Message: "sampleRatio -0.1 out of range 0-1"}}},
+		{"sample ratio 0", func(tc *config.TracingConfig) { tc.SampleRatio = 0 },
+			[]config.Issue{{Severity: config.SeverityWarning, Check: "tracing", Message: "sampleRatio 0 keeps only traces sampled by the caller"}}},
+		{"no timeout", func(tc *config.TracingConfig) { tc.Timeout = 0 },
+			[]config.Issue{{Severity: config.SeverityError, Check: "tracing", Message: "export timeout must be positive"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Tracing: valid()}
+			tt.mutate(&cfg.Tracing)
+			report := &config.ValidationReport{}
+
+			ValidateConfig(report, cfg, config.ValidationScope{})
+
+			if !reflect.DeepEqual(report.Issues, tt.want) {
+				t.Errorf("issues = %v, want %v", report.Issues, tt.want)
+			}
+		})
+	}
+}